@@ -0,0 +1,398 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/oiler-backup/cli/internal/adapters"
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// adapterExportFlags are the flags of adapter export.
+type adapterExportFlags struct {
+	output string
+	format string
+}
+
+// adapterImportFlags are the flags of adapter import.
+type adapterImportFlags struct {
+	file    string
+	format  string
+	replace bool
+	dryRun  bool
+	yes     bool
+	force   bool
+}
+
+// newAdapterExportCmd returns a command that writes adapters from ConfigMap to a file.
+func newAdapterExportCmd(f Factory) *cobra.Command {
+	flags := &adapterExportFlags{}
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Export adapters from the ConfigMap",
+		Long: `Export adapters from the ConfigMap to YAML, JSON or env file.
+
+Output goes to stdout unless --output is set. Format is taken from --format
+or guessed from the output file extension.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			stopFn := startSpinner("[1/3] Preparing")
+			format := adapters.FormatFromPath(flags.output)
+			if flags.format != "" {
+				var err error
+				format, err = adapters.ParseFormat(flags.format)
+				if err != nil {
+					stopFn()
+					return usageErrorf("invalid --format: %w", err)
+				}
+			}
+
+			clientset, err := f.ClientSet()
+			if err != nil {
+				stopFn()
+				return fmt.Errorf("failed to get client: %w", err)
+			}
+			stopFn()
+
+			stopFn = startSpinner("[2/3] Getting config map")
+			configMap, err := getAdapterConfigMap(ctx, f, clientset)
+			if err != nil {
+				stopFn()
+				return fmt.Errorf("failed to get adapter ConfigMap: %w", err)
+			}
+			stopFn()
+
+			stopFn = startSpinner("[3/3] Writing result")
+			data, err := adapters.Encode(configMap.Data, format)
+			if err != nil {
+				stopFn()
+				return fmt.Errorf("failed to encode adapters: %w", err)
+			}
+			stopFn()
+
+			if flags.output == "" || flags.output == "-" {
+				cmd.OutOrStdout().Write(data)
+				return nil
+			}
+			if err := os.WriteFile(flags.output, data, 0644); err != nil {
+				return fmt.Errorf("failed to write %s: %w", flags.output, err)
+			}
+			log.Infof("Successfully exported %d adapters to %s", len(configMap.Data), flags.output)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&flags.output, "output", "o", "", "File to write adapters to (default stdout)")
+	cmd.Flags().StringVar(&flags.format, "format", "", "Output format: yaml, json or env (default guessed from --output, yaml otherwise)")
+	registerFlagCompletion(cmd, "format", completeAdapterFormats)
+	return cmd
+}
+
+// newAdapterImportCmd returns a command that merges or replaces adapters in ConfigMap from a file.
+func newAdapterImportCmd(f Factory) *cobra.Command {
+	flags := &adapterImportFlags{}
+	cmd := &cobra.Command{
+		Use:   "import -f <file>",
+		Short: "Import adapters into the ConfigMap",
+		Long: `Import adapters from YAML, JSON or env file into the ConfigMap.
+
+Entries from the file are merged into the ConfigMap, or replace it entirely with --replace.
+A preview of added, changed and removed adapters is shown before anything is written.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			stopFn := startSpinner("[1/3] Preparing")
+			format := adapters.FormatFromPath(flags.file)
+			if flags.format != "" {
+				var err error
+				format, err = adapters.ParseFormat(flags.format)
+				if err != nil {
+					stopFn()
+					return usageErrorf("invalid --format: %w", err)
+				}
+			}
+
+			data, err := readInput(flags.file)
+			if err != nil {
+				stopFn()
+				return fmt.Errorf("failed to read %s: %w", flags.file, err)
+			}
+
+			incoming, err := adapters.Decode(data, format)
+			if err != nil {
+				stopFn()
+				return fmt.Errorf("failed to parse %s: %w", flags.file, err)
+			}
+			if err := adapters.Validate(incoming); err != nil {
+				stopFn()
+				return err
+			}
+
+			clientset, err := f.ClientSet()
+			if err != nil {
+				stopFn()
+				return fmt.Errorf("failed to get client: %w", err)
+			}
+			if !flags.dryRun {
+				if err := checkAccess(ctx, f, permUpdateConfigMap); err != nil {
+					stopFn()
+					return err
+				}
+			}
+			stopFn()
+
+			stopFn = startSpinner("[2/3] Getting config map")
+			configMap, err := getAdapterConfigMap(ctx, f, clientset)
+			if err != nil {
+				stopFn()
+				return fmt.Errorf("failed to get adapter ConfigMap: %w", err)
+			}
+			stopFn()
+
+			desired := incoming
+			if !flags.replace {
+				desired = adapters.Merge(configMap.Data, incoming)
+			}
+			refreshAliases(configMap, desired)
+			changes := adapters.Diff(configMap.Data, desired)
+			renderAdapterChanges(cmd.OutOrStdout(), configMap.Data, desired, changes)
+			if changes.Empty() {
+				log.Info("Adapters are up to date, nothing to import")
+				return nil
+			}
+			if flags.dryRun {
+				return nil
+			}
+			if err := checkRemovedAdaptersUnused(ctx, f, changes.Removed, flags.force); err != nil {
+				return err
+			}
+			if !flags.yes && !confirm(ctx, "Apply these changes?") {
+				log.Info("Import cancelled")
+				return nil
+			}
+
+			stopFn = startSpinner("[3/3] Updating config map")
+			if err := saveAdapters(ctx, clientset, configMap, desired); err != nil {
+				stopFn()
+				return fmt.Errorf("failed to update ConfigMap: %w", err)
+			}
+			stopFn()
+			log.Infof("Successfully imported adapters into ConfigMap %s: %d added, %d changed, %d removed",
+				configMap.Name, len(changes.Added), len(changes.Changed), len(changes.Removed))
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&flags.file, "file", "f", "", "File to read adapters from, - for stdin")
+	cmd.Flags().StringVar(&flags.format, "format", "", "Input format: yaml, json or env (default guessed from --file, yaml otherwise)")
+	cmd.Flags().BoolVar(&flags.replace, "replace", false, "Replace all adapters instead of merging")
+	cmd.Flags().BoolVar(&flags.dryRun, "dry-run", false, "Only show the preview of changes")
+	cmd.Flags().BoolVarP(&flags.yes, "yes", "y", false, "Apply changes without confirmation")
+	cmd.Flags().BoolVar(&flags.force, "force", false, "Remove adapters even if BackupRequests use them")
+	cmd.MarkFlagRequired("file")
+	registerFlagCompletion(cmd, "format", completeAdapterFormats)
+	return cmd
+}
+
+// newAdapterEditCmd returns a command that opens adapters in $EDITOR and saves validated result back to ConfigMap.
+func newAdapterEditCmd(f Factory) *cobra.Command {
+	var force bool
+	cmd := &cobra.Command{
+		Use:   "edit",
+		Short: "Edit adapters in $EDITOR",
+		Long: `Open adapters from the ConfigMap in $EDITOR as YAML and save them back after validation.
+
+If the edited file is invalid, the editor is reopened with the error on top.
+Saving an unchanged file or an empty one cancels the edit.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			stopFn := startSpinner("[1/3] Preparing")
+			clientset, err := f.ClientSet()
+			if err != nil {
+				stopFn()
+				return fmt.Errorf("failed to get client: %w", err)
+			}
+			if err := checkAccess(ctx, f, permUpdateConfigMap); err != nil {
+				stopFn()
+				return err
+			}
+			stopFn()
+
+			stopFn = startSpinner("[2/3] Getting config map")
+			configMap, err := getAdapterConfigMap(ctx, f, clientset)
+			if err != nil {
+				stopFn()
+				return fmt.Errorf("failed to get adapter ConfigMap: %w", err)
+			}
+			stopFn()
+
+			original, err := adapters.Encode(configMap.Data, adapters.FormatYAML)
+			if err != nil {
+				return fmt.Errorf("failed to encode adapters: %w", err)
+			}
+
+			header := fmt.Sprintf("# Adapters from ConfigMap %s/%s as <name>: <url>.\n# Lines starting with '#' are ignored. An empty file cancels the edit.\n", configMap.Namespace, configMap.Name)
+			content := append([]byte(header), original...)
+			var desired map[string]string
+			for {
+				edited, err := editInEditor(content, "oiler-adapters-*.yaml")
+				if err != nil {
+					return fmt.Errorf("failed to edit adapters: %w", err)
+				}
+				body := stripComments(edited)
+				if len(bytes.TrimSpace(body)) == 0 || bytes.Equal(body, stripComments(content)) {
+					log.Info("Edit cancelled, no changes made")
+					return nil
+				}
+
+				desired, err = adapters.Decode(body, adapters.FormatYAML)
+				if err == nil {
+					err = adapters.Validate(desired)
+				}
+				if err == nil {
+					break
+				}
+				content = append([]byte("# ERROR: "+strings.ReplaceAll(err.Error(), "\n", "\n# ")+"\n#\n"+header), body...)
+			}
+
+			refreshAliases(configMap, desired)
+			changes := adapters.Diff(configMap.Data, desired)
+			renderAdapterChanges(cmd.OutOrStdout(), configMap.Data, desired, changes)
+			if changes.Empty() {
+				log.Info("Edit cancelled, no changes made")
+				return nil
+			}
+			if err := checkRemovedAdaptersUnused(ctx, f, changes.Removed, force); err != nil {
+				return err
+			}
+
+			stopFn = startSpinner("[3/3] Updating config map")
+			if err := saveAdapters(ctx, clientset, configMap, desired); err != nil {
+				stopFn()
+				return fmt.Errorf("failed to update ConfigMap: %w", err)
+			}
+			stopFn()
+			log.Infof("Successfully updated ConfigMap %s", configMap.Name)
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&force, "force", false, "Remove adapters even if BackupRequests use them")
+	return cmd
+}
+
+// renderAdapterChanges prints a table of adapters that differ between current and desired.
+func renderAdapterChanges(w io.Writer, current, desired map[string]string, changes adapters.Changes) {
+	t := table.NewWriter()
+	t.SetOutputMirror(w)
+	t.SetStyle(table.StyleLight)
+	t.AppendHeader(table.Row{"Change", "Adapter Name", "Old URI", "New URI"})
+	for _, name := range changes.Added {
+		t.AppendRow(table.Row{"added", name, "", desired[name]})
+	}
+	for _, name := range changes.Changed {
+		t.AppendRow(table.Row{"changed", name, current[name], desired[name]})
+	}
+	for _, name := range changes.Removed {
+		t.AppendRow(table.Row{"removed", name, current[name], ""})
+	}
+	t.AppendFooter(table.Row{"", "TOTAL", "", fmt.Sprintf("+%d ~%d -%d", len(changes.Added), len(changes.Changed), len(changes.Removed))})
+	t.Render()
+}
+
+// checkRemovedAdaptersUnused fails if any of removed adapters is still used by BackupRequests.
+func checkRemovedAdaptersUnused(ctx context.Context, f Factory, removed []string, force bool) error {
+	if len(removed) == 0 {
+		return nil
+	}
+
+	dynClient, err := f.DynamicClient()
+	if err != nil {
+		return fmt.Errorf("failed to get client: %w", err)
+	}
+	backupRequests, err := listBackupRequests(ctx, dynClient, metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to get BackupRequests: %w", err)
+	}
+	usage := adapterUsage(backupRequests)
+
+	var inUse []string
+	for _, name := range removed {
+		if n := len(usage[name]); n > 0 {
+			inUse = append(inUse, fmt.Sprintf("%s (%d)", name, n))
+		}
+	}
+	if len(inUse) == 0 {
+		return nil
+	}
+	if !force {
+		return conflictErrorf("removed adapters are used by BackupRequests: %s, use --force to remove anyway", strings.Join(inUse, ", "))
+	}
+	log.Warnf("Removing adapters used by BackupRequests: %s", strings.Join(inUse, ", "))
+	return nil
+}
+
+// saveAdapters replaces data of configMap with entries.
+// The update relies on resourceVersion, so concurrent changes are not overwritten.
+func saveAdapters(ctx context.Context, clientset kubernetes.Interface, configMap *corev1.ConfigMap, entries map[string]string) error {
+	updated := configMap.DeepCopy()
+	updated.Data = entries
+	_, err := clientset.CoreV1().ConfigMaps(updated.Namespace).Update(ctx, updated, metav1.UpdateOptions{})
+	return err
+}
+
+// editInEditor opens content in $VISUAL or $EDITOR as a temporary file named after pattern and returns the edited result.
+func editInEditor(content []byte, pattern string) ([]byte, error) {
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = "vi"
+	}
+
+	f, err := os.CreateTemp("", pattern)
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.Write(content); err != nil {
+		f.Close()
+		return nil, err
+	}
+	if err := f.Close(); err != nil {
+		return nil, err
+	}
+
+	parts := strings.Fields(editor)
+	c := exec.Command(parts[0], append(parts[1:], f.Name())...)
+	c.Stdin, c.Stdout, c.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := c.Run(); err != nil {
+		return nil, fmt.Errorf("editor %q failed: %w", editor, err)
+	}
+
+	return os.ReadFile(f.Name())
+}
+
+// stripComments drops lines starting with '#'.
+func stripComments(data []byte) []byte {
+	var out bytes.Buffer
+	for _, line := range strings.SplitAfter(string(data), "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "#") {
+			continue
+		}
+		out.WriteString(line)
+	}
+	return out.Bytes()
+}
@@ -1,155 +1,330 @@
 package cmd
 
 import (
-	"context"
-	"os"
+	"fmt"
+	"maps"
 	"strings"
 
 	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/oiler-backup/cli/internal/adapters"
+	"github.com/oiler-backup/cli/internal/health"
+	backupv1 "github.com/oiler-backup/core/core/api/v1"
 	"github.com/spf13/cobra"
-	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
-// adapterCmd is a top-level command for actions with adapters ConfigMap.
-var adapterCmd = &cobra.Command{
-	Use:   "adapter",
-	Short: "Manage adapters",
-	Long:  `Manage adapters in the cluster.`,
+// newAdapterCmd returns the top-level command for actions with adapters ConfigMap.
+func newAdapterCmd(f Factory) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "adapter",
+		Short: "Manage adapters",
+		Long:  `Manage adapters in the cluster.`,
+	}
+
+	cmd.AddCommand(newAdapterAddCmd(f))
+	cmd.AddCommand(newAdapterDeleteCmd(f))
+	cmd.AddCommand(newAdapterListCmd(f))
+	cmd.AddCommand(newAdapterHealthCmd(f))
+	cmd.AddCommand(newAdapterDescribeCmd(f))
+	cmd.AddCommand(newAdapterExportCmd(f))
+	cmd.AddCommand(newAdapterImportCmd(f))
+	cmd.AddCommand(newAdapterEditCmd(f))
+	cmd.AddCommand(newAdapterRenameCmd(f))
+	cmd.AddCommand(newAdapterSetDefaultCmd(f))
+	return cmd
 }
 
-// adapterAddCmd adds new adapter to ConfigMap.
-var adapterAddCmd = &cobra.Command{
-	Use:   "add <name>=<url>",
-	Short: "Add an adapter to the ConfigMap",
-	Long:  `Add an adapter to the ConfigMap in the specified namespace.`,
-	Args:  cobra.ExactArgs(1),
-	Run: func(cmd *cobra.Command, args []string) {
-		stopFn := startSpinner("[1/3] Preparing")
-		arg := args[0]
-		parts := strings.SplitN(arg, "=", 2)
-		if len(parts) != 2 {
+// newAdapterAddCmd returns a command that adds new adapter to ConfigMap.
+func newAdapterAddCmd(f Factory) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "add <name>=<url>",
+		Short: "Add an adapter to the ConfigMap",
+		Long: `Add an adapter to the ConfigMap in the specified namespace.
+
+URL is either a gRPC address host:port (optionally prefixed with grpc:// or grpcs://)
+or an HTTP(S) URL.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			stopFn := startSpinner("[1/3] Preparing")
+			arg := args[0]
+			parts := strings.SplitN(arg, "=", 2)
+			if len(parts) != 2 {
+				stopFn()
+				return usageErrorf("invalid argument format, use <name>=<url>")
+			}
+
+			name := parts[0]
+			url := parts[1]
+			if _, err := health.ParseURL(url); err != nil {
+				stopFn()
+				return usageErrorf("invalid adapter URL: %w", err)
+			}
+
+			clientset, err := f.ClientSet()
+			if err != nil {
+				stopFn()
+				return fmt.Errorf("failed to get client: %w", err)
+			}
+			stopFn()
+
+			stopFn = startSpinner("[2/3] Getting config map")
+			configMap, err := getAdapterConfigMap(ctx, f, clientset)
+			if err != nil {
+				stopFn()
+				return fmt.Errorf("failed to get adapter ConfigMap: %w", err)
+			}
+			stopFn()
+
+			stopFn = startSpinner("[3/3] Updating existing config map")
+			desired := maps.Clone(configMap.Data)
+			desired[name] = url
+			refreshed := refreshAliases(configMap, desired)
+			configMap.Data = desired
+
+			_, err = clientset.CoreV1().ConfigMaps(configMap.Namespace).Update(ctx, configMap, metav1.UpdateOptions{})
+			if err != nil {
+				stopFn()
+				return fmt.Errorf("failed to update ConfigMap: %w", err)
+			}
 			stopFn()
-			log.Fatalf("Invalid argument format. Use <name>=<url>")
-		}
+			log.Infof("Successfully updated ConfigMap %s with entry %s=%s", configMap.Name, name, url)
+			if len(refreshed) > 0 {
+				log.Infof("Default adapter entries %s follow %s to the new URL", strings.Join(refreshed, ", "), name)
+			}
+			return nil
+		},
+	}
+	return cmd
+}
+
+// newAdapterDeleteCmd returns a command that deletes existing adapter from ConfigMap.
+func newAdapterDeleteCmd(f Factory) *cobra.Command {
+	var force bool
+	cmd := &cobra.Command{
+		Use:   "delete <name>",
+		Short: "Delete an adapter from the ConfigMap",
+		Long: `Delete an adapter from the ConfigMap in the specified namespace.
 
-		name := parts[0]
-		url := parts[1]
+Deletion is refused while BackupRequests rely on the adapter unless --force is set.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			name := args[0]
 
-		clientset, err := getClientSet()
+			stopFn := startSpinner("[1/4] Preparing")
+			clientset, err := f.ClientSet()
+			if err != nil {
+				stopFn()
+				return fmt.Errorf("failed to get client: %w", err)
+			}
+			dynClient, err := f.DynamicClient()
+			if err != nil {
+				stopFn()
+				return fmt.Errorf("failed to get client: %w", err)
+			}
+			stopFn()
 
-		stopFn()
+			stopFn = startSpinner("[2/4] Getting config map")
+			configMap, err := getAdapterConfigMap(ctx, f, clientset)
+			if err != nil {
+				stopFn()
+				return fmt.Errorf("failed to get adapter ConfigMap: %w", err)
+			}
 
-		stopFn = startSpinner("[2/3] Getting config map")
-		configMap, err := clientset.CoreV1().ConfigMaps(cfg.Namespace).Get(context.TODO(), CM_NAME, metav1.GetOptions{})
-		if err != nil {
-			configMap = &corev1.ConfigMap{
-				ObjectMeta: metav1.ObjectMeta{
-					Name:      CM_NAME,
-					Namespace: cfg.Namespace,
-				},
-				Data: map[string]string{
-					name: url,
-				},
+			if _, exists := configMap.Data[name]; !exists {
+				stopFn()
+				log.Infof("Entry %s is not found in ConfigMap %s", name, configMap.Name)
+				return nil
 			}
+			stopFn()
 
-			_, err := clientset.CoreV1().ConfigMaps(cfg.Namespace).Create(context.TODO(), configMap, metav1.CreateOptions{})
+			stopFn = startSpinner("[3/4] Checking dependent BackupRequests")
+			backupRequests, err := listBackupRequests(ctx, dynClient, metav1.ListOptions{})
 			if err != nil {
 				stopFn()
-				log.Fatalf("Failed to create ConfigMap: %v", err)
+				return fmt.Errorf("failed to get BackupRequests: %w", err)
 			}
+			dependents := adapterUsage(backupRequests)[name]
 			stopFn()
-			log.Infof("Successfully created ConfigMap %s with entry %s=%s", CM_NAME, name, url)
-			return
-		}
-		stopFn()
+			if len(dependents) > 0 {
+				names := make([]string, 0, len(dependents))
+				for _, br := range dependents {
+					names = append(names, br.Name)
+				}
+				if !force {
+					return conflictErrorf("adapter %s is used by %d BackupRequest(s): %s, use --force to delete anyway", name, len(names), strings.Join(names, ", "))
+				}
+				log.Warnf("Deleting adapter %s used by %d BackupRequest(s): %s", name, len(names), strings.Join(names, ", "))
+			}
 
-		stopFn = startSpinner("[3/3] Updating existing config map")
-		configMap.Data[name] = url
+			stopFn = startSpinner("[4/4] Updating config map")
+			desired := maps.Clone(configMap.Data)
+			delete(desired, name)
+			refreshAliases(configMap, desired)
+			configMap.Data = desired
+
+			_, err = clientset.CoreV1().ConfigMaps(configMap.Namespace).Update(ctx, configMap, metav1.UpdateOptions{})
+			if err != nil {
+				stopFn()
+				return fmt.Errorf("failed to update ConfigMap: %w", err)
+			}
 
-		_, err = clientset.CoreV1().ConfigMaps(cfg.Namespace).Update(context.TODO(), configMap, metav1.UpdateOptions{})
-		if err != nil {
 			stopFn()
-			log.Fatalf("Failed to update ConfigMap: %v", err)
-		}
-		stopFn()
-		log.Infof("Successfully updated ConfigMap %s with entry %s=%s", CM_NAME, name, url)
-	},
+			log.Infof("Successfully deleted entry %s from ConfigMap %s", name, configMap.Name)
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&force, "force", false, "Delete the adapter even if BackupRequests use it")
+	cmd.ValidArgsFunction = completeAdapterNames(f, 1)
+	return cmd
 }
 
-// adapterDeleteCmd deletes existing adapter from ConfigMap.
-var adapterDeleteCmd = &cobra.Command{
-	Use:   "delete <name>",
-	Short: "Delete an adapter from the ConfigMap",
-	Long:  `Delete an adapter from the ConfigMap in the specified namespace.`,
-	Args:  cobra.ExactArgs(1),
-	Run: func(cmd *cobra.Command, args []string) {
-		name := args[0]
-
-		stopFn := startSpinner("[1/3] Preparing")
-		clientset, err := getClientSet()
-
-		stopFn()
-		stopFn = startSpinner("[2/3] Getting config map")
-		configMap, err := clientset.CoreV1().ConfigMaps(cfg.Namespace).Get(context.TODO(), CM_NAME, metav1.GetOptions{})
-		if err != nil {
+// newAdapterListCmd returns a command that lists all active adapters.
+func newAdapterListCmd(f Factory) *cobra.Command {
+	var showUsage bool
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List all adapters from the ConfigMap",
+		Long: `List all adapters from the ConfigMap in the specified namespace.
+
+With --usage the number of BackupRequests relying on each adapter is shown.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			stopFn := startSpinner("[1/3] Preparing")
+			clientset, err := f.ClientSet()
+			if err != nil {
+				stopFn()
+				return fmt.Errorf("failed to get client: %w", err)
+			}
 			stopFn()
-			log.Fatalf("Failed to get ConfigMap: %v", err)
-		}
 
-		if _, exists := configMap.Data[name]; !exists {
+			stopFn = startSpinner("[2/3] Getting config map")
+			configMap, err := getAdapterConfigMap(ctx, f, clientset)
+			if err != nil {
+				stopFn()
+				return fmt.Errorf("failed to get adapter ConfigMap: %w", err)
+			}
+
+			var usage map[string][]backupv1.BackupRequest
+			if showUsage {
+				dynClient, err := f.DynamicClient()
+				if err != nil {
+					stopFn()
+					return fmt.Errorf("failed to get client: %w", err)
+				}
+				backupRequests, err := listBackupRequests(ctx, dynClient, metav1.ListOptions{})
+				if err != nil {
+					stopFn()
+					return fmt.Errorf("failed to get BackupRequests: %w", err)
+				}
+				usage = adapterUsage(backupRequests)
+			}
 			stopFn()
-			log.Infof("Entry %s is not found in ConfigMap %s", name, CM_NAME)
-			return
-		}
-		stopFn()
 
-		stopFn = startSpinner("[3/3] Updating config map")
-		delete(configMap.Data, name)
+			stopFn = startSpinner("[3/3] Generating results")
+			t := table.NewWriter()
+			t.SetOutputMirror(cmd.OutOrStdout())
+			t.SetStyle(table.StyleLight)
+			header := table.Row{"#", "Adapter Name", "Adapter URI"}
+			if showUsage {
+				header = append(header, "Used By")
+			}
+			t.AppendHeader(header)
+			i := 1
+			for _, name := range adapters.Names(configMap.Data) {
+				row := table.Row{i, name, configMap.Data[name]}
+				if showUsage {
+					row = append(row, len(usage[name]))
+				}
+				t.AppendRow(row)
+				t.AppendSeparator()
+				i++
+			}
+			footer := table.Row{"", "TOTAL", i - 1}
+			if showUsage {
+				footer = append(footer, "")
+			}
+			t.AppendFooter(footer)
 
-		_, err = clientset.CoreV1().ConfigMaps(cfg.Namespace).Update(context.TODO(), configMap, metav1.UpdateOptions{})
-		if err != nil {
 			stopFn()
-			log.Fatalf("Failed to update ConfigMap: %v", err)
-		}
+			t.Render()
+			return nil
+		},
+	}
 
-		stopFn()
-		log.Infof("Successfully deleted entry %s from ConfigMap %s", name, CM_NAME)
-	},
+	cmd.Flags().BoolVar(&showUsage, "usage", false, "Show how many BackupRequests use each adapter")
+	return cmd
 }
 
-// adapterListCmd lists all active adapters.
-var adapterListCmd = &cobra.Command{
-	Use:   "list",
-	Short: "List all adapters from the ConfigMap",
-	Long:  `List all adapters from the ConfigMap in the specified namespace.`,
-	Run: func(cmd *cobra.Command, args []string) {
-		stopFn := startSpinner("[1/3] Preparing")
-		clientset, err := getClientSet()
-		stopFn()
-
-		stopFn = startSpinner("[2/3] Getting config map")
-		configMap, err := clientset.CoreV1().ConfigMaps(cfg.Namespace).Get(context.TODO(), CM_NAME, metav1.GetOptions{})
-		if err != nil {
+// newAdapterDescribeCmd returns a command that shows an adapter and BackupRequests relying on it.
+func newAdapterDescribeCmd(f Factory) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "describe <name>",
+		Short: "Show an adapter and BackupRequests that use it",
+		Long:  `Show an adapter from the ConfigMap and all BackupRequests whose database type refers to it.`,
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			name := args[0]
+
+			stopFn := startSpinner("[1/3] Preparing")
+			clientset, err := f.ClientSet()
+			if err != nil {
+				stopFn()
+				return fmt.Errorf("failed to get client: %w", err)
+			}
+			dynClient, err := f.DynamicClient()
+			if err != nil {
+				stopFn()
+				return fmt.Errorf("failed to get client: %w", err)
+			}
+			stopFn()
+
+			stopFn = startSpinner("[2/3] Getting config map")
+			configMap, err := getAdapterConfigMap(ctx, f, clientset)
+			if err != nil {
+				stopFn()
+				return fmt.Errorf("failed to get adapter ConfigMap: %w", err)
+			}
+			url, exists := configMap.Data[name]
+			if !exists {
+				stopFn()
+				return notFoundErrorf("entry %s is not found in ConfigMap %s", name, configMap.Name)
+			}
 			stopFn()
-			log.Fatalf("Failed to get ConfigMap: %v", err)
-		}
-		stopFn()
-
-		stopFn = startSpinner("[3/3] Generating results")
-		t := table.NewWriter()
-		t.SetOutputMirror(os.Stdout)
-		t.SetStyle(table.StyleLight)
-		t.AppendHeader(table.Row{"#", "Adapter Name", "Adapter URI"})
-		i := 1
-		for name, url := range configMap.Data {
-			t.AppendRow(table.Row{i, name, url})
-			t.AppendSeparator()
-			i++
-		}
-		t.AppendFooter(table.Row{"", "TOTAL", i - 1})
-
-		stopFn()
-		t.Render()
-	},
+
+			stopFn = startSpinner("[3/3] Getting BackupRequests")
+			backupRequests, err := listBackupRequests(ctx, dynClient, metav1.ListOptions{})
+			if err != nil {
+				stopFn()
+				return fmt.Errorf("failed to get BackupRequests: %w", err)
+			}
+			dependents := adapterUsage(backupRequests)[name]
+			stopFn()
+
+			fmt.Fprintf(cmd.OutOrStdout(), "Name:       %s\n", name)
+			fmt.Fprintf(cmd.OutOrStdout(), "URI:        %s\n", url)
+			fmt.Fprintf(cmd.OutOrStdout(), "ConfigMap:  %s/%s\n", configMap.Namespace, configMap.Name)
+			fmt.Fprintf(cmd.OutOrStdout(), "Used By:    %d BackupRequest(s)\n", len(dependents))
+			if len(dependents) == 0 {
+				return nil
+			}
+
+			t := table.NewWriter()
+			t.SetOutputMirror(cmd.OutOrStdout())
+			t.SetStyle(table.StyleLight)
+			t.AppendHeader(table.Row{"#", "BackupRequest Name", "Database URI", "Database Name", "Schedule", "Status"})
+			for i, br := range dependents {
+				t.AppendRow(table.Row{i + 1, br.Name, br.Spec.DbSpec.URI, br.Spec.DbSpec.DbName, br.Spec.Schedule, br.Status.Status})
+				t.AppendSeparator()
+			}
+			t.Render()
+			return nil
+		},
+	}
+
+	cmd.ValidArgsFunction = completeAdapterNames(f, 1)
+	return cmd
 }
@@ -0,0 +1,319 @@
+package cmd
+
+import (
+	"errors"
+	"maps"
+	"strings"
+	"testing"
+
+	backupv1 "github.com/oiler-backup/core/core/api/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// testAdapters returns adapter entries most adapter tests start from.
+func testAdapters() map[string]string {
+	return map[string]string{
+		"postgres": "postgres-adapter.oiler-backup-system:50051",
+		"mysql":    "http://mysql-adapter.oiler-backup-system:8080",
+		"redis":    "grpc://redis-adapter.oiler-backup-system:50051",
+	}
+}
+
+// testAdapterUsers returns BackupRequests relying on the adapters of testAdapters.
+func testAdapterUsers() []backupv1.BackupRequest {
+	return []backupv1.BackupRequest{
+		newBackupRequest("billing", "postgres", "0 2 * * *", "Success", nil),
+		newBackupRequest("sessions", "postgres", "0 * * * *", "", nil),
+		newBackupRequest("orders", "mysql", "*/30 * * * *", "Failed", nil),
+	}
+}
+
+func TestAdapterList(t *testing.T) {
+	tests := []struct {
+		name   string
+		args   []string
+		golden string
+	}{
+		{name: "plain", args: []string{"adapter", "list"}, golden: "adapter_list"},
+		{name: "usage", args: []string{"adapter", "list", "--usage"}, golden: "adapter_list_usage"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newFakeFactory(t, testAdapters(), testAdapterUsers()...)
+			out, err := runCmd(t, f, tt.args...)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			assertGolden(t, tt.golden, out)
+		})
+	}
+}
+
+func TestAdapterListOverride(t *testing.T) {
+	f := newFakeFactory(t, testAdapters())
+	_, err := runCmd(t, f, "adapter", "list", "--adapter-configmap", "other/adapters")
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	assertExitCode(t, err, exitNotFound)
+}
+
+func TestAdapterAdd(t *testing.T) {
+	tests := []struct {
+		name string
+		arg  string
+		want map[string]string
+	}{
+		{
+			name: "new",
+			arg:  "mongodb=mongodb-adapter:50051",
+			want: map[string]string{"mongodb": "mongodb-adapter:50051"},
+		},
+		{
+			name: "replace",
+			arg:  "mysql=https://mysql-adapter-v2:8443",
+			want: map[string]string{"mysql": "https://mysql-adapter-v2:8443"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newFakeFactory(t, testAdapters())
+			if _, err := runCmd(t, f, "adapter", "add", tt.arg); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			want := testAdapters()
+			maps.Copy(want, tt.want)
+			if got := f.adapterEntries(t); !maps.Equal(got, want) {
+				t.Errorf("adapters = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestAdapterAddErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{name: "no url", args: []string{"adapter", "add", "mongodb"}},
+		{name: "invalid url", args: []string{"adapter", "add", "mongodb=ftp://mongodb-adapter"}},
+		{name: "no arguments", args: []string{"adapter", "add"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newFakeFactory(t, testAdapters())
+			_, err := runCmd(t, f, tt.args...)
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+			assertExitCode(t, err, exitUsage)
+			if got := f.adapterEntries(t); !maps.Equal(got, testAdapters()) {
+				t.Errorf("adapters changed to %v", got)
+			}
+		})
+	}
+}
+
+func TestAdapterDelete(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		code    int
+		removed string
+	}{
+		{name: "unused", args: []string{"adapter", "delete", "redis"}, removed: "redis"},
+		{name: "in use", args: []string{"adapter", "delete", "postgres"}, code: exitConflict},
+		{name: "in use forced", args: []string{"adapter", "delete", "postgres", "--force"}, removed: "postgres"},
+		{name: "missing", args: []string{"adapter", "delete", "mongodb"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newFakeFactory(t, testAdapters(), testAdapterUsers()...)
+			_, err := runCmd(t, f, tt.args...)
+			assertExitCode(t, err, tt.code)
+
+			want := testAdapters()
+			delete(want, tt.removed)
+			if got := f.adapterEntries(t); !maps.Equal(got, want) {
+				t.Errorf("adapters = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestAdapterDescribe(t *testing.T) {
+	tests := []struct {
+		name   string
+		args   []string
+		golden string
+	}{
+		{name: "used", args: []string{"adapter", "describe", "postgres"}, golden: "adapter_describe"},
+		{name: "unused", args: []string{"adapter", "describe", "redis"}, golden: "adapter_describe_unused"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newFakeFactory(t, testAdapters(), testAdapterUsers()...)
+			out, err := runCmd(t, f, tt.args...)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			assertGolden(t, tt.golden, out)
+		})
+	}
+}
+
+func TestAdapterDescribeNotFound(t *testing.T) {
+	f := newFakeFactory(t, testAdapters())
+	_, err := runCmd(t, f, "adapter", "describe", "mongodb")
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	assertExitCode(t, err, exitNotFound)
+}
+
+func TestAdapterRename(t *testing.T) {
+	tests := []struct {
+		name       string
+		args       []string
+		code       int
+		renamed    bool
+		backupType string
+	}{
+		{name: "unused", args: []string{"adapter", "rename", "redis", "valkey"}, renamed: true, backupType: "postgres"},
+		{name: "in use", args: []string{"adapter", "rename", "postgres", "pg"}, code: exitConflict, backupType: "postgres"},
+		{name: "in use forced", args: []string{"adapter", "rename", "postgres", "pg", "--force"}, renamed: true, backupType: "postgres"},
+		{name: "update backups", args: []string{"adapter", "rename", "postgres", "pg", "--update-backups"}, renamed: true, backupType: "pg"},
+		{name: "dry run", args: []string{"adapter", "rename", "postgres", "pg", "--update-backups", "--dry-run"}, backupType: "postgres"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newFakeFactory(t, testAdapters(), testAdapterUsers()...)
+			_, err := runCmd(t, f, tt.args...)
+			assertExitCode(t, err, tt.code)
+
+			want := testAdapters()
+			if oldName, newName := tt.args[2], tt.args[3]; tt.renamed {
+				want[newName] = want[oldName]
+				delete(want, oldName)
+			}
+			if got := f.adapterEntries(t); !maps.Equal(got, want) {
+				t.Errorf("adapters = %v, want %v", got, want)
+			}
+			for _, name := range []string{"billing", "sessions"} {
+				if got := f.backupRequest(t, name).Spec.DbSpec.DbType; got != tt.backupType {
+					t.Errorf("BackupRequest %s has dbType %s, want %s", name, got, tt.backupType)
+				}
+			}
+		})
+	}
+}
+
+func TestAdapterRenameFailedPatch(t *testing.T) {
+	f := newFakeFactory(t, testAdapters(), testAdapterUsers()...)
+	f.dynClient.PrependReactor("patch", "backuprequests", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		if action.(k8stesting.PatchAction).GetName() == "sessions" {
+			return true, nil, errors.New("admission webhook denied the request")
+		}
+		return false, nil, nil
+	})
+
+	_, err := runCmd(t, f, "adapter", "rename", "postgres", "pg", "--update-backups")
+	if err == nil || !strings.Contains(err.Error(), "postgres is kept") {
+		t.Fatalf("error = %v, want one saying postgres is kept", err)
+	}
+	want := testAdapters()
+	want["pg"] = want["postgres"]
+	if got := f.adapterEntries(t); !maps.Equal(got, want) {
+		t.Errorf("adapters = %v, want %v with both names", got, want)
+	}
+	if got := f.backupRequest(t, "billing").Spec.DbSpec.DbType; got != "pg" {
+		t.Errorf("BackupRequest billing has dbType %s, want pg", got)
+	}
+	if got := f.backupRequest(t, "sessions").Spec.DbSpec.DbType; got != "postgres" {
+		t.Errorf("BackupRequest sessions has dbType %s, want postgres", got)
+	}
+}
+
+func TestAdapterSetDefault(t *testing.T) {
+	tests := []struct {
+		name       string
+		args       []string
+		code       int
+		want       map[string]string
+		backupType string
+	}{
+		{
+			name:       "new entry",
+			args:       []string{"adapter", "set-default", "pg", "postgres"},
+			want:       map[string]string{"pg": testAdapters()["postgres"]},
+			backupType: "mysql",
+		},
+		{
+			name:       "adapter entry",
+			args:       []string{"adapter", "set-default", "mysql", "postgres"},
+			code:       exitConflict,
+			backupType: "mysql",
+		},
+		{
+			name:       "adapter entry forced",
+			args:       []string{"adapter", "set-default", "mysql", "postgres", "--force"},
+			want:       map[string]string{"mysql": testAdapters()["postgres"]},
+			backupType: "mysql",
+		},
+		{
+			name:       "update backups",
+			args:       []string{"adapter", "set-default", "mysql", "postgres", "--force", "--update-backups"},
+			want:       map[string]string{"mysql": testAdapters()["postgres"]},
+			backupType: "postgres",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newFakeFactory(t, testAdapters(), testAdapterUsers()...)
+			_, err := runCmd(t, f, tt.args...)
+			assertExitCode(t, err, tt.code)
+
+			want := testAdapters()
+			maps.Copy(want, tt.want)
+			if got := f.adapterEntries(t); !maps.Equal(got, want) {
+				t.Errorf("adapters = %v, want %v", got, want)
+			}
+			if got := f.backupRequest(t, "orders").Spec.DbSpec.DbType; got != tt.backupType {
+				t.Errorf("BackupRequest orders has dbType %s, want %s", got, tt.backupType)
+			}
+		})
+	}
+}
+
+func TestAdapterAliasRefresh(t *testing.T) {
+	f := newFakeFactory(t, testAdapters())
+	if _, err := runCmd(t, f, "adapter", "set-default", "pg", "postgres"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// An alias may be pointed at another adapter without --force.
+	if _, err := runCmd(t, f, "adapter", "set-default", "pg", "redis"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if _, err := runCmd(t, f, "adapter", "add", "redis=redis-adapter-v2:50051"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := f.adapterEntries(t)["pg"]; got != "redis-adapter-v2:50051" {
+		t.Errorf("alias pg = %s, want the new URL of redis", got)
+	}
+
+	if _, err := runCmd(t, f, "adapter", "add", "pg=pg-adapter:50051"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := runCmd(t, f, "adapter", "add", "redis=redis-adapter-v3:50051"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := f.adapterEntries(t)["pg"]; got != "pg-adapter:50051" {
+		t.Errorf("pg = %s, want the URL it was set to after it stopped being an alias", got)
+	}
+	if _, err := runCmd(t, f, "adapter", "set-default", "pg", "redis"); err == nil {
+		t.Error("set-default replaced pg, which is no longer an alias, without --force")
+	}
+}
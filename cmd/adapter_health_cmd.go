@@ -0,0 +1,170 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/oiler-backup/cli/internal/health"
+	"github.com/spf13/cobra"
+	"k8s.io/client-go/kubernetes"
+)
+
+// Ways to reach adapters from adapter health.
+const (
+	probeViaDirect = "direct"
+	probeViaProxy  = "proxy"
+	probeViaPod    = "pod"
+)
+
+// adapterProbeFlags choose how adapters are probed.
+type adapterProbeFlags struct {
+	timeout   time.Duration
+	via       string
+	httpImage string
+	grpcImage string
+}
+
+// defaultProbeFlags are the defaults of adapter health flags, also used where adapters are probed without them.
+var defaultProbeFlags = adapterProbeFlags{
+	timeout:   5 * time.Second,
+	via:       probeViaDirect,
+	httpImage: health.DefaultHTTPProbeImage,
+	grpcImage: health.DefaultGRPCProbeImage,
+}
+
+// newAdapterHealthCmd returns a command that probes adapters registered in ConfigMap.
+func newAdapterHealthCmd(f Factory) *cobra.Command {
+	flags := defaultProbeFlags
+	cmd := &cobra.Command{
+		Use:   "health [name]",
+		Short: "Probe adapter endpoints",
+		Long: `Probe adapter endpoints from the ConfigMap and report their status, latency and version.
+
+HTTP adapters are checked with GET on their health endpoint (/healthz unless the URL has a path),
+gRPC adapters with the grpc.health.v1 protocol. Use --via=proxy or --via=pod to probe
+from inside the cluster through the API-server service proxy or a temporary pod.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			stopFn := startSpinner("[1/3] Preparing")
+			switch flags.via {
+			case probeViaDirect, probeViaProxy, probeViaPod:
+			default:
+				stopFn()
+				return usageErrorf("unknown --via value %q, use %s, %s or %s", flags.via, probeViaDirect, probeViaProxy, probeViaPod)
+			}
+
+			cfg, err := f.Config()
+			if err != nil {
+				stopFn()
+				return err
+			}
+			clientset, err := f.ClientSet()
+			if err != nil {
+				stopFn()
+				return fmt.Errorf("failed to get client: %w", err)
+			}
+			stopFn()
+
+			stopFn = startSpinner("[2/3] Getting config map")
+			configMap, err := getAdapterConfigMap(ctx, f, clientset)
+			if err != nil {
+				stopFn()
+				return fmt.Errorf("failed to get adapter ConfigMap: %w", err)
+			}
+
+			adapters := configMap.Data
+			if len(args) == 1 {
+				uri, exists := configMap.Data[args[0]]
+				if !exists {
+					stopFn()
+					return notFoundErrorf("entry %s is not found in ConfigMap %s", args[0], configMap.Name)
+				}
+				adapters = map[string]string{args[0]: uri}
+			}
+			stopFn()
+
+			stopFn = startSpinner("[3/3] Probing adapters")
+			names := make([]string, 0, len(adapters))
+			for name := range adapters {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+
+			results := make([]health.Result, len(names))
+			var wg sync.WaitGroup
+			for i, name := range names {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					results[i] = probeAdapter(ctx, clientset, cfg.Namespace, adapters[name], flags)
+				}()
+			}
+			wg.Wait()
+			stopFn()
+
+			t := table.NewWriter()
+			t.SetOutputMirror(cmd.OutOrStdout())
+			t.SetStyle(table.StyleLight)
+			t.AppendHeader(table.Row{"#", "Adapter Name", "Adapter URI", "Status", "Latency", "Version", "Details"})
+			unhealthy := 0
+			for i, name := range names {
+				res := results[i]
+				if !res.Healthy() {
+					unhealthy++
+				}
+				details := ""
+				if res.Err != nil {
+					details = res.Err.Error()
+				}
+				version := res.Version
+				if version == "" {
+					version = "-"
+				}
+				t.AppendRow(table.Row{i + 1, name, adapters[name], res.Status, res.Latency.Round(time.Millisecond), version, details})
+				t.AppendSeparator()
+			}
+			t.AppendFooter(table.Row{"", "", "", "", "", "UNHEALTHY", fmt.Sprintf("%d/%d", unhealthy, len(names))})
+			t.Render()
+
+			if unhealthy > 0 {
+				return fmt.Errorf("%d of %d adapters are unhealthy", unhealthy, len(names))
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().DurationVar(&flags.timeout, "timeout", defaultProbeFlags.timeout, "Timeout for a single adapter probe")
+	cmd.Flags().StringVar(&flags.via, "via", defaultProbeFlags.via, "How to reach adapters: direct, proxy (API-server service proxy) or pod (temporary pod)")
+	cmd.Flags().StringVar(&flags.httpImage, "probe-image-http", defaultProbeFlags.httpImage, "Image used to probe HTTP adapters with --via=pod")
+	cmd.Flags().StringVar(&flags.grpcImage, "probe-image-grpc", defaultProbeFlags.grpcImage, "Image used to probe gRPC adapters with --via=pod")
+	cmd.ValidArgsFunction = completeAdapterNames(f, 1)
+	registerFlagCompletion(cmd, "via", cobra.FixedCompletions([]string{probeViaDirect, probeViaProxy, probeViaPod}, cobra.ShellCompDirectiveNoFileComp))
+	return cmd
+}
+
+// probeAdapter validates raw adapter URL and probes it the way flags request.
+func probeAdapter(ctx context.Context, clientset kubernetes.Interface, namespace, raw string, flags adapterProbeFlags) health.Result {
+	u, err := health.ParseURL(raw)
+	if err != nil {
+		return health.Result{Status: health.StatusUnknown, Err: err}
+	}
+
+	switch flags.via {
+	case probeViaProxy:
+		return health.ProbeViaProxy(ctx, clientset, namespace, u, flags.timeout)
+	case probeViaPod:
+		return health.ProbeViaPod(ctx, clientset, namespace, u, health.PodOptions{
+			HTTPImage:      flags.httpImage,
+			GRPCImage:      flags.grpcImage,
+			Timeout:        flags.timeout,
+			StartupTimeout: time.Minute,
+		})
+	default:
+		return health.Probe(ctx, u, flags.timeout)
+	}
+}
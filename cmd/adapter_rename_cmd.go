@@ -0,0 +1,363 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/oiler-backup/cli/internal/rbac"
+	backupv1 "github.com/oiler-backup/core/core/api/v1"
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic"
+)
+
+// defaultAdapterAnnotationPrefix marks a db type entry that is an alias of another adapter.
+const defaultAdapterAnnotationPrefix = "oiler.backup/default."
+
+// adapterRenameFlags are the flags of adapter rename and set-default.
+type adapterRenameFlags struct {
+	updateBackups bool
+	force         bool
+	dryRun        bool
+}
+
+// A planStep is a single change printed before it is applied.
+type planStep struct {
+	Object string
+	Change string
+}
+
+// newAdapterRenameCmd returns a command that renames an adapter in a single ConfigMap update.
+func newAdapterRenameCmd(f Factory) *cobra.Command {
+	flags := &adapterRenameFlags{}
+	cmd := &cobra.Command{
+		Use:   "rename <old> <new>",
+		Short: "Rename an adapter",
+		Long: `Rename an adapter in the ConfigMap in a single atomic update.
+
+BackupRequests using the old name are refused unless --update-backups rewrites their
+database type to the new name, or --force is set. With --update-backups the new name is added
+first and the old one is removed only after every BackupRequest is rewritten, so none of them
+is left without an adapter.`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			oldName, newName := args[0], args[1]
+
+			stopFn := startSpinner("[1/5] Preparing")
+			if oldName == newName {
+				stopFn()
+				return usageErrorf("old and new adapter names are the same")
+			}
+			clientset, err := f.ClientSet()
+			if err != nil {
+				stopFn()
+				return fmt.Errorf("failed to get client: %w", err)
+			}
+			dynClient, err := f.DynamicClient()
+			if err != nil {
+				stopFn()
+				return fmt.Errorf("failed to get client: %w", err)
+			}
+			perms := []rbac.Permission{permUpdateConfigMap}
+			if flags.updateBackups {
+				perms = append(perms, permPatchBackups)
+			}
+			if !flags.dryRun {
+				if err := checkAccess(ctx, f, perms...); err != nil {
+					stopFn()
+					return err
+				}
+			}
+			stopFn()
+
+			stopFn = startSpinner("[2/5] Getting config map")
+			configMap, err := getAdapterConfigMap(ctx, f, clientset)
+			if err != nil {
+				stopFn()
+				return fmt.Errorf("failed to get adapter ConfigMap: %w", err)
+			}
+			url, exists := configMap.Data[oldName]
+			if !exists {
+				stopFn()
+				return notFoundErrorf("entry %s is not found in ConfigMap %s", oldName, configMap.Name)
+			}
+			if _, exists := configMap.Data[newName]; exists {
+				stopFn()
+				return conflictErrorf("entry %s already exists in ConfigMap %s", newName, configMap.Name)
+			}
+			backupRequests, err := listBackupRequests(ctx, dynClient, metav1.ListOptions{})
+			if err != nil {
+				stopFn()
+				return fmt.Errorf("failed to get BackupRequests: %w", err)
+			}
+			dependents := adapterUsage(backupRequests)[oldName]
+			stopFn()
+
+			// With --update-backups both names exist until BackupRequests use the new one.
+			twoStep := flags.updateBackups && len(dependents) > 0
+			updated := configMap.DeepCopy()
+			updated.Data[newName] = url
+			if !twoStep {
+				delete(updated.Data, oldName)
+			}
+			configMapObject := "ConfigMap " + configMap.Namespace + "/" + configMap.Name
+			plan := []planStep{{Object: configMapObject, Change: fmt.Sprintf("add %s (%s)", newName, url)}}
+			if !twoStep {
+				plan = []planStep{{Object: configMapObject, Change: fmt.Sprintf("rename %s -> %s (%s)", oldName, newName, url)}}
+			}
+			for key, value := range configMap.Annotations {
+				dbType, isDefault := strings.CutPrefix(key, defaultAdapterAnnotationPrefix)
+				if isDefault && value == oldName {
+					updated.Annotations[key] = newName
+					plan = append(plan, planStep{
+						Object: configMapObject,
+						Change: fmt.Sprintf("default for %s: %s -> %s", dbType, oldName, newName),
+					})
+				}
+			}
+			for _, br := range dependents {
+				change := "keeps dbType " + oldName + " (will fail until updated)"
+				if flags.updateBackups {
+					change = fmt.Sprintf("dbType %s -> %s", oldName, newName)
+				}
+				plan = append(plan, planStep{Object: "BackupRequest " + br.Name, Change: change})
+			}
+			if twoStep {
+				plan = append(plan, planStep{Object: configMapObject, Change: fmt.Sprintf("remove %s once all BackupRequests are updated", oldName)})
+			}
+			renderPlan(cmd.OutOrStdout(), plan)
+
+			if len(dependents) > 0 && !flags.updateBackups && !flags.force {
+				return conflictErrorf("adapter %s is used by %d BackupRequest(s), use --update-backups to rewrite them or --force to rename anyway", oldName, len(dependents))
+			}
+			if flags.dryRun {
+				return nil
+			}
+
+			stopFn = startSpinner("[3/5] Updating config map")
+			updated, err = clientset.CoreV1().ConfigMaps(updated.Namespace).Update(ctx, updated, metav1.UpdateOptions{})
+			if err != nil {
+				stopFn()
+				return fmt.Errorf("failed to update ConfigMap: %w", err)
+			}
+			stopFn()
+
+			if !twoStep {
+				log.Infof("Successfully renamed adapter %s to %s", oldName, newName)
+				return nil
+			}
+
+			failed := updateBackupDbTypes(ctx, f, dynClient, "[4/5] Updating BackupRequests", dependents, newName)
+			if len(failed) > 0 {
+				return withHint(fmt.Errorf("added adapter %s, but failed to update BackupRequests, so %s is kept:\n%s", newName, oldName, strings.Join(failed, "\n")),
+					fmt.Sprintf("fix them with backup update <name> spec.dbSpec.dbType=%s, then remove the old name with adapter delete %s", newName, oldName))
+			}
+
+			stopFn = startSpinner("[5/5] Removing old name")
+			delete(updated.Data, oldName)
+			_, err = clientset.CoreV1().ConfigMaps(updated.Namespace).Update(ctx, updated, metav1.UpdateOptions{})
+			stopFn()
+			if err != nil {
+				return withHint(fmt.Errorf("added adapter %s and updated %d BackupRequest(s), but failed to remove %s: %w", newName, len(dependents), oldName, err),
+					fmt.Sprintf("remove it with adapter delete %s", oldName))
+			}
+			log.Infof("Successfully renamed adapter %s to %s and updated %d BackupRequest(s)", oldName, newName, len(dependents))
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&flags.updateBackups, "update-backups", false, "Rewrite database type of BackupRequests using the old name")
+	cmd.Flags().BoolVar(&flags.force, "force", false, "Rename even if BackupRequests use the old name")
+	cmd.Flags().BoolVar(&flags.dryRun, "dry-run", false, "Only print the plan")
+	cmd.ValidArgsFunction = completeAdapterNames(f, 1)
+	return cmd
+}
+
+// newAdapterSetDefaultCmd returns a command that points a db type at a chosen adapter.
+func newAdapterSetDefaultCmd(f Factory) *cobra.Command {
+	flags := &adapterRenameFlags{}
+	cmd := &cobra.Command{
+		Use:   "set-default <dbType> <name>",
+		Short: "Set the preferred adapter for a database type",
+		Long: `Set the preferred adapter for a database type in a single atomic ConfigMap update.
+
+The entry for <dbType>, which BackupRequests resolve by their database type, gets the URL of
+adapter <name>, and the choice is recorded in the ` + defaultAdapterAnnotationPrefix + `<dbType> annotation.
+Such an alias follows later URL changes of <name> made by adapter add, import and edit.
+An existing <dbType> entry that is not an alias is an adapter of its own and is only replaced with --force.
+--update-backups also rewrites BackupRequests using <dbType> to use <name> directly.`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			dbType, name := args[0], args[1]
+
+			stopFn := startSpinner("[1/4] Preparing")
+			if dbType == name {
+				stopFn()
+				return usageErrorf("database type and adapter name are the same")
+			}
+			clientset, err := f.ClientSet()
+			if err != nil {
+				stopFn()
+				return fmt.Errorf("failed to get client: %w", err)
+			}
+			dynClient, err := f.DynamicClient()
+			if err != nil {
+				stopFn()
+				return fmt.Errorf("failed to get client: %w", err)
+			}
+			perms := []rbac.Permission{permUpdateConfigMap}
+			if flags.updateBackups {
+				perms = append(perms, permPatchBackups)
+			}
+			if !flags.dryRun {
+				if err := checkAccess(ctx, f, perms...); err != nil {
+					stopFn()
+					return err
+				}
+			}
+			stopFn()
+
+			stopFn = startSpinner("[2/4] Getting config map")
+			configMap, err := getAdapterConfigMap(ctx, f, clientset)
+			if err != nil {
+				stopFn()
+				return fmt.Errorf("failed to get adapter ConfigMap: %w", err)
+			}
+			url, exists := configMap.Data[name]
+			if !exists {
+				stopFn()
+				return notFoundErrorf("entry %s is not found in ConfigMap %s", name, configMap.Name)
+			}
+			var dependents []backupv1.BackupRequest
+			if flags.updateBackups {
+				backupRequests, err := listBackupRequests(ctx, dynClient, metav1.ListOptions{})
+				if err != nil {
+					stopFn()
+					return fmt.Errorf("failed to get BackupRequests: %w", err)
+				}
+				dependents = adapterUsage(backupRequests)[dbType]
+			}
+			stopFn()
+
+			previous, exists := configMap.Data[dbType]
+			_, isAlias := configMap.Annotations[defaultAdapterAnnotationPrefix+dbType]
+			if exists && !isAlias && !flags.force {
+				return conflictErrorf("entry %s in ConfigMap %s is an adapter, not an alias set by set-default, use --force to replace it", dbType, configMap.Name)
+			}
+
+			updated := configMap.DeepCopy()
+			updated.Data[dbType] = url
+			if updated.Annotations == nil {
+				updated.Annotations = map[string]string{}
+			}
+			updated.Annotations[defaultAdapterAnnotationPrefix+dbType] = name
+
+			change := fmt.Sprintf("%s = %s (from %s)", dbType, url, name)
+			if exists {
+				change = fmt.Sprintf("%s: %s -> %s (from %s)", dbType, previous, url, name)
+			}
+			plan := []planStep{{Object: "ConfigMap " + configMap.Namespace + "/" + configMap.Name, Change: change}}
+			for _, br := range dependents {
+				plan = append(plan, planStep{Object: "BackupRequest " + br.Name, Change: fmt.Sprintf("dbType %s -> %s", dbType, name)})
+			}
+			renderPlan(cmd.OutOrStdout(), plan)
+			if flags.dryRun {
+				return nil
+			}
+
+			stopFn = startSpinner("[3/4] Updating config map")
+			_, err = clientset.CoreV1().ConfigMaps(updated.Namespace).Update(ctx, updated, metav1.UpdateOptions{})
+			if err != nil {
+				stopFn()
+				return fmt.Errorf("failed to update ConfigMap: %w", err)
+			}
+			stopFn()
+
+			if len(dependents) > 0 {
+				if failed := updateBackupDbTypes(ctx, f, dynClient, "[4/4] Updating BackupRequests", dependents, name); len(failed) > 0 {
+					return fmt.Errorf("set %s as default adapter for %s, but failed to update BackupRequests, they keep using %s:\n%s", name, dbType, dbType, strings.Join(failed, "\n"))
+				}
+				log.Infof("Successfully set %s as default adapter for %s and updated %d BackupRequest(s)", name, dbType, len(dependents))
+				return nil
+			}
+			log.Infof("Successfully set %s as default adapter for %s", name, dbType)
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&flags.updateBackups, "update-backups", false, "Rewrite database type of BackupRequests using <dbType> to <name>")
+	cmd.Flags().BoolVar(&flags.force, "force", false, "Replace a <dbType> entry that is an adapter rather than an alias")
+	cmd.Flags().BoolVar(&flags.dryRun, "dry-run", false, "Only print the plan")
+	cmd.ValidArgsFunction = completeAdapterNames(f, 2)
+	return cmd
+}
+
+// refreshAliases keeps aliases set by adapter set-default in line with entries of configMap changing to desired.
+// An alias gets the new URL of its adapter, and stops being an alias when it is changed or removed itself.
+// desired and annotations of configMap are updated in place; the returned db types are the refreshed aliases.
+func refreshAliases(configMap *corev1.ConfigMap, desired map[string]string) []string {
+	var refreshed []string
+	for key, target := range configMap.Annotations {
+		dbType, isDefault := strings.CutPrefix(key, defaultAdapterAnnotationPrefix)
+		if !isDefault {
+			continue
+		}
+		aliasURL, exists := desired[dbType]
+		if !exists || aliasURL != configMap.Data[dbType] {
+			delete(configMap.Annotations, key)
+			continue
+		}
+		if url, exists := desired[target]; exists && url != configMap.Data[target] && url != aliasURL {
+			desired[dbType] = url
+			refreshed = append(refreshed, dbType)
+		}
+	}
+	slices.Sort(refreshed)
+	return refreshed
+}
+
+// updateBackupDbTypes points backupRequests at adapter name, reporting progress as text.
+// It returns a description of every BackupRequest it failed to update.
+func updateBackupDbTypes(ctx context.Context, f Factory, dynClient dynamic.Interface, text string, backupRequests []backupv1.BackupRequest, name string) []string {
+	bar := startProgress(text, len(backupRequests))
+	patch := []byte(fmt.Sprintf(`{"spec":{"dbSpec":{"dbType":%q}}}`, name))
+	var failed []string
+	var patched []unstructured.Unstructured
+	for _, br := range backupRequests {
+		item, err := dynClient.Resource(gvr).Patch(ctx, br.Name, types.MergePatchType, patch, metav1.PatchOptions{})
+		if err != nil {
+			failed = append(failed, fmt.Sprintf("%s: %v", br.Name, err))
+		} else {
+			patched = append(patched, *item)
+		}
+		bar.Increment()
+	}
+	err := reapplySuspension(ctx, f, dynClient, patched)
+	bar.Done()
+	if err != nil {
+		log.Warnf("%v; run backup suspend on them again", err)
+	}
+	return failed
+}
+
+// renderPlan prints steps that are going to be applied.
+func renderPlan(w io.Writer, plan []planStep) {
+	t := table.NewWriter()
+	t.SetOutputMirror(w)
+	t.SetStyle(table.StyleLight)
+	t.AppendHeader(table.Row{"#", "Object", "Change"})
+	for i, step := range plan {
+		t.AppendRow(table.Row{i + 1, step.Object, step.Change})
+		t.AppendSeparator()
+	}
+	t.Render()
+}
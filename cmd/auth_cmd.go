@@ -0,0 +1,170 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/oiler-backup/cli/internal/rbac"
+	"github.com/spf13/cobra"
+)
+
+// Permissions commands check before starting multi-step work.
+var (
+	permGetBackups      = rbac.Permission{Verb: "get", Group: gvr.Group, Resource: gvr.Resource}
+	permCreateBackups   = rbac.Permission{Verb: "create", Group: gvr.Group, Resource: gvr.Resource}
+	permUpdateBackups   = rbac.Permission{Verb: "update", Group: gvr.Group, Resource: gvr.Resource}
+	permPatchBackups    = rbac.Permission{Verb: "patch", Group: gvr.Group, Resource: gvr.Resource}
+	permDeleteBackups   = rbac.Permission{Verb: "delete", Group: gvr.Group, Resource: gvr.Resource}
+	permUpdateConfigMap = rbac.Permission{Verb: "update", Resource: "configmaps", Namespaced: true}
+)
+
+// A neededPermission is a permission commands of oiler-cli rely on.
+type neededPermission struct {
+	rbac.Permission
+	usedBy string
+}
+
+// neededPermissions are all permissions oiler-cli uses, checked by auth check.
+var neededPermissions = []neededPermission{
+	{permGetBackups, "backup commands"},
+	{rbac.Permission{Verb: "list", Group: gvr.Group, Resource: gvr.Resource}, "backup list, adapter usage"},
+	{rbac.Permission{Verb: "watch", Group: gvr.Group, Resource: gvr.Resource}, "backup watch, backup wait, ui"},
+	{permCreateBackups, "backup create, backup clone"},
+	{permUpdateBackups, "backup update, ui edit"},
+	{permPatchBackups, "backup label, backup suspend, adapter rename"},
+	{permDeleteBackups, "backup delete, ui delete"},
+	{rbac.Permission{Verb: "get", Group: "apiextensions.k8s.io", Resource: "customresourcedefinitions"}, "backup suspend, doctor"},
+	{rbac.Permission{Verb: "get", Resource: "configmaps", Namespaced: true}, "adapter commands, templates"},
+	{rbac.Permission{Verb: "list", Resource: "configmaps", Namespaced: true}, "adapter ConfigMap discovery"},
+	{rbac.Permission{Verb: "create", Resource: "configmaps", Namespaced: true}, "template create --source cluster"},
+	{permUpdateConfigMap, "adapter add, delete, rename, import, edit, set-default; cluster templates"},
+	{rbac.Permission{Verb: "list", Group: "apps", Resource: "deployments", Namespaced: true}, "adapter ConfigMap discovery, doctor"},
+	{rbac.Permission{Verb: "get", Group: "batch", Resource: "cronjobs", Namespaced: true}, "ui run now"},
+	{rbac.Permission{Verb: "patch", Group: "batch", Resource: "cronjobs", Namespaced: true}, "backup suspend without spec.suspend"},
+	{rbac.Permission{Verb: "list", Group: "batch", Resource: "jobs", Namespaced: true}, "ui details"},
+	{rbac.Permission{Verb: "create", Group: "batch", Resource: "jobs", Namespaced: true}, "ui run now"},
+	{rbac.Permission{Verb: "list", Resource: "pods", Namespaced: true}, "ui details, doctor"},
+	{rbac.Permission{Verb: "get", Resource: "pods", Subresource: "log", Namespaced: true}, "ui logs"},
+	{rbac.Permission{Verb: "create", Resource: "pods", Namespaced: true}, "adapter health --via pod"},
+	{rbac.Permission{Verb: "delete", Resource: "pods", Namespaced: true}, "adapter health --via pod"},
+	{rbac.Permission{Verb: "get", Resource: "services", Subresource: "proxy", Namespaced: true}, "adapter health --via proxy, doctor"},
+	{rbac.Permission{Verb: "get", Resource: "services", Namespaced: true}, "doctor"},
+	{rbac.Permission{Verb: "list", Group: "discovery.k8s.io", Resource: "endpointslices", Namespaced: true}, "doctor"},
+	{rbac.Permission{Verb: "list", Group: "admissionregistration.k8s.io", Resource: "validatingwebhookconfigurations"}, "doctor"},
+	{rbac.Permission{Verb: "list", Group: "admissionregistration.k8s.io", Resource: "mutatingwebhookconfigurations"}, "doctor"},
+}
+
+// newAuthCmd returns the top-level command for checking access to the cluster.
+func newAuthCmd(f Factory) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "auth",
+		Short: "Inspect authorization",
+		Long:  `Inspect what the current kubeconfig user is allowed to do in the cluster.`,
+	}
+
+	cmd.AddCommand(newAuthCheckCmd(f))
+	return cmd
+}
+
+// newAuthCheckCmd returns a command that checks all permissions oiler-cli uses.
+func newAuthCheckCmd(f Factory) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "check",
+		Short: "Check permissions oiler-cli needs",
+		Long: `Check every permission oiler-cli uses with SelfSubjectAccessReviews, like kubectl auth can-i,
+and print which commands need it. Namespaced permissions are checked in the operator namespace.
+
+Exits with code 6 if any permission is missing.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			stopFn := startSpinner("[1/2] Preparing")
+			cfg, err := f.Config()
+			if err != nil {
+				stopFn()
+				return err
+			}
+			clientset, err := f.ClientSet()
+			if err != nil {
+				stopFn()
+				return fmt.Errorf("failed to get client: %w", err)
+			}
+			stopFn()
+
+			stopFn = startSpinner("[2/2] Reviewing access")
+			perms := make([]rbac.Permission, 0, len(neededPermissions))
+			for _, p := range neededPermissions {
+				perms = append(perms, p.Permission)
+			}
+			results, err := rbac.Check(ctx, clientset, cfg.Namespace, perms)
+			stopFn()
+			if err != nil {
+				return err
+			}
+
+			t := table.NewWriter()
+			t.SetOutputMirror(cmd.OutOrStdout())
+			t.SetStyle(table.StyleLight)
+			t.AppendHeader(table.Row{"#", "Verb", "Resource", "Namespace", "Allowed", "Used By"})
+			allowed := 0
+			for i, r := range results {
+				answer := "no"
+				if r.Allowed {
+					answer = "yes"
+					allowed++
+				}
+				t.AppendRow(table.Row{i + 1, r.Verb, r.ResourceName(), r.Namespace, answer, neededPermissions[i].usedBy})
+			}
+			t.AppendFooter(table.Row{"", "", "", "ALLOWED", fmt.Sprintf("%d/%d", allowed, len(results)), ""})
+			t.Render()
+
+			if denied := len(results) - allowed; denied > 0 {
+				return &cliError{
+					code: exitForbidden,
+					err:  fmt.Errorf("%d of %d permissions are missing", denied, len(results)),
+					hint: "Ask a cluster administrator to grant the missing permissions to your user.",
+				}
+			}
+			return nil
+		},
+	}
+	return cmd
+}
+
+// checkAccess fails with a forbidden error if f lacks one of perms, so multi-step commands stop before changing anything.
+// It does not get in the way if access cannot be reviewed, the API server has the last word anyway.
+func checkAccess(ctx context.Context, f Factory, perms ...rbac.Permission) error {
+	cfg, err := f.Config()
+	if err != nil {
+		return err
+	}
+	clientset, err := f.ClientSet()
+	if err != nil {
+		return fmt.Errorf("failed to get client: %w", err)
+	}
+	results, err := rbac.Check(ctx, clientset, cfg.Namespace, perms)
+	if err != nil {
+		log.Debugf("Skipping permission check: %v", err)
+		return nil
+	}
+
+	denied := rbac.Denied(results)
+	if len(denied) == 0 {
+		return nil
+	}
+	missing := make([]string, 0, len(denied))
+	for _, r := range denied {
+		if r.Namespace != "" {
+			missing = append(missing, fmt.Sprintf("%s in namespace %s", r.Permission, r.Namespace))
+		} else {
+			missing = append(missing, r.Permission.String())
+		}
+	}
+	return &cliError{
+		code: exitForbidden,
+		err:  fmt.Errorf("not allowed to %s", strings.Join(missing, ", ")),
+		hint: "Run oiler-cli auth check to see all missing permissions, and ask a cluster administrator to grant them.",
+	}
+}
@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"maps"
+	"testing"
+)
+
+func TestAuthCheck(t *testing.T) {
+	f := newFakeFactory(t, nil)
+	out, err := runCmd(t, f, "auth", "check")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	assertGolden(t, "auth_check", out)
+}
+
+func TestAuthCheckDenied(t *testing.T) {
+	f := newFakeFactory(t, nil)
+	f.deny(permUpdateConfigMap, permDeleteBackups)
+	out, err := runCmd(t, f, "auth", "check")
+	assertExitCode(t, err, exitForbidden)
+	assertGolden(t, "auth_check_denied", out)
+}
+
+func TestPermissionPreCheck(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{name: "backup delete", args: []string{"backup", "delete", "billing", "--yes"}},
+		{name: "backup update", args: []string{"backup", "update", "billing", "spec.schedule=0 3 * * *", "--yes"}},
+		{name: "backup suspend", args: []string{"backup", "suspend", "billing"}},
+		{name: "adapter rename", args: []string{"adapter", "rename", "postgres", "pg", "--update-backups"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newFakeFactory(t, testAdapters(), testBackupRequests()...)
+			f.deny(permDeleteBackups, permUpdateBackups, permPatchBackups, permUpdateConfigMap)
+			_, err := runCmd(t, f, tt.args...)
+			assertExitCode(t, err, exitForbidden)
+
+			if got := f.backupRequest(t, "billing"); got.Spec.Schedule != "0 2 * * *" || got.Spec.DbSpec.DbType != "postgres" {
+				t.Errorf("BackupRequest billing changed to %+v", got.Spec)
+			}
+			if got := f.adapterEntries(t); !maps.Equal(got, testAdapters()) {
+				t.Errorf("adapters changed to %v", got)
+			}
+		})
+	}
+}
+
+func TestPermissionPreCheckDryRun(t *testing.T) {
+	f := newFakeFactory(t, nil, testBackupRequests()...)
+	f.deny(permDeleteBackups)
+	if _, err := runCmd(t, f, "backup", "delete", "billing", "--dry-run"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,266 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/spf13/cobra"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// Values of backup clone --credentials.
+const (
+	credentialsCopy   = "copy"
+	credentialsPrompt = "prompt"
+)
+
+// clientSideAnnotations are dropped from clones, they describe the source object only.
+var clientSideAnnotations = []string{
+	"kubectl.kubernetes.io/last-applied-configuration",
+}
+
+// backupCloneFlags are the flags of backup clone.
+type backupCloneFlags struct {
+	db            string
+	s3            string
+	schedule      string
+	namespace     string
+	targetContext string
+	credentials   string
+}
+
+// newBackupCloneCmd returns a command that copies a BackupRequest.
+func newBackupCloneCmd(f Factory) *cobra.Command {
+	flags := &backupCloneFlags{}
+	cmd := &cobra.Command{
+		Use:   "clone <source> <destination>",
+		Short: "Copy a BackupRequest under a new name",
+		Long: `Copy the spec, labels and annotations of a BackupRequest to a new BackupRequest.
+
+Server-side metadata and status are not copied. --db, --s3 and --schedule override the copied values,
+--namespace moves the database address (<service>[.<namespace>[.svc...]]) to another namespace,
+--target-context creates the copy in another cluster from the kubeconfig, while --context selects
+the cluster of the source.
+
+BackupRequests keep credentials inline, so they are copied as is unless --credentials=prompt is set.`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			source, destination := args[0], args[1]
+
+			stopFn := startSpinner("[1/3] Preparing")
+			if flags.credentials != credentialsCopy && flags.credentials != credentialsPrompt {
+				stopFn()
+				return usageErrorf("invalid --credentials value %q, use %s or %s", flags.credentials, credentialsCopy, credentialsPrompt)
+			}
+			dynClient, err := f.DynamicClient()
+			if err != nil {
+				stopFn()
+				return fmt.Errorf("failed to get client: %w", err)
+			}
+			if err := checkAccess(ctx, f.ForContext(flags.targetContext), permCreateBackups); err != nil {
+				stopFn()
+				return err
+			}
+			stopFn()
+
+			stopFn = startSpinner("[2/3] Getting BackupRequest")
+			item, err := dynClient.Resource(gvr).Get(ctx, source, metav1.GetOptions{})
+			if err != nil {
+				stopFn()
+				return fmt.Errorf("failed to get BackupRequest %s: %w", source, err)
+			}
+			clone := cloneBackupRequest(item, destination)
+			if err := applyCloneOverrides(clone, flags); err != nil {
+				stopFn()
+				return err
+			}
+			stopFn()
+
+			if flags.credentials == credentialsPrompt {
+				if err := promptCloneCredentials(ctx, clone); err != nil {
+					return fmt.Errorf("failed to read credentials: %w", err)
+				}
+			}
+
+			stopFn = startSpinner("[3/3] Creating BackupRequest")
+			target := f.ForContext(flags.targetContext)
+			targetClient, err := target.DynamicClient()
+			if err != nil {
+				stopFn()
+				return fmt.Errorf("failed to get client for context %q: %w", flags.targetContext, err)
+			}
+			_, err = targetClient.Resource(gvr).Create(ctx, clone, metav1.CreateOptions{})
+			if apierrors.IsAlreadyExists(err) {
+				stopFn()
+				return conflictErrorf("BackupRequest %s already exists", destination)
+			}
+			if err != nil {
+				stopFn()
+				return fmt.Errorf("failed to create BackupRequest resource: %w", err)
+			}
+			dbType, _, _ := unstructured.NestedString(clone.Object, "spec", "dbSpec", "dbType")
+			adapterWarning := checkAdapterRegistered(ctx, target, dbType)
+			stopFn()
+
+			if adapterWarning != "" {
+				log.Warn(adapterWarning)
+			}
+			if flags.targetContext != "" {
+				log.Infof("Successfully cloned BackupRequest %s to %s in context %s", source, destination, flags.targetContext)
+				return nil
+			}
+			log.Infof("Successfully cloned BackupRequest %s to %s", source, destination)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&flags.db, "db", "", "Override DB specification in the format dbType@dbUri:dbPort/dbName")
+	cmd.Flags().StringVar(&flags.s3, "s3", "", "Override S3 specification in the format [scheme://]endpoint:port/bucket")
+	cmd.Flags().StringVar(&flags.schedule, "schedule", "", "Override cron schedule for backups")
+	cmd.Flags().StringVar(&flags.namespace, "namespace", "", "Move the database service address to this namespace")
+	cmd.Flags().StringVar(&flags.targetContext, "target-context", "", "Kubeconfig context to create the copy in (default the one of the source)")
+	cmd.Flags().StringVar(&flags.credentials, "credentials", credentialsCopy, "Credentials of the copy: copy (from the source) or prompt")
+	cmd.ValidArgsFunction = completeBackupRequestNames(f, 1)
+	registerFlagCompletion(cmd, "db", completeDBSpec(f))
+	registerFlagCompletion(cmd, "target-context", completeKubeContexts(f))
+	registerFlagCompletion(cmd, "credentials", cobra.FixedCompletions([]string{credentialsCopy, credentialsPrompt}, cobra.ShellCompDirectiveNoFileComp))
+	return cmd
+}
+
+// cloneBackupRequest returns a new BackupRequest named name with spec, labels and annotations of source.
+func cloneBackupRequest(source *unstructured.Unstructured, name string) *unstructured.Unstructured {
+	clone := &unstructured.Unstructured{Object: map[string]any{}}
+	clone.SetAPIVersion(source.GetAPIVersion())
+	clone.SetKind(source.GetKind())
+	clone.SetName(name)
+	clone.SetLabels(source.GetLabels())
+
+	annotations := source.GetAnnotations()
+	for _, key := range clientSideAnnotations {
+		delete(annotations, key)
+	}
+	clone.SetAnnotations(annotations)
+
+	if spec, ok := source.Object["spec"]; ok {
+		clone.Object["spec"] = runtime.DeepCopyJSONValue(spec)
+	}
+	return clone
+}
+
+// applyCloneOverrides sets values given by backup clone flags.
+func applyCloneOverrides(clone *unstructured.Unstructured, flags *backupCloneFlags) error {
+	if flags.db != "" {
+		dbSpec, err := parseDBSpec(flags.db)
+		if err != nil {
+			return err
+		}
+		fields := map[string]any{
+			"dbType": dbSpec.DbType,
+			"uri":    dbSpec.URI,
+			"port":   int64(dbSpec.Port),
+			"dbName": dbSpec.DbName,
+		}
+		for field, value := range fields {
+			if err := unstructured.SetNestedField(clone.Object, value, "spec", "dbSpec", field); err != nil {
+				return err
+			}
+		}
+	}
+
+	if flags.s3 != "" {
+		s3Spec, err := parseS3Spec(flags.s3)
+		if err != nil {
+			return err
+		}
+		if err := unstructured.SetNestedField(clone.Object, s3Spec.Endpoint, "spec", "s3Spec", "endpoint"); err != nil {
+			return err
+		}
+		if err := unstructured.SetNestedField(clone.Object, s3Spec.BucketName, "spec", "s3Spec", "bucketName"); err != nil {
+			return err
+		}
+	}
+
+	if flags.schedule != "" {
+		if err := unstructured.SetNestedField(clone.Object, flags.schedule, "spec", "schedule"); err != nil {
+			return err
+		}
+	}
+
+	if flags.namespace != "" {
+		uri, _, _ := unstructured.NestedString(clone.Object, "spec", "dbSpec", "uri")
+		moved, err := moveToNamespace(uri, flags.namespace)
+		if err != nil {
+			return err
+		}
+		if err := unstructured.SetNestedField(clone.Object, moved, "spec", "dbSpec", "uri"); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// moveToNamespace rewrites a cluster service address to point into namespace.
+func moveToNamespace(host, namespace string) (string, error) {
+	if net.ParseIP(host) != nil {
+		return "", usageErrorf("database address %s is an IP address, set --db instead of --namespace", host)
+	}
+
+	parts := strings.Split(host, ".")
+	switch {
+	case len(parts) == 1:
+		return host + "." + namespace, nil
+	case len(parts) == 2 || parts[2] == "svc":
+		parts[1] = namespace
+		return strings.Join(parts, "."), nil
+	}
+	return "", usageErrorf("database address %s is not a cluster service address, set --db instead of --namespace", host)
+}
+
+// promptCloneCredentials asks for new credentials, keeping copied ones on empty input.
+func promptCloneCredentials(ctx context.Context, clone *unstructured.Unstructured) error {
+	prompts := []struct {
+		prompt string
+		path   []string
+	}{
+		{"Enter DB User (empty to keep)", []string{"spec", "dbSpec", "user"}},
+		{"Enter DB Password (empty to keep)", []string{"spec", "dbSpec", "pass"}},
+		{"Enter S3 Access Key (empty to keep)", []string{"spec", "s3Spec", "auth", "accessKey"}},
+		{"Enter S3 Secret Key (empty to keep)", []string{"spec", "s3Spec", "auth", "secretKey"}},
+	}
+	for _, p := range prompts {
+		value, err := readSecret(ctx, p.prompt)
+		if err != nil {
+			return err
+		}
+		if value == "" {
+			continue
+		}
+		if err := unstructured.SetNestedField(clone.Object, value, p.path...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkAdapterRegistered returns a warning if dbType has no adapter in the cluster of f.
+func checkAdapterRegistered(ctx context.Context, f Factory, dbType string) string {
+	clientset, err := f.ClientSet()
+	if err != nil {
+		return fmt.Sprintf("Could not check adapter %s: %v", dbType, err)
+	}
+	configMap, err := getAdapterConfigMap(ctx, f, clientset)
+	if err != nil {
+		return fmt.Sprintf("Could not check adapter %s: %v", dbType, err)
+	}
+	if _, exists := configMap.Data[dbType]; !exists {
+		return fmt.Sprintf("Adapter %s is not registered in %s/%s, backups will fail until it is added", dbType, configMap.Namespace, configMap.Name)
+	}
+	return ""
+}
@@ -1,11 +1,9 @@
 package cmd
 
 import (
-	"context"
-	"encoding/json"
 	"fmt"
-	"os"
 	"regexp"
+	"slices"
 	"strconv"
 	"strings"
 
@@ -13,320 +11,578 @@ import (
 	"github.com/oiler-backup/cli/internal/k8s"
 	backupv1 "github.com/oiler-backup/core/core/api/v1"
 	"github.com/spf13/cobra"
-	"golang.org/x/term"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 )
 
-// backupCmd is a top-level command for actions with BackupRequest.
-var backupCmd = &cobra.Command{
-	Use:   "backup",
-	Short: "Manage backup resources",
-	Long:  `Manage backup resources in the cluster.`,
+// newBackupCmd returns the top-level command for actions with BackupRequest.
+func newBackupCmd(f Factory) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "backup",
+		Short: "Manage backup resources",
+		Long:  `Manage backup resources in the cluster.`,
+	}
+
+	cmd.AddCommand(newBackupListCmd(f))
+	cmd.AddCommand(newBackupCreateCmd(f))
+	cmd.AddCommand(newBackupDeleteCmd(f))
+	cmd.AddCommand(newBackupUpdateCmd(f))
+	cmd.AddCommand(newBackupWatchCmd(f))
+	cmd.AddCommand(newBackupWaitCmd(f))
+	cmd.AddCommand(newBackupSuspendCmd(f))
+	cmd.AddCommand(newBackupResumeCmd(f))
+	cmd.AddCommand(newBackupLabelCmd(f))
+	cmd.AddCommand(newBackupAnnotateCmd(f))
+	cmd.AddCommand(newBackupCloneCmd(f))
+	return cmd
 }
 
-// backupListCmd lists all BackupRequest instances.
-var backupListCmd = &cobra.Command{
-	Use:   "list",
-	Short: "List all BackupRequest resources",
-	Long:  `List all BackupRequest resources in the cluster.`,
-	Run: func(cmd *cobra.Command, args []string) {
-		stopFn := startSpinner("[1/3] Preparing")
-		dynClient, err := getDynamicClient()
-		if err != nil {
-			stopFn()
-			log.Fatalf("Failed to get client: %v", err)
-		}
-		stopFn()
+// newBackupListCmd returns a command that lists all BackupRequest instances.
+func newBackupListCmd(f Factory) *cobra.Command {
+	flags := &backupListFlags{}
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List all BackupRequest resources",
+		Long:  `List all BackupRequest resources in the cluster.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			if flags.watch {
+				return runBackupWatch(ctx, f, cmd.OutOrStdout(), flags.selector, flags.fieldSelector)
+			}
 
-		stopFn = startSpinner("[2/3] Getting BackupRequests")
-		list, err := dynClient.Resource(gvr).List(context.TODO(), metav1.ListOptions{})
-		if err != nil {
+			stopFn := startSpinner("[1/3] Preparing")
+			dynClient, err := f.DynamicClient()
+			if err != nil {
+				stopFn()
+				return fmt.Errorf("failed to get client: %w", err)
+			}
 			stopFn()
-			log.Fatalf("Failed to list BackupRequest resources: %v", err)
-		}
 
-		var backupRequests []backupv1.BackupRequest
-		for _, item := range list.Items {
-			var backupRequest backupv1.BackupRequest
-			jsonItem, err := item.MarshalJSON()
+			stopFn = startSpinner("[2/3] Getting BackupRequests")
+			backupRequests, err := listBackupRequests(ctx, dynClient, metav1.ListOptions{LabelSelector: flags.selector, FieldSelector: flags.fieldSelector})
 			if err != nil {
 				stopFn()
-				log.Fatalf("Failed to unmarshal object: %v", err)
+				return fmt.Errorf("failed to get BackupRequests: %w", err)
 			}
-			if err := json.Unmarshal(jsonItem, &backupRequest); err != nil {
-				stopFn()
-				log.Fatalf("Failed to unmarshal BackupRequest resource: %v", err)
+			stopFn()
+
+			stopFn = startSpinner("[3/3] Generating results")
+			t := table.NewWriter()
+			t.SetOutputMirror(cmd.OutOrStdout())
+			t.SetStyle(table.StyleLight)
+			t.AppendHeader(table.Row{"#", "BackupRequest Name", "Database URI", "Database Name", "Database Type", "Schedule", "Suspended", "Status"})
+			var overdue []string
+			for i, br := range backupRequests {
+				suspended, expired := suspensionState(br)
+				if expired {
+					overdue = append(overdue, br.Name)
+				}
+				t.AppendRow(table.Row{i + 1, br.Name, br.Spec.DbSpec.URI, br.Spec.DbSpec.DbName, br.Spec.DbSpec.DbType, br.Spec.Schedule, suspended, br.Status.Status})
+				t.AppendSeparator()
 			}
-			backupRequests = append(backupRequests, backupRequest)
-		}
-		stopFn()
-
-		stopFn = startSpinner("[3/3] Generating results")
-		t := table.NewWriter()
-		t.SetOutputMirror(os.Stdout)
-		t.SetStyle(table.StyleLight)
-		t.AppendHeader(table.Row{"#", "BackupRequest Name", "Database URI", "Database Name", "Database Type", "Schedule", "Status"})
-		for i, br := range backupRequests {
-			t.AppendRow(table.Row{i + 1, br.Name, br.Spec.DbSpec.URI, br.Spec.DbSpec.DbName, br.Spec.DbSpec.DbType, br.Spec.Schedule, br.Status.Status})
-			t.AppendSeparator()
-		}
-		t.AppendFooter(table.Row{"", "", "", "", "", "TOTAL", len(backupRequests)})
-		stopFn()
-		t.Render()
-	},
+			t.AppendFooter(table.Row{"", "", "", "", "", "", "TOTAL", len(backupRequests)})
+			stopFn()
+			t.Render()
+
+			if len(overdue) > 0 {
+				log.Warnf("Suspension window has passed for %s, resume with: oiler-cli backup resume %s", strings.Join(overdue, ", "), strings.Join(overdue, " "))
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&flags.selector, "selector", "l", "", "Label selector, e.g. team=payments")
+	cmd.Flags().StringVar(&flags.fieldSelector, "field-selector", "", "Field selector, e.g. metadata.name=my-backup")
+	cmd.Flags().BoolVarP(&flags.watch, "watch", "w", false, "Watch for BackupRequest changes, same as backup watch")
+	return cmd
 }
 
-var (
-	db                string
-	dbUser            string
-	dbPass            string
-	dbUserStdin       bool
-	dbPassStdin       bool
-	s3                string
-	s3AccessKey       string
-	s3SecretKey       string
-	s3AccessKeyStdin  bool
-	s3SecretKeyStdin  bool
-	schedule          string
-	maxBackupCount    int64
-	backupRequestName string
-)
+// backupCreateFlags are the flags of backup create. --template and the wizard fill in those not given explicitly.
+type backupCreateFlags struct {
+	name             string
+	db               string
+	dbUser           string
+	dbPass           string
+	dbUserStdin      bool
+	dbPassStdin      bool
+	s3               string
+	s3AccessKey      string
+	s3SecretKey      string
+	s3AccessKeyStdin bool
+	s3SecretKeyStdin bool
+	schedule         string
+	maxBackupCount   int64
+	labels           []string
+	annotations      []string
+	template         string
+	vars             []string
+	interactive      bool
+}
 
-// backupCreateCmd creates BackupRequest instance.
-var backupCreateCmd = &cobra.Command{
-	Use:   "create",
-	Short: "Create a BackupRequest",
-	Long:  `Create a BackupRequest in the specified namespace.`,
-	Run: func(cmd *cobra.Command, args []string) {
-		stopFn := startSpinner("[1/3] Preparing")
-		dbRegex := regexp.MustCompile(`^(?P<dbType>[^@]+)@(?P<dbUri>[^:]+):(?P<dbPort>\d+)/(?P<dbName>.+)$`)
-		dbMatches := dbRegex.FindStringSubmatch(db)
-		if len(dbMatches) != 5 {
-			stopFn()
-			log.Fatalf("Invalid --db format. Use dbType@dbUri:dbPort/dbName")
-		}
-		dbType := dbMatches[1]
-		dbUri := dbMatches[2]
-		dbPort, err := strconv.Atoi(dbMatches[3])
-		if err != nil {
-			stopFn()
-			log.Fatalf("Port %s is not a valid integer", dbMatches[3])
-		}
-		dbName := dbMatches[4]
+// backupListFlags are the flags of backup list.
+type backupListFlags struct {
+	backupSelectFlags
+	watch bool
+}
 
-		stopFn()
-		var dbUserInput, dbPassInput string
-		if dbUserStdin {
-			fmt.Print("Enter DB User: ")
-			byteUser, err := term.ReadPassword(int(os.Stdin.Fd()))
-			if err != nil {
-				log.Fatalf("Failed to read DB User: %v", err)
+// backupSelectFlags select BackupRequests of commands working on several of them.
+type backupSelectFlags struct {
+	selector      string
+	fieldSelector string
+}
+
+// backupBulkFlags are the flags of backup delete and update.
+type backupBulkFlags struct {
+	backupSelectFlags
+	yes      bool
+	dryRun   string
+	parallel int
+}
+
+// newBackupCreateCmd returns a command that creates BackupRequest instance.
+func newBackupCreateCmd(f Factory) *cobra.Command {
+	flags := &backupCreateFlags{}
+	cmd := &cobra.Command{
+		Use:   "create",
+		Short: "Create a BackupRequest",
+		Long: `Create a BackupRequest in the specified namespace.
+
+With --template, flags not given explicitly are taken from the named template, see oiler-cli template --help.
+With --interactive, or when required flags are missing on a terminal, values are asked for step by step
+and the resulting manifest is shown for confirmation.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			var templateLabels, templateAnnotations map[string]string
+			interactive := wantCreateWizard(flags)
+			if interactive {
+				if err := checkAccess(ctx, f, permCreateBackups); err != nil {
+					return err
+				}
+				var err error
+				templateLabels, templateAnnotations, err = runCreateWizard(f, cmd, flags)
+				if err != nil {
+					return fmt.Errorf("interactive create failed: %w", err)
+				}
+			}
+
+			stopFn := startSpinner("[1/3] Preparing")
+			if flags.template != "" && !interactive {
+				var err error
+				templateLabels, templateAnnotations, err = applyBackupTemplate(f, cmd, flags)
+				if err != nil {
+					stopFn()
+					return fmt.Errorf("failed to apply template: %w", err)
+				}
+			}
+			if flags.name == "" || flags.db == "" || flags.s3 == "" {
+				stopFn()
+				return usageErrorf("--name, --db and --s3 are required unless set by --template, or run with --interactive")
 			}
-			dbUserInput = string(byteUser)
-			fmt.Println()
-		} else {
-			dbUserInput = dbUser
-		}
 
-		if dbPassStdin {
-			fmt.Print("Enter DB Password: ")
-			bytePass, err := term.ReadPassword(int(os.Stdin.Fd()))
+			labels, err := k8s.ParseKeyValues(flags.labels)
+			if err == nil {
+				labels = mergeMaps(templateLabels, labels)
+				err = validateMetadata("labels", labels)
+			}
 			if err != nil {
-				log.Fatalf("Failed to read DB Password: %v", err)
+				stopFn()
+				return usageErrorf("invalid --label: %w", err)
+			}
+			annotations, err := k8s.ParseKeyValues(flags.annotations)
+			if err == nil {
+				annotations = mergeMaps(templateAnnotations, annotations)
+				err = validateMetadata("annotations", annotations)
+			}
+			if err != nil {
+				stopFn()
+				return usageErrorf("invalid --annotation: %w", err)
 			}
-			dbPassInput = string(bytePass)
-			fmt.Println()
-		} else {
-			dbPassInput = dbPass
-		}
-		stopFn = startSpinner("[2/3] Preparing")
-		s3Regex := regexp.MustCompile(`^(?P<endpoint>[^/]+)/(?P<bucketName>.+)$`)
-		s3Matches := s3Regex.FindStringSubmatch(s3)
-		if len(s3Matches) != 3 {
-			log.Fatalf("Invalid --s3 format. Use endpoint/bucket")
-		}
-		s3Endpoint := s3Matches[1]
-		s3BucketName := s3Matches[2]
-
-		// Разделяем endpoint на протокол и адрес
-		endpointParts := strings.SplitN(s3Endpoint, "://", 2)
-		var protocol, address string
-		if len(endpointParts) == 2 {
-			protocol = endpointParts[0]
-			address = endpointParts[1]
-		} else {
-			protocol = ""
-			address = s3Endpoint
-		}
 
-		stopFn()
-		var s3AccessKeyInput, s3SecretKeyInput string
-		if s3AccessKeyStdin {
-			fmt.Print("Enter S3 Access Key: ")
-			byteAccessKey, err := term.ReadPassword(int(os.Stdin.Fd()))
+			dbSpec, err := parseDBSpec(flags.db)
 			if err != nil {
-				log.Fatalf("Failed to read S3 Access Key: %v", err)
+				stopFn()
+				return err
 			}
-			s3AccessKeyInput = string(byteAccessKey)
-			fmt.Println()
-		} else {
-			s3AccessKeyInput = s3AccessKey
-		}
 
-		if s3SecretKeyStdin {
-			fmt.Print("Enter S3 Secret Key: ")
-			byteSecretKey, err := term.ReadPassword(int(os.Stdin.Fd()))
+			stopFn()
+			dbUserInput, dbPassInput := flags.dbUser, flags.dbPass
+			if flags.dbUserStdin {
+				if dbUserInput, err = readSecret(ctx, "Enter DB User"); err != nil {
+					return fmt.Errorf("failed to read DB User: %w", err)
+				}
+			}
+			if flags.dbPassStdin {
+				if dbPassInput, err = readSecret(ctx, "Enter DB Password"); err != nil {
+					return fmt.Errorf("failed to read DB Password: %w", err)
+				}
+			}
+			stopFn = startSpinner("[2/3] Preparing")
+			s3Spec, err := parseS3Spec(flags.s3)
 			if err != nil {
-				log.Fatalf("Failed to read S3 Secret Key: %v", err)
+				stopFn()
+				return err
 			}
-			s3SecretKeyInput = string(byteSecretKey)
-			fmt.Println()
-		} else {
-			s3SecretKeyInput = s3SecretKey
-		}
-		stopFn = startSpinner("[3/3] Creating BackupRequest")
-		backupRequest := backupv1.BackupRequest{
-			TypeMeta: metav1.TypeMeta{
-				APIVersion: "backup.oiler.backup/v1",
-				Kind:       "BackupRequest",
-			},
-			ObjectMeta: metav1.ObjectMeta{
-				Name: backupRequestName,
-			},
-			Spec: backupv1.BackupRequestSpec{
-				DbSpec: backupv1.DatabaseSpec{
-					DbType: dbType,
-					URI:    dbUri,
-					Port:   dbPort,
-					User:   dbUserInput,
-					Pass:   dbPassInput,
-					DbName: dbName,
+
+			stopFn()
+			s3AccessKeyInput, s3SecretKeyInput := flags.s3AccessKey, flags.s3SecretKey
+			if flags.s3AccessKeyStdin {
+				if s3AccessKeyInput, err = readSecret(ctx, "Enter S3 Access Key"); err != nil {
+					return fmt.Errorf("failed to read S3 Access Key: %w", err)
+				}
+			}
+			if flags.s3SecretKeyStdin {
+				if s3SecretKeyInput, err = readSecret(ctx, "Enter S3 Secret Key"); err != nil {
+					return fmt.Errorf("failed to read S3 Secret Key: %w", err)
+				}
+			}
+
+			backupRequest := backupv1.BackupRequest{
+				TypeMeta: metav1.TypeMeta{
+					APIVersion: "backup.oiler.backup/v1",
+					Kind:       "BackupRequest",
+				},
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        flags.name,
+					Labels:      labels,
+					Annotations: annotations,
 				},
-				S3Spec: backupv1.S3Spec{
-					Endpoint:   fmt.Sprintf("%s://%s", protocol, address),
-					BucketName: s3BucketName,
-					Auth: backupv1.S3Auth{
-						AccessKey: s3AccessKeyInput,
-						SecretKey: s3SecretKeyInput,
+				Spec: backupv1.BackupRequestSpec{
+					DbSpec: backupv1.DatabaseSpec{
+						DbType: dbSpec.DbType,
+						URI:    dbSpec.URI,
+						Port:   dbSpec.Port,
+						User:   dbUserInput,
+						Pass:   dbPassInput,
+						DbName: dbSpec.DbName,
 					},
+					S3Spec: backupv1.S3Spec{
+						Endpoint:   s3Spec.Endpoint,
+						BucketName: s3Spec.BucketName,
+						Auth: backupv1.S3Auth{
+							AccessKey: s3AccessKeyInput,
+							SecretKey: s3SecretKeyInput,
+						},
+					},
+					Schedule:       flags.schedule,
+					MaxBackupCount: flags.maxBackupCount,
 				},
-				Schedule:       schedule,
-				MaxBackupCount: maxBackupCount,
-			},
-		}
+			}
 
-		dynClient, err := getDynamicClient()
-		if err != nil {
-			stopFn()
-			log.Fatalf("Failed to get client: %v", err)
-		}
-		unstructuredBackupRequest, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&backupRequest)
-		if err != nil {
-			stopFn()
-			log.Fatalf("Failed to convert BackupRequest to unstructured: %v", err)
-		}
+			if interactive {
+				if err := printManifest(cmd.ErrOrStderr(), backupRequest); err != nil {
+					return fmt.Errorf("failed to show BackupRequest: %w", err)
+				}
+				if !confirm(ctx, "Create this BackupRequest?") {
+					log.Info("Create cancelled")
+					return nil
+				}
+			}
+
+			stopFn = startSpinner("[3/3] Creating BackupRequest")
+			dynClient, err := f.DynamicClient()
+			if err != nil {
+				stopFn()
+				return fmt.Errorf("failed to get client: %w", err)
+			}
+			unstructuredBackupRequest, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&backupRequest)
+			if err != nil {
+				stopFn()
+				return fmt.Errorf("failed to convert BackupRequest to unstructured: %w", err)
+			}
 
-		_, err = dynClient.Resource(gvr).Create(context.TODO(), &unstructured.Unstructured{Object: unstructuredBackupRequest}, metav1.CreateOptions{})
-		if err != nil {
+			_, err = dynClient.Resource(gvr).Create(ctx, &unstructured.Unstructured{Object: unstructuredBackupRequest}, metav1.CreateOptions{})
+			if err != nil {
+				stopFn()
+				return fmt.Errorf("failed to create BackupRequest resource: %w", err)
+			}
 			stopFn()
-			log.Fatalf("Failed to create BackupRequest resource: %v", err)
-		}
-		stopFn()
-		log.Infof("Successfully created BackupRequest %s", backupRequestName)
-	},
+			log.Infof("Successfully created BackupRequest %s", flags.name)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&flags.db, "db", "", "DB specification in the format dbType@dbUri:dbPort/dbName")
+	cmd.Flags().StringVar(&flags.dbUser, "db-user", "", "DB user")
+	cmd.Flags().StringVar(&flags.dbPass, "db-pass", "", "DB password")
+	cmd.Flags().BoolVar(&flags.dbUserStdin, "db-user-stdin", false, "Prompt for DB user from stdin")
+	cmd.Flags().BoolVar(&flags.dbPassStdin, "db-pass-stdin", false, "Prompt for DB password from stdin")
+	cmd.Flags().StringVar(&flags.s3, "s3", "", "S3 specification in the format [scheme://]endpoint:port/bucket")
+	cmd.Flags().StringVar(&flags.s3AccessKey, "s3-access-key", "", "S3 access key")
+	cmd.Flags().StringVar(&flags.s3SecretKey, "s3-secret-key", "", "S3 secret key")
+	cmd.Flags().BoolVar(&flags.s3AccessKeyStdin, "s3-access-key-stdin", false, "Prompt for S3 access key from stdin")
+	cmd.Flags().BoolVar(&flags.s3SecretKeyStdin, "s3-secret-key-stdin", false, "Prompt for S3 secret key from stdin")
+	cmd.Flags().StringVar(&flags.schedule, "schedule", "*/1 * * * *", "Cron schedule for backups")
+	cmd.Flags().Int64Var(&flags.maxBackupCount, "max-backup-count", 2, "Maximum number of backups to retain")
+	cmd.Flags().StringVar(&flags.name, "name", "", "Name of the BackupRequest")
+	cmd.Flags().StringArrayVar(&flags.labels, "label", nil, "Label to set on the BackupRequest as <key>=<value>, can be repeated")
+	cmd.Flags().StringArrayVar(&flags.annotations, "annotation", nil, "Annotation to set on the BackupRequest as <key>=<value>, can be repeated")
+	cmd.Flags().StringVar(&flags.template, "template", "", "Template to take flags not given explicitly from")
+	cmd.Flags().StringArrayVar(&flags.vars, "var", nil, "Template variable as <key>=<value>, can be repeated")
+	cmd.Flags().BoolVarP(&flags.interactive, "interactive", "i", false, "Ask for values step by step (default when required flags are missing on a terminal)")
+	registerFlagCompletion(cmd, "db", completeDBSpec(f))
+	registerFlagCompletion(cmd, "template", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return completeTemplateNames(f)(cmd, nil, toComplete)
+	})
+	return cmd
 }
 
-// backupDeleteCmd deletes BackupRequest.
-var backupDeleteCmd = &cobra.Command{
-	Use:   "delete <name>",
-	Short: "Delete a BackupRequest",
-	Long:  `Delete a BackupRequest in the specified namespace.`,
-	Args:  cobra.ExactArgs(1),
-	Run: func(cmd *cobra.Command, args []string) {
-		stopFn := startSpinner("[1/2] Preparing")
-		name := args[0]
-
-		dynClient, err := getDynamicClient()
-		if err != nil {
+// newBackupDeleteCmd returns a command that deletes BackupRequests.
+func newBackupDeleteCmd(f Factory) *cobra.Command {
+	flags := &backupBulkFlags{}
+	cmd := &cobra.Command{
+		Use:   "delete [name...]",
+		Short: "Delete BackupRequests",
+		Long: `Delete BackupRequests given by names, or all BackupRequests matching --selector and --field-selector.
+
+Affected BackupRequests are listed and a confirmation is asked unless --yes is set.
+--dry-run=client only lists them, --dry-run=server also sends dry-run requests to the API server.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			stopFn := startSpinner("[1/3] Preparing")
+			dryRun, err := parseDryRun(flags.dryRun)
+			if err != nil {
+				stopFn()
+				return err
+			}
+			dynClient, err := f.DynamicClient()
+			if err != nil {
+				stopFn()
+				return fmt.Errorf("failed to get client: %w", err)
+			}
+			if flags.dryRun != dryRunClient {
+				if err := checkAccess(ctx, f, permDeleteBackups); err != nil {
+					stopFn()
+					return err
+				}
+			}
 			stopFn()
-			log.Fatalf("Failed to get client: %v", err)
-		}
-		stopFn()
 
-		stopFn = startSpinner("[2/2] Deleting BackupRequest")
-		err = dynClient.Resource(gvr).Delete(context.TODO(), name, metav1.DeleteOptions{})
-		if err != nil {
+			stopFn = startSpinner("[2/3] Getting BackupRequests")
+			backupRequests, err := selectBackupRequestObjects(ctx, dynClient, args, flags.selector, flags.fieldSelector)
+			if err != nil {
+				stopFn()
+				return fmt.Errorf("failed to get BackupRequests: %w", err)
+			}
 			stopFn()
-			log.Fatalf("Failed to delete BackupRequest resource: %v", err)
-		}
 
-		stopFn()
-		log.Infof("Successfully deleted BackupRequest %s", backupRequestName)
-	},
+			if len(backupRequests) == 0 {
+				log.Info("No BackupRequests matched")
+				return nil
+			}
+			renderAffected(cmd.OutOrStdout(), backupRequests)
+			if flags.dryRun == dryRunClient {
+				return nil
+			}
+			if dryRun == nil && !flags.yes && !confirm(ctx, fmt.Sprintf("Delete %d BackupRequest(s)?", len(backupRequests))) {
+				log.Info("Delete cancelled")
+				return nil
+			}
+
+			results := runBulk("[3/3] Deleting BackupRequests", backupRequests, flags.parallel, func(br *unstructured.Unstructured) error {
+				return dynClient.Resource(gvr).Delete(ctx, br.GetName(), metav1.DeleteOptions{DryRun: dryRun})
+			})
+
+			done := "deleted"
+			if dryRun != nil {
+				done = "deleted (dry run)"
+			}
+			if failed := renderBulkResults(cmd.OutOrStdout(), results, done); failed > 0 {
+				return fmt.Errorf("failed to delete %d of %d BackupRequest(s)", failed, len(results))
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&flags.selector, "selector", "l", "", "Label selector of BackupRequests to delete")
+	cmd.Flags().StringVar(&flags.fieldSelector, "field-selector", "", "Field selector of BackupRequests to delete")
+	cmd.Flags().BoolVarP(&flags.yes, "yes", "y", false, "Delete without confirmation")
+	cmd.Flags().StringVar(&flags.dryRun, "dry-run", dryRunNone, "none, client (only list affected BackupRequests) or server (send dry-run requests)")
+	cmd.Flags().Lookup("dry-run").NoOptDefVal = dryRunClient
+	cmd.Flags().IntVar(&flags.parallel, "parallel", 4, "Number of concurrent API calls")
+	cmd.ValidArgsFunction = completeBackupRequestNames(f, -1)
+	registerFlagCompletion(cmd, "dry-run", cobra.FixedCompletions([]string{dryRunNone, dryRunClient, dryRunServer}, cobra.ShellCompDirectiveNoFileComp))
+	return cmd
 }
 
-// backupUpdateCmd updates existing BackupRequest.
-var backupUpdateCmd = &cobra.Command{
-	Use:   "update <name> <field>=<value>",
-	Short: "Update a field in a BackupRequest",
-	Long:  `Update a field in a BackupRequest in the specified namespace.`,
-	Args:  cobra.ExactArgs(2),
-	Run: func(cmd *cobra.Command, args []string) {
-		stopFn := startSpinner("[1/3] Preparing")
-		name := args[0]
-		fieldValue := args[1]
-
-		parts := strings.SplitN(fieldValue, "=", 2)
-		if len(parts) != 2 {
-			stopFn()
-			log.Fatalf("Invalid argument format. Use <field>=<value>")
-		}
+// integerUpdateFields are BackupRequest fields backup update sets as integers, the others are strings.
+var integerUpdateFields = []string{"spec.maxBackupCount", "spec.dbSpec.port"}
+
+// newBackupUpdateCmd returns a command that updates existing BackupRequests.
+func newBackupUpdateCmd(f Factory) *cobra.Command {
+	flags := &backupBulkFlags{}
+	cmd := &cobra.Command{
+		Use:   "update [name...] <field>=<value>",
+		Short: "Update a field in BackupRequests",
+		Long: `Update a field in BackupRequests given by names, or in all BackupRequests matching --selector and --field-selector.
+
+Values of spec.maxBackupCount and spec.dbSpec.port must be integers, other fields are set as strings.
+Affected BackupRequests are listed and a confirmation is asked unless --yes is set.
+--dry-run=client only lists them, --dry-run=server also sends dry-run requests to the API server.`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			stopFn := startSpinner("[1/3] Preparing")
+			names := args[:len(args)-1]
+			fieldValue := args[len(args)-1]
+
+			parts := strings.SplitN(fieldValue, "=", 2)
+			if len(parts) != 2 {
+				stopFn()
+				return usageErrorf("invalid argument format, use <field>=<value>")
+			}
 
-		field := parts[0]
-		value := parts[1]
+			field := parts[0]
+			value := parts[1]
 
-		dynClient, err := getDynamicClient()
-		if err != nil {
-			stopFn()
-			log.Fatalf("Failed to get client: %v", err)
-		}
-		stopFn()
+			fieldParts := strings.Split(field, ".")
+			if len(fieldParts) == 0 {
+				stopFn()
+				return usageErrorf("invalid field format, use <field>=<value>")
+			}
+			var newValue any = value
+			if slices.Contains(integerUpdateFields, field) {
+				n, err := strconv.ParseInt(value, 10, 64)
+				if err != nil {
+					stopFn()
+					return usageErrorf("invalid value of %s %q, it must be an integer", field, value)
+				}
+				newValue = n
+			}
 
-		stopFn = startSpinner("[2/3] Getting BackupRequest")
-		backupRequest, err := dynClient.Resource(gvr).Get(context.TODO(), name, metav1.GetOptions{})
-		if err != nil {
+			dryRun, err := parseDryRun(flags.dryRun)
+			if err != nil {
+				stopFn()
+				return err
+			}
+			dynClient, err := f.DynamicClient()
+			if err != nil {
+				stopFn()
+				return fmt.Errorf("failed to get client: %w", err)
+			}
+			if flags.dryRun != dryRunClient {
+				if err := checkAccess(ctx, f, permUpdateBackups); err != nil {
+					stopFn()
+					return err
+				}
+			}
 			stopFn()
-			log.Fatalf("Failed to get BackupRequest resource: %v", err)
-		}
-
-		unstructuredBackupRequest := backupRequest.UnstructuredContent()
 
-		fieldParts := strings.Split(field, ".")
-		if len(fieldParts) == 0 {
+			stopFn = startSpinner("[2/3] Getting BackupRequests")
+			backupRequests, err := selectBackupRequestObjects(ctx, dynClient, names, flags.selector, flags.fieldSelector)
+			if err != nil {
+				stopFn()
+				return fmt.Errorf("failed to get BackupRequests: %w", err)
+			}
 			stopFn()
-			log.Fatalf("Invalid field format. Use <field>=<value>")
-		}
 
-		stopFn()
+			if len(backupRequests) == 0 {
+				log.Info("No BackupRequests matched")
+				return nil
+			}
+			renderAffected(cmd.OutOrStdout(), backupRequests)
+			if flags.dryRun == dryRunClient {
+				return nil
+			}
+			if dryRun == nil && !flags.yes && !confirm(ctx, fmt.Sprintf("Set %s=%s in %d BackupRequest(s)?", field, value, len(backupRequests))) {
+				log.Info("Update cancelled")
+				return nil
+			}
 
-		stopFn = startSpinner("[3/3] Updating BackupRequest")
-		err = k8s.UpdateField(unstructuredBackupRequest, fieldParts, value)
-		if err != nil {
-			stopFn()
-			log.Fatalf("Failed to update field: %v", err)
-		}
+			results := runBulk("[3/3] Updating BackupRequests", backupRequests, flags.parallel, func(br *unstructured.Unstructured) error {
+				if err := k8s.UpdateField(br.UnstructuredContent(), fieldParts, newValue); err != nil {
+					return fmt.Errorf("failed to update field: %w", err)
+				}
+				_, err := dynClient.Resource(gvr).Update(ctx, br, metav1.UpdateOptions{DryRun: dryRun})
+				return err
+			})
+
+			done := "updated"
+			if dryRun != nil {
+				done = "updated (dry run)"
+			}
+			failed := renderBulkResults(cmd.OutOrStdout(), results, done)
+			if dryRun == nil {
+				var updated []unstructured.Unstructured
+				for i, res := range results {
+					if res.Err == nil {
+						updated = append(updated, backupRequests[i])
+					}
+				}
+				if err := reapplySuspension(ctx, f, dynClient, updated); err != nil {
+					log.Warnf("%v; run backup suspend on them again", err)
+				}
+			}
+			if failed > 0 {
+				return fmt.Errorf("failed to update %d of %d BackupRequest(s)", failed, len(results))
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&flags.selector, "selector", "l", "", "Label selector of BackupRequests to update")
+	cmd.Flags().StringVar(&flags.fieldSelector, "field-selector", "", "Field selector of BackupRequests to update")
+	cmd.Flags().BoolVarP(&flags.yes, "yes", "y", false, "Update without confirmation")
+	cmd.Flags().StringVar(&flags.dryRun, "dry-run", dryRunNone, "none, client (only list affected BackupRequests) or server (send dry-run requests)")
+	cmd.Flags().Lookup("dry-run").NoOptDefVal = dryRunClient
+	cmd.Flags().IntVar(&flags.parallel, "parallel", 4, "Number of concurrent API calls")
+	cmd.ValidArgsFunction = completeBackupUpdate(f)
+	registerFlagCompletion(cmd, "dry-run", cobra.FixedCompletions([]string{dryRunNone, dryRunClient, dryRunServer}, cobra.ShellCompDirectiveNoFileComp))
+	return cmd
+}
+
+// parseDBSpec parses --db in the format dbType@dbUri:dbPort/dbName.
+func parseDBSpec(db string) (backupv1.DatabaseSpec, error) {
+	dbRegex := regexp.MustCompile(`^(?P<dbType>[^@]+)@(?P<dbUri>[^:]+):(?P<dbPort>\d+)/(?P<dbName>.+)$`)
+	dbMatches := dbRegex.FindStringSubmatch(db)
+	if len(dbMatches) != 5 {
+		return backupv1.DatabaseSpec{}, usageErrorf("invalid --db format, use dbType@dbUri:dbPort/dbName")
+	}
+	dbPort, err := strconv.Atoi(dbMatches[3])
+	if err != nil {
+		return backupv1.DatabaseSpec{}, usageErrorf("port %s is not a valid integer", dbMatches[3])
+	}
+
+	return backupv1.DatabaseSpec{
+		DbType: dbMatches[1],
+		URI:    dbMatches[2],
+		Port:   dbPort,
+		DbName: dbMatches[4],
+	}, nil
+}
 
-		updatedBackupRequest := &unstructured.Unstructured{Object: unstructuredBackupRequest}
+// s3Regex splits --s3 into the endpoint, with an optional scheme, and the bucket.
+var s3Regex = regexp.MustCompile(`^(?P<endpoint>(?:[a-zA-Z][a-zA-Z0-9+.-]*://)?[^/]+)/(?P<bucketName>.+)$`)
+
+// parseS3Spec parses --s3 in the format [scheme://]host[:port]/bucket.
+func parseS3Spec(s3 string) (backupv1.S3Spec, error) {
+	s3Matches := s3Regex.FindStringSubmatch(s3)
+	if len(s3Matches) != 3 {
+		return backupv1.S3Spec{}, usageErrorf("invalid --s3 format, use [scheme://]endpoint/bucket")
+	}
+	endpoint, err := parseS3Endpoint(s3Matches[1])
+	if err != nil {
+		return backupv1.S3Spec{}, usageErrorf("invalid --s3 endpoint: %w", err)
+	}
+
+	return backupv1.S3Spec{
+		Endpoint:   endpoint,
+		BucketName: s3Matches[2],
+	}, nil
+}
 
-		_, err = dynClient.Resource(gvr).Update(context.TODO(), updatedBackupRequest, metav1.UpdateOptions{})
-		if err != nil {
-			stopFn()
-			log.Fatalf("Failed to update BackupRequest resource: %v", err)
+// parseS3Endpoint checks an S3 endpoint given as [http(s)://]host[:port] and returns it as given.
+func parseS3Endpoint(endpoint string) (string, error) {
+	address := endpoint
+	if scheme, rest, ok := strings.Cut(endpoint, "://"); ok {
+		if scheme != "http" && scheme != "https" {
+			return "", fmt.Errorf("unsupported scheme %q, use http or https", scheme)
 		}
-
-		stopFn()
-		log.Infof("Successfully updated BackupRequest %s", backupRequestName)
-	},
+		address = rest
+	}
+	host, port, hasPort := strings.Cut(address, ":")
+	if validateHost(host) != nil || (hasPort && validatePort(port) != nil) {
+		return "", fmt.Errorf("enter the endpoint as [http(s)://]host[:port]")
+	}
+	return endpoint, nil
 }
@@ -0,0 +1,504 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/oiler-backup/cli/internal/cron"
+	backupv1 "github.com/oiler-backup/core/core/api/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// testBackupRequests returns BackupRequests most backup tests start from.
+func testBackupRequests() []backupv1.BackupRequest {
+	return []backupv1.BackupRequest{
+		newBackupRequest("billing", "postgres", "0 2 * * *", "Success", map[string]string{"team": "payments"}),
+		newBackupRequest("orders", "mysql", "*/30 * * * *", "Failed", map[string]string{"team": "payments"}),
+		newBackupRequest("sessions", "postgres", "0 * * * *", "", map[string]string{"team": "web"}),
+	}
+}
+
+func TestBackupList(t *testing.T) {
+	tests := []struct {
+		name   string
+		args   []string
+		golden string
+	}{
+		{name: "all", args: []string{"backup", "list"}, golden: "backup_list"},
+		{name: "selector", args: []string{"backup", "list", "-l", "team=payments"}, golden: "backup_list_selector"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newFakeFactory(t, nil, testBackupRequests()...)
+			out, err := runCmd(t, f, tt.args...)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			assertGolden(t, tt.golden, out)
+		})
+	}
+}
+
+func TestBackupListEmpty(t *testing.T) {
+	f := newFakeFactory(t, nil)
+	out, err := runCmd(t, f, "backup", "list")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	assertGolden(t, "backup_list_empty", out)
+}
+
+func TestBackupWatchJSON(t *testing.T) {
+	f := newFakeFactory(t, nil, testBackupRequests()...)
+	ctx, cancel := context.WithTimeout(t.Context(), time.Second)
+	defer cancel()
+	out, _ := runCmdContext(t, ctx, f, "backup", "watch")
+
+	if strings.Contains(out, "\033") {
+		t.Errorf("output is not a terminal but has escape sequences: %q", out)
+	}
+	var names []string
+	for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+		var event watchEvent
+		if err := json.Unmarshal([]byte(line), &event); err != nil {
+			t.Fatalf("line %q is not a JSON event: %v", line, err)
+		}
+		if event.Type != "ADDED" {
+			t.Errorf("event %s has type %s, want ADDED", event.Name, event.Type)
+		}
+		names = append(names, event.Name)
+	}
+	slices.Sort(names)
+	if got := strings.Join(names, " "); got != "billing orders sessions" {
+		t.Errorf("events for %s, want billing orders sessions", got)
+	}
+}
+
+func TestBackupCreate(t *testing.T) {
+	f := newFakeFactory(t, nil)
+	_, err := runCmd(t, f, "backup", "create",
+		"--name", "inventory",
+		"--db", "postgres@inventory.db:5432/inventory",
+		"--db-user", "backup", "--db-pass", "secret",
+		"--s3", "https://s3.example.com/backups",
+		"--s3-access-key", "AK", "--s3-secret-key", "SK",
+		"--schedule", "0 4 * * *",
+		"--max-backup-count", "7",
+		"--label", "team=logistics",
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	br := f.backupRequest(t, "inventory")
+	want := backupv1.BackupRequestSpec{
+		DbSpec: backupv1.DatabaseSpec{
+			DbType: "postgres",
+			URI:    "inventory.db",
+			Port:   5432,
+			User:   "backup",
+			Pass:   "secret",
+			DbName: "inventory",
+		},
+		S3Spec: backupv1.S3Spec{
+			Endpoint:   "https://s3.example.com",
+			BucketName: "backups",
+			Auth:       backupv1.S3Auth{AccessKey: "AK", SecretKey: "SK"},
+		},
+		Schedule:       "0 4 * * *",
+		MaxBackupCount: 7,
+	}
+	if br.Spec != want {
+		t.Errorf("spec = %+v, want %+v", br.Spec, want)
+	}
+	if br.Labels["team"] != "logistics" {
+		t.Errorf("labels = %v, want team=logistics", br.Labels)
+	}
+}
+
+func TestBackupCreateErrors(t *testing.T) {
+	base := []string{"backup", "create", "--name", "billing", "--s3", "http://minio:9000/backups"}
+	tests := []struct {
+		name string
+		args []string
+		code int
+	}{
+		{name: "missing db", args: base, code: exitUsage},
+		{name: "invalid db", args: append(base, "--db", "postgres"), code: exitUsage},
+		{name: "invalid port", args: append(base, "--db", "postgres@db:99999999999999999999/x"), code: exitUsage},
+		{name: "invalid label", args: append(base, "--db", "postgres@db:5432/x", "--label", "team"), code: exitUsage},
+		{name: "already exists", args: append(base, "--db", "postgres@db:5432/x"), code: exitConflict},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newFakeFactory(t, nil, testBackupRequests()...)
+			_, err := runCmd(t, f, tt.args...)
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+			assertExitCode(t, err, tt.code)
+		})
+	}
+}
+
+func TestBackupUpdate(t *testing.T) {
+	f := newFakeFactory(t, nil, testBackupRequests()...)
+	out, err := runCmd(t, f, "backup", "update", "billing", "orders", "spec.schedule=0 3 * * *", "--yes")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	assertGolden(t, "backup_update", out)
+
+	for _, name := range []string{"billing", "orders"} {
+		if got := f.backupRequest(t, name).Spec.Schedule; got != "0 3 * * *" {
+			t.Errorf("schedule of %s = %q, want %q", name, got, "0 3 * * *")
+		}
+	}
+	if got := f.backupRequest(t, "sessions").Spec.Schedule; got != "0 * * * *" {
+		t.Errorf("schedule of sessions changed to %q", got)
+	}
+}
+
+func TestBackupUpdateInteger(t *testing.T) {
+	f := newFakeFactory(t, nil, testBackupRequests()...)
+	if _, err := runCmd(t, f, "backup", "update", "billing", "spec.maxBackupCount=9", "--yes"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	item, err := f.dynClient.Resource(gvr).Get(t.Context(), "billing", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, _, _ := unstructured.NestedFieldNoCopy(item.Object, "spec", "maxBackupCount"); got != int64(9) {
+		t.Errorf("maxBackupCount = %#v, want int64 9", got)
+	}
+}
+
+func TestBackupUpdateSelector(t *testing.T) {
+	f := newFakeFactory(t, nil, testBackupRequests()...)
+	_, err := runCmd(t, f, "backup", "update", "-l", "team=web", "spec.schedule=0 5 * * *", "--yes")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := f.backupRequest(t, "sessions").Spec.Schedule; got != "0 5 * * *" {
+		t.Errorf("schedule of sessions = %q, want %q", got, "0 5 * * *")
+	}
+	if got := f.backupRequest(t, "billing").Spec.Schedule; got != "0 2 * * *" {
+		t.Errorf("schedule of billing changed to %q", got)
+	}
+}
+
+func TestBackupUpdateDryRunClient(t *testing.T) {
+	f := newFakeFactory(t, nil, testBackupRequests()...)
+	out, err := runCmd(t, f, "backup", "update", "billing", "spec.schedule=0 3 * * *", "--dry-run")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	assertGolden(t, "backup_update_dry_run", out)
+	if got := f.backupRequest(t, "billing").Spec.Schedule; got != "0 2 * * *" {
+		t.Errorf("dry run changed schedule to %q", got)
+	}
+}
+
+func TestBackupUpdateErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		code int
+	}{
+		{name: "no field", args: []string{"backup", "update", "billing"}, code: exitUsage},
+		{name: "no names", args: []string{"backup", "update", "spec.schedule=x", "--yes"}, code: exitUsage},
+		{name: "names and selector", args: []string{"backup", "update", "billing", "-l", "team=web", "spec.schedule=x", "--yes"}, code: exitUsage},
+		{name: "not found", args: []string{"backup", "update", "missing", "spec.schedule=x", "--yes"}, code: exitNotFound},
+		{name: "invalid dry run", args: []string{"backup", "update", "billing", "spec.schedule=x", "--dry-run=maybe"}, code: exitUsage},
+		{name: "not an integer", args: []string{"backup", "update", "billing", "spec.dbSpec.port=abc", "--yes"}, code: exitUsage},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newFakeFactory(t, nil, testBackupRequests()...)
+			_, err := runCmd(t, f, tt.args...)
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+			assertExitCode(t, err, tt.code)
+		})
+	}
+}
+
+func TestBackupDelete(t *testing.T) {
+	f := newFakeFactory(t, nil, testBackupRequests()...)
+	out, err := runCmd(t, f, "backup", "delete", "-l", "team=payments", "--yes")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	assertGolden(t, "backup_delete", out)
+
+	for _, name := range []string{"billing", "orders"} {
+		_, err := f.dynClient.Resource(gvr).Get(t.Context(), name, metav1.GetOptions{})
+		if !apierrors.IsNotFound(err) {
+			t.Errorf("BackupRequest %s still exists (err: %v)", name, err)
+		}
+	}
+	f.backupRequest(t, "sessions")
+}
+
+func TestBackupDeleteWithoutConfirmation(t *testing.T) {
+	f := newFakeFactory(t, nil, testBackupRequests()...)
+	if _, err := runCmd(t, f, "backup", "delete", "billing"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	f.backupRequest(t, "billing")
+}
+
+func TestBackupDeleteNotFound(t *testing.T) {
+	f := newFakeFactory(t, nil, testBackupRequests()...)
+	_, err := runCmd(t, f, "backup", "delete", "missing", "--yes")
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	assertExitCode(t, err, exitNotFound)
+}
+
+func TestBackupWaitInterrupted(t *testing.T) {
+	f := newFakeFactory(t, nil, testBackupRequests()...)
+	ctx, cancel := context.WithCancel(t.Context())
+	cancel()
+	_, err := runCmdContext(t, ctx, f, "backup", "wait", "sessions", "--for", "status=Success")
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	assertExitCode(t, err, exitInterrupted)
+}
+
+func TestBackupWaitInvalidCondition(t *testing.T) {
+	for _, condition := range []string{"status=", "done"} {
+		t.Run(condition, func(t *testing.T) {
+			f := newFakeFactory(t, nil, testBackupRequests()...)
+			_, err := runCmd(t, f, "backup", "wait", "sessions", "--for", condition, "--timeout", "1s")
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+			assertExitCode(t, err, exitUsage)
+		})
+	}
+}
+
+// installBackupRequestCRD adds the BackupRequest CRD declaring specFields to the fake cluster of f.
+func installBackupRequestCRD(t *testing.T, f *fakeFactory, specFields ...string) {
+	t.Helper()
+	properties := map[string]any{}
+	for _, field := range specFields {
+		properties[field] = map[string]any{"type": "boolean"}
+	}
+	crd := &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": "apiextensions.k8s.io/v1",
+		"kind":       "CustomResourceDefinition",
+		"metadata":   map[string]any{"name": backupRequestCRD},
+		"spec": map[string]any{
+			"group": gvr.Group,
+			"versions": []any{map[string]any{
+				"name": gvr.Version,
+				"schema": map[string]any{"openAPIV3Schema": map[string]any{"properties": map[string]any{
+					"spec": map[string]any{"properties": properties},
+				}}},
+			}},
+		},
+	}}
+	if _, err := f.dynClient.Resource(crdGVR).Create(t.Context(), crd, metav1.CreateOptions{}); err != nil {
+		t.Fatalf("failed to create CRD: %v", err)
+	}
+}
+
+func TestBackupSuspend(t *testing.T) {
+	f := newFakeFactory(t, nil, testBackupRequests()...)
+	installBackupRequestCRD(t, f, "suspend")
+	if _, err := runCmd(t, f, "backup", "suspend", "billing", "--until", "1h"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	item, err := f.dynClient.Resource(gvr).Get(t.Context(), "billing", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if suspended, _, _ := unstructured.NestedBool(item.Object, "spec", "suspend"); !suspended {
+		t.Error("spec.suspend is not set")
+	}
+	if state, _ := suspensionState(f.backupRequest(t, "billing")); !strings.HasPrefix(state, "until ") {
+		t.Errorf("suspension state = %q, want it suspended until a time", state)
+	}
+
+	if _, err := runCmd(t, f, "backup", "resume", "billing"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	item, err = f.dynClient.Resource(gvr).Get(t.Context(), "billing", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if suspended, _, _ := unstructured.NestedBool(item.Object, "spec", "suspend"); suspended {
+		t.Error("spec.suspend is still set after resume")
+	}
+	if state, _ := suspensionState(f.backupRequest(t, "billing")); state != "no" {
+		t.Errorf("suspension state = %q after resume, want no", state)
+	}
+}
+
+// cronJobSuspended reports whether the CronJob name in the fake cluster is suspended.
+func cronJobSuspended(t *testing.T, f *fakeFactory, name string) bool {
+	t.Helper()
+	cronJob, err := f.clientset.BatchV1().CronJobs(testNamespace).Get(t.Context(), name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("failed to get CronJob %s: %v", name, err)
+	}
+	return cronJob.Spec.Suspend != nil && *cronJob.Spec.Suspend
+}
+
+func TestBackupSuspendCronJob(t *testing.T) {
+	billing := newBackupRequest("billing", "postgres", "0 2 * * *", "Success", map[string]string{"team": "payments"})
+	billing.Status.CronJobData = backupv1.CreatedCronJobData{Name: "billing-backup", Namespace: testNamespace}
+	pending := newBackupRequest("pending", "postgres", "0 2 * * *", "", nil)
+	f := newFakeFactory(t, nil, billing, pending)
+	installBackupRequestCRD(t, f)
+	cronJob := &batchv1.CronJob{ObjectMeta: metav1.ObjectMeta{Name: "billing-backup", Namespace: testNamespace}}
+	if _, err := f.clientset.BatchV1().CronJobs(testNamespace).Create(t.Context(), cronJob, metav1.CreateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	// rewrite resets the CronJob the way the operator does when the BackupRequest changes.
+	rewrite := func() {
+		if _, err := f.clientset.BatchV1().CronJobs(testNamespace).Update(t.Context(), cronJob, metav1.UpdateOptions{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, err := runCmd(t, f, "backup", "suspend", "billing"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !cronJobSuspended(t, f, "billing-backup") {
+		t.Error("CronJob is not suspended")
+	}
+	if state, _ := suspensionState(f.backupRequest(t, "billing")); state != "yes" {
+		t.Errorf("suspension state = %q, want yes", state)
+	}
+
+	for _, args := range [][]string{
+		{"backup", "update", "billing", "spec.schedule=0 3 * * *", "--yes"},
+		{"backup", "label", "billing", "tier=gold"},
+		{"backup", "annotate", "billing", "owner=billing-team"},
+	} {
+		rewrite()
+		if _, err := runCmd(t, f, args...); err != nil {
+			t.Fatalf("%s: unexpected error: %v", strings.Join(args[:2], " "), err)
+		}
+		if !cronJobSuspended(t, f, "billing-backup") {
+			t.Errorf("CronJob is not suspended again after %s", strings.Join(args[:2], " "))
+		}
+	}
+
+	if _, err := runCmd(t, f, "backup", "resume", "billing"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cronJobSuspended(t, f, "billing-backup") {
+		t.Error("CronJob is still suspended after resume")
+	}
+	rewrite()
+	if _, err := runCmd(t, f, "backup", "label", "billing", "tier=silver", "--overwrite"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cronJobSuspended(t, f, "billing-backup") {
+		t.Error("label suspended the CronJob of a resumed BackupRequest")
+	}
+
+	if _, err := runCmd(t, f, "backup", "suspend", "pending"); err == nil || !strings.Contains(err.Error(), "has not created a CronJob") {
+		t.Errorf("error = %v, want one about the missing CronJob", err)
+	}
+	if state, _ := suspensionState(f.backupRequest(t, "pending")); state != "no" {
+		t.Errorf("suspension state = %q, want pending left untouched", state)
+	}
+}
+
+func TestBackupCloneTargetContext(t *testing.T) {
+	f := newFakeFactory(t, nil, testBackupRequests()...)
+	if _, err := runCmd(t, f, "backup", "clone", "billing", "billing-copy", "--context", "source", "--target-context", "target"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if f.flags.kube.Context != "source" {
+		t.Errorf("--context = %q, want source: the root flag selects the source cluster", f.flags.kube.Context)
+	}
+	if got := f.contexts; len(got) == 0 || got[len(got)-1] != "target" {
+		t.Errorf("ForContext() calls = %v, want the copy created in context target", got)
+	}
+	if clone := f.backupRequest(t, "billing-copy"); clone.Spec.DbSpec.DbType != "postgres" {
+		t.Errorf("clone has dbType %s, want postgres", clone.Spec.DbSpec.DbType)
+	}
+}
+
+func TestBackupFlagsNotShared(t *testing.T) {
+	f := newFakeFactory(t, nil)
+	first, second := newBackupCreateCmd(f), newBackupCreateCmd(f)
+	if err := first.Flags().Set("name", "billing"); err != nil {
+		t.Fatal(err)
+	}
+	if got := second.Flags().Lookup("name").Value.String(); got != "" {
+		t.Errorf("--name of another backup create = %q, want it unset", got)
+	}
+}
+
+func TestParseS3Spec(t *testing.T) {
+	tests := []struct {
+		s3      string
+		want    backupv1.S3Spec
+		wantErr bool
+	}{
+		{s3: "https://s3.example.com/backups", want: backupv1.S3Spec{Endpoint: "https://s3.example.com", BucketName: "backups"}},
+		{s3: "http://minio:9000/backups", want: backupv1.S3Spec{Endpoint: "http://minio:9000", BucketName: "backups"}},
+		{s3: "minio:9000/backups", want: backupv1.S3Spec{Endpoint: "minio:9000", BucketName: "backups"}},
+		{s3: "minio:9000", wantErr: true},
+		{s3: "ftp://minio:21/backups", wantErr: true},
+		{s3: "minio:http/backups", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := parseS3Spec(tt.s3)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseS3Spec(%q) error = %v, wantErr %v", tt.s3, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("parseS3Spec(%q) = %+v, want %+v", tt.s3, got, tt.want)
+		}
+		if !tt.wantErr {
+			if err := validateEndpoint(got.Endpoint); err != nil {
+				t.Errorf("wizard rejects endpoint %q accepted by --s3: %v", got.Endpoint, err)
+			}
+		}
+	}
+}
+
+func TestWizardAsk(t *testing.T) {
+	// All answers arrive at once, as when typed ahead or pasted.
+	var out bytes.Buffer
+	w := wizard{ctx: t.Context(), in: bufio.NewReader(strings.NewReader("0 25 * * *\n0 4 * * *\n7\n")), out: &out}
+
+	schedule, err := w.ask("Cron schedule", "", cron.Validate)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if schedule != "0 4 * * *" {
+		t.Errorf("schedule = %q, want the answer after the invalid one", schedule)
+	}
+	if !strings.Contains(out.String(), "✗ invalid hour") {
+		t.Errorf("output %q does not explain the invalid answer", out.String())
+	}
+	count, err := w.ask("Backups to keep", "3", validatePositive)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if count != "7" {
+		t.Errorf("count = %q, want the line read ahead with the previous answer", count)
+	}
+}
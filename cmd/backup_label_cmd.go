@@ -0,0 +1,178 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/oiler-backup/cli/internal/k8s"
+	backupv1 "github.com/oiler-backup/core/core/api/v1"
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/client-go/dynamic"
+)
+
+// backupMetadataFlags are the flags of backup label and annotate.
+type backupMetadataFlags struct {
+	backupSelectFlags
+	overwrite bool
+}
+
+// newBackupLabelCmd returns a command that updates labels of BackupRequests.
+func newBackupLabelCmd(f Factory) *cobra.Command {
+	flags := &backupMetadataFlags{}
+	cmd := &cobra.Command{
+		Use:   "label [name...] <key>=<value>... <key>-...",
+		Short: "Update labels of BackupRequests",
+		Long: `Update labels of BackupRequests given by names or selectors.
+
+<key>=<value> sets a label, <key>- removes it. Changing an existing label requires --overwrite.`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			return updateMetadata(ctx, f, "labels", args, flags.selector, flags.fieldSelector, flags.overwrite)
+		},
+	}
+
+	cmd.Flags().StringVarP(&flags.selector, "selector", "l", "", "Label selector of BackupRequests to label")
+	cmd.Flags().StringVar(&flags.fieldSelector, "field-selector", "", "Field selector of BackupRequests to label")
+	cmd.Flags().BoolVar(&flags.overwrite, "overwrite", false, "Allow changing existing labels")
+	cmd.ValidArgsFunction = completeBackupRequestNames(f, -1)
+	return cmd
+}
+
+// newBackupAnnotateCmd returns a command that updates annotations of BackupRequests.
+func newBackupAnnotateCmd(f Factory) *cobra.Command {
+	flags := &backupMetadataFlags{}
+	cmd := &cobra.Command{
+		Use:   "annotate [name...] <key>=<value>... <key>-...",
+		Short: "Update annotations of BackupRequests",
+		Long: `Update annotations of BackupRequests given by names or selectors.
+
+<key>=<value> sets an annotation, <key>- removes it. Changing an existing annotation requires --overwrite.`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			return updateMetadata(ctx, f, "annotations", args, flags.selector, flags.fieldSelector, flags.overwrite)
+		},
+	}
+
+	cmd.Flags().StringVarP(&flags.selector, "selector", "l", "", "Label selector of BackupRequests to annotate")
+	cmd.Flags().StringVar(&flags.fieldSelector, "field-selector", "", "Field selector of BackupRequests to annotate")
+	cmd.Flags().BoolVar(&flags.overwrite, "overwrite", false, "Allow changing existing annotations")
+	cmd.ValidArgsFunction = completeBackupRequestNames(f, -1)
+	return cmd
+}
+
+// updateMetadata applies label or annotation changes from args to selected BackupRequests.
+func updateMetadata(ctx context.Context, f Factory, kind string, args []string, selector, fieldSelector string, overwrite bool) error {
+	stopFn := startSpinner("[1/3] Preparing")
+	var names, changes []string
+	for _, arg := range args {
+		if k8s.IsMetadataChange(arg) {
+			changes = append(changes, arg)
+		} else {
+			names = append(names, arg)
+		}
+	}
+	if len(changes) == 0 {
+		stopFn()
+		return usageErrorf("no %s changes given, use <key>=<value> or <key>-", kind)
+	}
+
+	set, remove, err := k8s.ParseMetadataChanges(changes)
+	if err != nil {
+		stopFn()
+		return usageErrorf("invalid %s: %w", kind, err)
+	}
+	if err := validateMetadata(kind, set); err != nil {
+		stopFn()
+		return usageErrorf("invalid %s: %w", kind, err)
+	}
+
+	dynClient, err := f.DynamicClient()
+	if err != nil {
+		stopFn()
+		return fmt.Errorf("failed to get client: %w", err)
+	}
+	if err := checkAccess(ctx, f, permPatchBackups); err != nil {
+		stopFn()
+		return err
+	}
+	stopFn()
+
+	stopFn = startSpinner("[2/3] Getting BackupRequests")
+	backupRequests, err := selectBackupRequests(ctx, dynClient, names, selector, fieldSelector)
+	if err != nil {
+		stopFn()
+		return fmt.Errorf("failed to get BackupRequests: %w", err)
+	}
+	stopFn()
+
+	stopFn = startSpinner(fmt.Sprintf("[3/3] Updating %d BackupRequest(s)", len(backupRequests)))
+	var failed []string
+	var patched []unstructured.Unstructured
+	for _, br := range backupRequests {
+		item, err := patchMetadata(ctx, dynClient, br, kind, set, remove, overwrite)
+		if err != nil {
+			failed = append(failed, fmt.Sprintf("%s: %v", br.Name, err))
+			continue
+		}
+		patched = append(patched, *item)
+	}
+	err = reapplySuspension(ctx, f, dynClient, patched)
+	stopFn()
+	if err != nil {
+		log.Warnf("%v; run backup suspend on them again", err)
+	}
+
+	if len(failed) > 0 {
+		return fmt.Errorf("failed to update %s:\n%s", kind, strings.Join(failed, "\n"))
+	}
+	log.Infof("Successfully updated %s of %d BackupRequest(s)", kind, len(backupRequests))
+	return nil
+}
+
+// patchMetadata sends a merge patch changing labels or annotations of br and returns the patched object.
+func patchMetadata(ctx context.Context, dynClient dynamic.Interface, br backupv1.BackupRequest, kind string, set map[string]string, remove []string, overwrite bool) (*unstructured.Unstructured, error) {
+	current := br.Labels
+	if kind == "annotations" {
+		current = br.Annotations
+	}
+
+	changes := map[string]any{}
+	for key, value := range set {
+		if old, exists := current[key]; exists && old != value && !overwrite {
+			return nil, fmt.Errorf("%s %s is already set to %q, use --overwrite", kind, key, old)
+		}
+		changes[key] = value
+	}
+	for _, key := range remove {
+		changes[key] = nil
+	}
+
+	patch, err := json.Marshal(map[string]any{"metadata": map[string]any{kind: changes}})
+	if err != nil {
+		return nil, err
+	}
+	return dynClient.Resource(gvr).Patch(ctx, br.Name, types.MergePatchType, patch, metav1.PatchOptions{})
+}
+
+// validateMetadata checks label or annotation keys and label values.
+func validateMetadata(kind string, values map[string]string) error {
+	var problems []string
+	for key, value := range values {
+		problems = append(problems, validation.IsQualifiedName(key)...)
+		if kind == "labels" {
+			problems = append(problems, validation.IsValidLabelValue(value)...)
+		}
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("%s", strings.Join(problems, "; "))
+	}
+	return nil
+}
@@ -0,0 +1,284 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	backupv1 "github.com/oiler-backup/core/core/api/v1"
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+)
+
+// Annotations the CLI puts on suspended BackupRequests.
+const (
+	suspendedAnnotation      = "oiler.backup/suspended"
+	suspendedUntilAnnotation = "oiler.backup/suspended-until"
+)
+
+var (
+	crdGVR = schema.GroupVersionResource{
+		Group:    "apiextensions.k8s.io",
+		Version:  "v1",
+		Resource: "customresourcedefinitions",
+	}
+	backupRequestCRD = gvr.Resource + "." + gvr.Group
+)
+
+// backupSuspendFlags are the flags of backup suspend.
+type backupSuspendFlags struct {
+	backupSelectFlags
+	until string
+}
+
+// newBackupSuspendCmd returns a command that pauses scheduled backups.
+func newBackupSuspendCmd(f Factory) *cobra.Command {
+	flags := &backupSuspendFlags{}
+	cmd := &cobra.Command{
+		Use:   "suspend [name...]",
+		Short: "Suspend scheduled backups",
+		Long: `Suspend scheduled backups of BackupRequests without deleting them.
+
+If the BackupRequest CRD has spec.suspend it is used, otherwise the CronJob created for the
+BackupRequest is suspended. The operator rewrites that CronJob whenever the BackupRequest changes,
+so backup update, label and annotate suspend it again; the oiler.backup/suspended annotation
+tells which BackupRequests are suspended. With --until the time the suspension should end is recorded and
+backup list reminds about BackupRequests still suspended after it.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			var until string
+			if flags.until != "" {
+				t, err := parseUntil(flags.until)
+				if err != nil {
+					return usageErrorf("invalid --until: %w", err)
+				}
+				until = t.UTC().Format(time.RFC3339)
+			}
+			return setSuspended(ctx, f, args, flags.selector, flags.fieldSelector, true, until)
+		},
+	}
+
+	cmd.Flags().StringVarP(&flags.selector, "selector", "l", "", "Label selector of BackupRequests to suspend")
+	cmd.Flags().StringVar(&flags.fieldSelector, "field-selector", "", "Field selector of BackupRequests to suspend")
+	cmd.Flags().StringVar(&flags.until, "until", "", "When the suspension should end, as RFC3339 time or duration from now")
+	cmd.ValidArgsFunction = completeBackupRequestNames(f, -1)
+	return cmd
+}
+
+// newBackupResumeCmd returns a command that resumes suspended backups.
+func newBackupResumeCmd(f Factory) *cobra.Command {
+	flags := &backupSelectFlags{}
+	cmd := &cobra.Command{
+		Use:   "resume [name...]",
+		Short: "Resume suspended backups",
+		Long:  `Resume scheduled backups of BackupRequests suspended with backup suspend.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			return setSuspended(ctx, f, args, flags.selector, flags.fieldSelector, false, "")
+		},
+	}
+
+	cmd.Flags().StringVarP(&flags.selector, "selector", "l", "", "Label selector of BackupRequests to resume")
+	cmd.Flags().StringVar(&flags.fieldSelector, "field-selector", "", "Field selector of BackupRequests to resume")
+	cmd.ValidArgsFunction = completeBackupRequestNames(f, -1)
+	return cmd
+}
+
+// setSuspended suspends or resumes BackupRequests given by names or selector.
+func setSuspended(ctx context.Context, f Factory, names []string, selector, fieldSelector string, suspend bool, until string) error {
+	verb, done := "resume", "resumed"
+	if suspend {
+		verb, done = "suspend", "suspended"
+	}
+
+	stopFn := startSpinner("[1/3] Preparing")
+	dynClient, err := f.DynamicClient()
+	if err != nil {
+		stopFn()
+		return fmt.Errorf("failed to get client: %w", err)
+	}
+	clientset, err := f.ClientSet()
+	if err != nil {
+		stopFn()
+		return fmt.Errorf("failed to get client: %w", err)
+	}
+	if err := checkAccess(ctx, f, permPatchBackups); err != nil {
+		stopFn()
+		return err
+	}
+	viaSpec, err := suspendViaSpec(ctx, dynClient)
+	stopFn()
+	if err != nil {
+		return err
+	}
+
+	stopFn = startSpinner("[2/3] Getting BackupRequests")
+	backupRequests, err := selectBackupRequests(ctx, dynClient, names, selector, fieldSelector)
+	if err != nil {
+		stopFn()
+		return fmt.Errorf("failed to get BackupRequests: %w", err)
+	}
+	stopFn()
+
+	stopFn = startSpinner(fmt.Sprintf("[3/3] Updating %d BackupRequest(s)", len(backupRequests)))
+	var failed []string
+	for _, br := range backupRequests {
+		if err := suspendBackupRequest(ctx, dynClient, clientset, br, viaSpec, suspend, until); err != nil {
+			failed = append(failed, fmt.Sprintf("%s: %v", br.Name, err))
+		}
+	}
+	stopFn()
+
+	if len(failed) > 0 {
+		return fmt.Errorf("failed to %s BackupRequests:\n%s", verb, strings.Join(failed, "\n"))
+	}
+	log.Infof("Successfully %s %d BackupRequest(s)", done, len(backupRequests))
+	return nil
+}
+
+// suspendViaSpec reports whether the installed BackupRequest CRD declares spec.suspend.
+// Without it the CronJobs of BackupRequests are suspended instead.
+func suspendViaSpec(ctx context.Context, dynClient dynamic.Interface) (bool, error) {
+	found, err := crdHasSpecField(ctx, dynClient, "suspend")
+	if err != nil {
+		return false, fmt.Errorf("failed to check how BackupRequests can be suspended: %w", err)
+	}
+	return found, nil
+}
+
+// suspendBackupRequest toggles suspension of a single BackupRequest and records it in annotations.
+// The BackupRequest is patched first, so a CronJob the operator rewrites in response is suspended after it.
+func suspendBackupRequest(ctx context.Context, dynClient dynamic.Interface, clientset kubernetes.Interface, br backupv1.BackupRequest, viaSpec, suspend bool, until string) error {
+	if !viaSpec && br.Status.CronJobData.Name == "" {
+		return fmt.Errorf("the operator has not created a CronJob yet")
+	}
+
+	annotations := map[string]any{suspendedAnnotation: nil, suspendedUntilAnnotation: nil}
+	if suspend {
+		annotations[suspendedAnnotation] = "true"
+		if until != "" {
+			annotations[suspendedUntilAnnotation] = until
+		}
+	}
+	patch := map[string]any{"metadata": map[string]any{"annotations": annotations}}
+	if viaSpec {
+		patch["spec"] = map[string]any{"suspend": suspend}
+	}
+
+	data, err := json.Marshal(patch)
+	if err != nil {
+		return err
+	}
+	if _, err := dynClient.Resource(gvr).Patch(ctx, br.Name, types.MergePatchType, data, metav1.PatchOptions{}); err != nil {
+		return err
+	}
+	if viaSpec {
+		return nil
+	}
+	return suspendCronJob(ctx, clientset, br, suspend)
+}
+
+// suspendCronJob toggles spec.suspend of the CronJob the operator created for br.
+func suspendCronJob(ctx context.Context, clientset kubernetes.Interface, br backupv1.BackupRequest, suspend bool) error {
+	cronJob := br.Status.CronJobData
+	if cronJob.Name == "" {
+		return fmt.Errorf("the operator has not created a CronJob yet")
+	}
+	patch := fmt.Sprintf(`{"spec":{"suspend":%t}}`, suspend)
+	_, err := clientset.BatchV1().CronJobs(cronJob.Namespace).Patch(ctx, cronJob.Name, types.MergePatchType, []byte(patch), metav1.PatchOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to patch CronJob %s/%s: %w", cronJob.Namespace, cronJob.Name, err)
+	}
+	return nil
+}
+
+// reapplySuspension suspends again the CronJobs of suspended BackupRequests among items, which were just changed.
+// It is a no-op when the CRD has spec.suspend, as the operator then keeps the CronJobs suspended itself.
+func reapplySuspension(ctx context.Context, f Factory, dynClient dynamic.Interface, items []unstructured.Unstructured) error {
+	var suspended []backupv1.BackupRequest
+	for _, item := range items {
+		if item.GetAnnotations()[suspendedAnnotation] != "true" {
+			continue
+		}
+		br, err := toBackupRequest(&item)
+		if err != nil {
+			return err
+		}
+		suspended = append(suspended, br)
+	}
+	if len(suspended) == 0 {
+		return nil
+	}
+
+	viaSpec, err := suspendViaSpec(ctx, dynClient)
+	if err != nil || viaSpec {
+		return err
+	}
+	clientset, err := f.ClientSet()
+	if err != nil {
+		return fmt.Errorf("failed to get client: %w", err)
+	}
+	var failed []string
+	for _, br := range suspended {
+		if err := suspendCronJob(ctx, clientset, br, true); err != nil {
+			failed = append(failed, fmt.Sprintf("%s: %v", br.Name, err))
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("failed to keep BackupRequests suspended:\n%s", strings.Join(failed, "\n"))
+	}
+	return nil
+}
+
+// crdHasSpecField reports whether the served BackupRequest CRD declares spec.<field>.
+func crdHasSpecField(ctx context.Context, dynClient dynamic.Interface, field string) (bool, error) {
+	crd, err := dynClient.Resource(crdGVR).Get(ctx, backupRequestCRD, metav1.GetOptions{})
+	if err != nil {
+		return false, fmt.Errorf("failed to get CRD %s: %w", backupRequestCRD, err)
+	}
+	versions, _, _ := unstructured.NestedSlice(crd.Object, "spec", "versions")
+	for _, v := range versions {
+		version, ok := v.(map[string]any)
+		if !ok || version["name"] != gvr.Version {
+			continue
+		}
+		_, found, _ := unstructured.NestedMap(version, "schema", "openAPIV3Schema", "properties", "spec", "properties", field)
+		return found, nil
+	}
+	return false, nil
+}
+
+// parseUntil accepts RFC3339 time or a duration from now.
+func parseUntil(s string) (time.Time, error) {
+	if d, err := time.ParseDuration(s); err == nil {
+		return time.Now().Add(d), nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%q is neither RFC3339 time nor duration", s)
+	}
+	return t, nil
+}
+
+// suspensionState describes suspension of br for backup list and reports whether its window has passed.
+func suspensionState(br backupv1.BackupRequest) (string, bool) {
+	if br.Annotations[suspendedAnnotation] != "true" {
+		return "no", false
+	}
+	until := br.Annotations[suspendedUntilAnnotation]
+	if until == "" {
+		return "yes", false
+	}
+	t, err := time.Parse(time.RFC3339, until)
+	if err != nil {
+		return "yes", false
+	}
+	return "until " + t.Local().Format(time.DateTime), time.Now().After(t)
+}
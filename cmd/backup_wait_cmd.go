@@ -0,0 +1,217 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/oiler-backup/cli/internal/watch"
+	backupv1 "github.com/oiler-backup/core/core/api/v1"
+	"github.com/spf13/cobra"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8swatch "k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+	watchtools "k8s.io/client-go/tools/watch"
+)
+
+// Conditions understood by backup wait --for.
+const (
+	waitForStatusPrefix = "status="
+	waitForFirstSuccess = "first-success"
+	waitForDelete       = "delete"
+)
+
+// backupWaitFlags are the flags of backup wait.
+type backupWaitFlags struct {
+	// condition is the value of --for.
+	condition string
+	timeout   time.Duration
+}
+
+// errWaitFailed tells that the awaited condition can no longer be met.
+var errWaitFailed = errors.New("condition failed")
+
+// newBackupWaitCmd returns a command that blocks until a BackupRequest reaches a condition.
+func newBackupWaitCmd(f Factory) *cobra.Command {
+	flags := &backupWaitFlags{}
+	cmd := &cobra.Command{
+		Use:   "wait <name> --for=status=<value>|first-success|delete",
+		Short: "Wait for a BackupRequest condition",
+		Long: `Block until a BackupRequest reaches a condition:
+
+  --for=status=<value>  Status.Status becomes <value> (case-insensitive)
+  --for=first-success   the first backup Job of the BackupRequest completes
+  --for=delete          the BackupRequest is deleted
+
+Exit codes: 0 - condition met, 2 - condition failed (the backup Job failed or
+the BackupRequest was deleted), 3 - timeout, others as listed in oiler-cli --help.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name := args[0]
+
+			condition, wantStatus := flags.condition, ""
+			if value, ok := strings.CutPrefix(flags.condition, waitForStatusPrefix); ok {
+				if value == "" {
+					return usageErrorf("invalid --for value %q, status= needs a value, e.g. status=Success", flags.condition)
+				}
+				condition, wantStatus = waitForStatusPrefix, value
+			}
+			switch condition {
+			case waitForStatusPrefix, waitForFirstSuccess, waitForDelete:
+			default:
+				return usageErrorf("invalid --for value %q, use status=<value>, %s or %s", flags.condition, waitForFirstSuccess, waitForDelete)
+			}
+
+			dynClient, err := f.DynamicClient()
+			if err != nil {
+				return fmt.Errorf("failed to get client: %w", err)
+			}
+
+			ctx, cancel := context.WithTimeout(cmd.Context(), flags.timeout)
+			defer cancel()
+
+			lw := &cache.ListWatch{
+				ListWithContextFunc: func(ctx context.Context, opts metav1.ListOptions) (runtime.Object, error) {
+					opts.FieldSelector = fields.OneTermEqualSelector("metadata.name", name).String()
+					return dynClient.Resource(gvr).List(ctx, opts)
+				},
+				WatchFuncWithContext: func(ctx context.Context, opts metav1.ListOptions) (k8swatch.Interface, error) {
+					opts.FieldSelector = fields.OneTermEqualSelector("metadata.name", name).String()
+					return dynClient.Resource(gvr).Watch(ctx, opts)
+				},
+			}
+
+			stopFn := startSpinner(fmt.Sprintf("Waiting for BackupRequest %s to meet %s", name, flags.condition))
+			switch condition {
+			case waitForDelete:
+				err = waitBackupDeleted(ctx, lw, name)
+			case waitForStatusPrefix:
+				err = waitBackupStatus(ctx, lw, wantStatus)
+			case waitForFirstSuccess:
+				err = waitBackupFirstSuccess(ctx, f, lw, name)
+			}
+			stopFn()
+
+			switch {
+			case err == nil:
+				log.Infof("BackupRequest %s met condition %s", name, flags.condition)
+			case errors.Is(err, errWaitFailed):
+				return &cliError{code: exitWaitFailed, err: fmt.Errorf("BackupRequest %s can not meet condition %s: %w", name, flags.condition, err)}
+			case errors.Is(ctx.Err(), context.Canceled):
+				return fmt.Errorf("stopped waiting for BackupRequest %s: %w", name, ctx.Err())
+			case errors.Is(ctx.Err(), context.DeadlineExceeded):
+				return &cliError{
+					code: exitTimeout,
+					err:  fmt.Errorf("timed out after %s waiting for BackupRequest %s to meet condition %s", flags.timeout, name, flags.condition),
+					hint: "Raise --timeout if the backup takes longer.",
+				}
+			default:
+				return fmt.Errorf("failed to wait for BackupRequest %s: %w", name, err)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&flags.condition, "for", "", "Condition to wait for: status=<value>, first-success or delete")
+	cmd.Flags().DurationVar(&flags.timeout, "timeout", 5*time.Minute, "How long to wait before giving up")
+	cmd.MarkFlagRequired("for")
+	cmd.ValidArgsFunction = completeBackupRequestNames(f, 1)
+	return cmd
+}
+
+// waitBackupDeleted waits until the BackupRequest disappears.
+func waitBackupDeleted(ctx context.Context, lw cache.ListerWatcher, name string) error {
+	precondition := func(store cache.Store) (bool, error) {
+		_, exists, err := store.GetByKey(name)
+		return !exists, err
+	}
+	_, err := watchtools.UntilWithSync(ctx, lw, &unstructured.Unstructured{}, precondition, func(e k8swatch.Event) (bool, error) {
+		return e.Type == k8swatch.Deleted, nil
+	})
+	return err
+}
+
+// waitBackupStatus waits until Status.Status equals want.
+func waitBackupStatus(ctx context.Context, lw cache.ListerWatcher, want string) error {
+	_, err := watchtools.UntilWithSync(ctx, lw, &unstructured.Unstructured{}, nil, func(e k8swatch.Event) (bool, error) {
+		if e.Type == k8swatch.Deleted {
+			return false, fmt.Errorf("%w: BackupRequest was deleted", errWaitFailed)
+		}
+		br, err := watch.ToBackupRequest(e.Object)
+		if err != nil {
+			return false, err
+		}
+		return strings.EqualFold(br.Status.Status, want), nil
+	})
+	return err
+}
+
+// waitBackupFirstSuccess waits for the BackupRequest CronJob to appear and its first Job to finish.
+func waitBackupFirstSuccess(ctx context.Context, f Factory, lw cache.ListerWatcher, name string) error {
+	var br backupv1.BackupRequest
+	_, err := watchtools.UntilWithSync(ctx, lw, &unstructured.Unstructured{}, nil, func(e k8swatch.Event) (bool, error) {
+		if e.Type == k8swatch.Deleted {
+			return false, fmt.Errorf("%w: BackupRequest was deleted", errWaitFailed)
+		}
+		var err error
+		br, err = watch.ToBackupRequest(e.Object)
+		if err != nil {
+			return false, err
+		}
+		return br.Status.LastBackupTime != nil || br.Status.CronJobData.Name != "", nil
+	})
+	if err != nil {
+		return err
+	}
+	if br.Status.LastBackupTime != nil {
+		return nil
+	}
+
+	clientset, err := f.ClientSet()
+	if err != nil {
+		return err
+	}
+	return waitCronJobFirstJob(ctx, clientset, br.Status.CronJobData.Namespace, br.Status.CronJobData.Name)
+}
+
+// waitCronJobFirstJob waits until a Job created by CronJob namespace/cronJob completes or fails.
+func waitCronJobFirstJob(ctx context.Context, clientset kubernetes.Interface, namespace, cronJob string) error {
+	lw := cache.NewListWatchFromClient(clientset.BatchV1().RESTClient(), "jobs", namespace, fields.Everything())
+	_, err := watchtools.UntilWithSync(ctx, lw, &batchv1.Job{}, nil, func(e k8swatch.Event) (bool, error) {
+		job, ok := e.Object.(*batchv1.Job)
+		if !ok || e.Type == k8swatch.Deleted || !ownedByCronJob(job, cronJob) {
+			return false, nil
+		}
+		for _, c := range job.Status.Conditions {
+			if c.Status != corev1.ConditionTrue {
+				continue
+			}
+			switch c.Type {
+			case batchv1.JobComplete:
+				return true, nil
+			case batchv1.JobFailed:
+				return false, fmt.Errorf("%w: Job %s failed: %s", errWaitFailed, job.Name, c.Message)
+			}
+		}
+		return false, nil
+	})
+	return err
+}
+
+// ownedByCronJob reports whether job was created by CronJob cronJob.
+func ownedByCronJob(job *batchv1.Job, cronJob string) bool {
+	for _, ref := range job.OwnerReferences {
+		if ref.Kind == "CronJob" && ref.Name == cronJob {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,188 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/jedib0t/go-pretty/v6/text"
+	"github.com/oiler-backup/cli/internal/watch"
+	backupv1 "github.com/oiler-backup/core/core/api/v1"
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// A watchEvent is a JSON line emitted by backup watch when stdout is not a terminal.
+type watchEvent struct {
+	Time           time.Time `json:"time"`
+	Type           string    `json:"type"`
+	Name           string    `json:"name"`
+	DbType         string    `json:"dbType"`
+	Schedule       string    `json:"schedule"`
+	Status         string    `json:"status"`
+	PreviousStatus string    `json:"previousStatus,omitempty"`
+}
+
+// A watchedBackup is a row of backup watch table.
+type watchedBackup struct {
+	br         backupv1.BackupRequest
+	transition string
+	changedAt  time.Time
+}
+
+// newBackupWatchCmd returns a command that shows BackupRequest status changes live.
+func newBackupWatchCmd(f Factory) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "watch",
+		Short: "Watch BackupRequest status changes",
+		Long: `Watch BackupRequest resources and show status changes as they happen.
+
+On a terminal the table is redrawn in place and status transitions are highlighted.
+Otherwise one JSON event is printed per change, which is handy for piping into other tools.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runBackupWatch(cmd.Context(), f, cmd.OutOrStdout(), "", "")
+		},
+	}
+	return cmd
+}
+
+// runBackupWatch streams changes of BackupRequests matching selectors to out until interrupted.
+// A terminal gets a table redrawn in place, anything else gets JSON lines.
+func runBackupWatch(ctx context.Context, f Factory, out io.Writer, selector, fieldSelector string) error {
+	stopFn := startSpinner("[1/2] Preparing")
+	dynClient, err := f.DynamicClient()
+	if err != nil {
+		stopFn()
+		return fmt.Errorf("failed to get client: %w", err)
+	}
+	stopFn()
+
+	stopFn = startSpinner("[2/2] Starting watch")
+	events, err := watch.BackupRequests(ctx, dynClient, gvr, func(opts *metav1.ListOptions) {
+		opts.LabelSelector = selector
+		opts.FieldSelector = fieldSelector
+	})
+	stopFn()
+	if err != nil {
+		return fmt.Errorf("failed to watch BackupRequests: %w", err)
+	}
+
+	if !isTerminal(out) {
+		encoder := json.NewEncoder(out)
+		for e := range events {
+			err := encoder.Encode(watchEvent{
+				Time:           time.Now().UTC(),
+				Type:           string(e.Type),
+				Name:           e.Object.Name,
+				DbType:         e.Object.Spec.DbSpec.DbType,
+				Schedule:       e.Object.Spec.Schedule,
+				Status:         e.Object.Status.Status,
+				PreviousStatus: e.PreviousStatus,
+			})
+			if err != nil {
+				return fmt.Errorf("failed to write event: %w", err)
+			}
+		}
+		return nil
+	}
+
+	rows := map[string]*watchedBackup{}
+	redraw := time.NewTicker(200 * time.Millisecond)
+	defer redraw.Stop()
+	dirty := true
+	for {
+		select {
+		case e, ok := <-events:
+			if !ok {
+				fmt.Fprintln(out)
+				return nil
+			}
+			applyWatchEvent(rows, e)
+			dirty = true
+		case <-redraw.C:
+			if dirty {
+				renderWatchTable(out, rows)
+				dirty = false
+			}
+		}
+	}
+}
+
+// applyWatchEvent updates watched rows with e.
+func applyWatchEvent(rows map[string]*watchedBackup, e watch.Event) {
+	switch e.Type {
+	case watch.Deleted:
+		delete(rows, e.Object.Name)
+	case watch.Added:
+		rows[e.Object.Name] = &watchedBackup{br: e.Object}
+	case watch.Modified:
+		row, exists := rows[e.Object.Name]
+		if !exists {
+			row = &watchedBackup{}
+			rows[e.Object.Name] = row
+		}
+		row.br = e.Object
+		if e.StatusChanged() {
+			row.transition = fmt.Sprintf("%s → %s", statusOrDash(e.PreviousStatus), statusOrDash(e.Object.Status.Status))
+			row.changedAt = time.Now()
+		}
+	}
+}
+
+// renderWatchTable clears the terminal out and draws current rows.
+func renderWatchTable(out io.Writer, rows map[string]*watchedBackup) {
+	names := make([]string, 0, len(rows))
+	for name := range rows {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	t := table.NewWriter()
+	t.SetStyle(table.StyleLight)
+	t.AppendHeader(table.Row{"#", "BackupRequest Name", "Database Type", "Schedule", "Status", "Last Transition", "Changed"})
+	for i, name := range names {
+		row := rows[name]
+		status := statusColor(row.br.Status.Status).Sprint(statusOrDash(row.br.Status.Status))
+		transition, changed := "", ""
+		if row.transition != "" {
+			transition = statusColor(row.br.Status.Status).Sprint(row.transition)
+			changed = row.changedAt.Format(time.TimeOnly)
+		}
+		t.AppendRow(table.Row{i + 1, name, row.br.Spec.DbSpec.DbType, row.br.Spec.Schedule, status, transition, changed})
+		t.AppendSeparator()
+	}
+	t.AppendFooter(table.Row{"", "", "", "", "", "TOTAL", len(names)})
+
+	fmt.Fprint(out, "\033[H\033[2J")
+	fmt.Fprintf(out, "Watching BackupRequests, press Ctrl-C to stop. Updated %s\n", time.Now().Format(time.TimeOnly))
+	fmt.Fprintln(out, t.Render())
+}
+
+// statusColor picks a highlight for status.
+func statusColor(status string) text.Colors {
+	s := strings.ToLower(status)
+	switch {
+	case strings.Contains(s, "fail"), strings.Contains(s, "error"):
+		return text.Colors{text.FgRed, text.Bold}
+	case strings.Contains(s, "success"):
+		return text.Colors{text.FgGreen}
+	case s == "":
+		return text.Colors{}
+	default:
+		return text.Colors{text.FgYellow}
+	}
+}
+
+// statusOrDash replaces an empty status with a dash.
+func statusOrDash(status string) string {
+	if status == "" {
+		return "-"
+	}
+	return status
+}
@@ -0,0 +1,329 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/oiler-backup/cli/internal/adapters"
+	"github.com/oiler-backup/cli/internal/cron"
+	backupv1 "github.com/oiler-backup/core/core/api/v1"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"sigs.k8s.io/yaml"
+)
+
+// defaultDBPorts suggests a port for well-known database types.
+var defaultDBPorts = map[string]int{
+	"postgres":   5432,
+	"postgresql": 5432,
+	"mysql":      3306,
+	"mariadb":    3306,
+	"mongodb":    27017,
+	"redis":      6379,
+}
+
+var bucketRegex = regexp.MustCompile(`^[a-z0-9][a-z0-9.-]{1,61}[a-z0-9]$`)
+
+// A wizard asks backup create questions on the terminal, until ctx ends.
+// Questions and validation errors go to out, stderr of the command, so stdout stays free for output.
+type wizard struct {
+	ctx context.Context
+	in  *bufio.Reader
+	out io.Writer
+}
+
+// wantCreateWizard reports whether backup create should ask for missing values.
+// It does when --interactive is set, or when required flags are missing and stdin is a terminal.
+func wantCreateWizard(flags *backupCreateFlags) bool {
+	if flags.interactive {
+		return true
+	}
+	missing := flags.name == "" || (flags.template == "" && (flags.db == "" || flags.s3 == ""))
+	return missing && term.IsTerminal(int(os.Stdin.Fd()))
+}
+
+// runCreateWizard asks for BackupRequest values step by step, offering current flag values as defaults,
+// and stores the answers in flags.
+// A --template is applied once the name is known. It returns labels and annotations of the template.
+func runCreateWizard(f Factory, cmd *cobra.Command, flags *backupCreateFlags) (map[string]string, map[string]string, error) {
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		return nil, nil, fmt.Errorf("interactive mode needs a terminal")
+	}
+	w := wizard{ctx: cmd.Context(), in: stdin, out: cmd.ErrOrStderr()}
+	fmt.Fprintln(w.out, "Creating a BackupRequest, press Enter to accept [defaults].")
+
+	var err error
+	if flags.name, err = w.ask("BackupRequest name", flags.name, validateResourceName); err != nil {
+		return nil, nil, err
+	}
+
+	var templateLabels, templateAnnotations map[string]string
+	if flags.template != "" {
+		templateLabels, templateAnnotations, err = applyBackupTemplate(f, cmd, flags)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to apply template: %w", err)
+		}
+	}
+
+	var current backupv1.DatabaseSpec
+	if flags.db != "" {
+		current, _ = parseDBSpec(flags.db)
+	}
+	fmt.Fprintln(w.out, "\nDatabase")
+	dbType, err := w.choose("Database type", adapterNames(cmd.Context(), f), current.DbType)
+	if err != nil {
+		return nil, nil, err
+	}
+	host, err := w.ask("Host", current.URI, validateHost)
+	if err != nil {
+		return nil, nil, err
+	}
+	port := current.Port
+	if port == 0 {
+		port = defaultDBPorts[strings.ToLower(dbType)]
+	}
+	portAnswer, err := w.ask("Port", portString(port), validatePort)
+	if err != nil {
+		return nil, nil, err
+	}
+	dbName, err := w.ask("Database name", current.DbName, validateNotEmpty)
+	if err != nil {
+		return nil, nil, err
+	}
+	if flags.dbUser, err = w.ask("User", flags.dbUser, validateNotEmpty); err != nil {
+		return nil, nil, err
+	}
+	if flags.dbPass, err = w.askSecret("Password", flags.dbPass); err != nil {
+		return nil, nil, err
+	}
+	flags.db = fmt.Sprintf("%s@%s:%s/%s", dbType, host, portAnswer, dbName)
+
+	var currentS3 backupv1.S3Spec
+	if flags.s3 != "" {
+		currentS3, _ = parseS3Spec(flags.s3)
+	}
+	fmt.Fprintln(w.out, "\nS3 storage")
+	endpoint, err := w.ask("Endpoint ([http(s)://]host[:port])", currentS3.Endpoint, validateEndpoint)
+	if err != nil {
+		return nil, nil, err
+	}
+	bucket, err := w.ask("Bucket", currentS3.BucketName, validateBucket)
+	if err != nil {
+		return nil, nil, err
+	}
+	if flags.s3AccessKey, err = w.askSecret("Access key", flags.s3AccessKey); err != nil {
+		return nil, nil, err
+	}
+	if flags.s3SecretKey, err = w.askSecret("Secret key", flags.s3SecretKey); err != nil {
+		return nil, nil, err
+	}
+	flags.s3 = endpoint + "/" + bucket
+
+	fmt.Fprintln(w.out, "\nSchedule")
+	if flags.schedule, err = w.ask("Cron schedule", flags.schedule, cron.Validate); err != nil {
+		return nil, nil, err
+	}
+	count, err := w.ask("Backups to keep", strconv.FormatInt(flags.maxBackupCount, 10), validatePositive)
+	if err != nil {
+		return nil, nil, err
+	}
+	flags.maxBackupCount, _ = strconv.ParseInt(count, 10, 64)
+	fmt.Fprintln(w.out)
+
+	// Answers replace prompts the flags would trigger later.
+	flags.dbUserStdin, flags.dbPassStdin, flags.s3AccessKeyStdin, flags.s3SecretKeyStdin = false, false, false, false
+	return templateLabels, templateAnnotations, nil
+}
+
+// ask prompts until the answer, or def on empty input, passes validate.
+func (w wizard) ask(label, def string, validate func(string) error) (string, error) {
+	for {
+		if def != "" {
+			fmt.Fprintf(w.out, "  %s [%s]: ", label, def)
+		} else {
+			fmt.Fprintf(w.out, "  %s: ", label)
+		}
+		answer, err := readInterruptibly(w.ctx, func() (string, error) {
+			return w.in.ReadString('\n')
+		})
+		if err != nil && (!errors.Is(err, io.EOF) || answer == "") {
+			return "", fmt.Errorf("failed to read answer: %w", err)
+		}
+		answer = strings.TrimSpace(answer)
+		if answer == "" {
+			answer = def
+		}
+		if err := validate(answer); err != nil {
+			fmt.Fprintf(w.out, "  ✗ %v\n", err)
+			continue
+		}
+		return answer, nil
+	}
+}
+
+// askSecret reads a value without echo, keeping an already given one on empty input.
+func (w wizard) askSecret(label, current string) (string, error) {
+	prompt := "  " + label
+	if current != "" {
+		prompt += " (empty to keep the given one)"
+	}
+	for {
+		value, err := readSecret(w.ctx, prompt)
+		if err != nil {
+			return "", fmt.Errorf("failed to read %s: %w", strings.ToLower(label), err)
+		}
+		if value == "" {
+			value = current
+		}
+		if value != "" {
+			return value, nil
+		}
+		fmt.Fprintf(w.out, "  ✗ %s must not be empty\n", label)
+	}
+}
+
+// choose offers numbered options, accepting a number or a value.
+// Any non-empty value is accepted when there are no options.
+func (w wizard) choose(label string, options []string, def string) (string, error) {
+	if len(options) == 0 {
+		return w.ask(label, def, validateNotEmpty)
+	}
+	for i, option := range options {
+		fmt.Fprintf(w.out, "  %d) %s\n", i+1, option)
+	}
+	if def == "" && len(options) == 1 {
+		def = options[0]
+	}
+	return w.askMapped(label, def, func(answer string) (string, error) {
+		if n, err := strconv.Atoi(answer); err == nil && n >= 1 && n <= len(options) {
+			return options[n-1], nil
+		}
+		for _, option := range options {
+			if option == answer {
+				return option, nil
+			}
+		}
+		return "", fmt.Errorf("choose 1-%d or one of the listed names", len(options))
+	})
+}
+
+// askMapped is ask with an answer converted by fn.
+func (w wizard) askMapped(label, def string, fn func(string) (string, error)) (string, error) {
+	var mapped string
+	_, err := w.ask(label, def, func(answer string) error {
+		var err error
+		mapped, err = fn(answer)
+		return err
+	})
+	return mapped, err
+}
+
+// adapterNames returns database types registered in the adapter ConfigMap, nil if it cannot be read.
+func adapterNames(ctx context.Context, f Factory) []string {
+	clientset, err := f.ClientSet()
+	if err != nil {
+		log.Warnf("Cannot offer database types: %v", err)
+		return nil
+	}
+	configMap, err := getAdapterConfigMap(ctx, f, clientset)
+	if err != nil {
+		log.Warnf("Cannot offer database types: %v", err)
+		return nil
+	}
+	return adapters.Names(configMap.Data)
+}
+
+// printManifest shows br on w as YAML with credentials masked.
+func printManifest(w io.Writer, br backupv1.BackupRequest) error {
+	masked := br
+	masked.Spec.DbSpec.Pass = mask(br.Spec.DbSpec.Pass)
+	masked.Spec.S3Spec.Auth.AccessKey = mask(br.Spec.S3Spec.Auth.AccessKey)
+	masked.Spec.S3Spec.Auth.SecretKey = mask(br.Spec.S3Spec.Auth.SecretKey)
+
+	data, err := yaml.Marshal(masked)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "---\n%s---\n", data)
+	return nil
+}
+
+// mask hides a secret, keeping whether it is set visible.
+func mask(secret string) string {
+	if secret == "" {
+		return ""
+	}
+	return "********"
+}
+
+// portString formats port, empty for unknown.
+func portString(port int) string {
+	if port == 0 {
+		return ""
+	}
+	return strconv.Itoa(port)
+}
+
+// validateNotEmpty rejects empty answers.
+func validateNotEmpty(s string) error {
+	if s == "" {
+		return fmt.Errorf("value must not be empty")
+	}
+	return nil
+}
+
+// validateResourceName checks a Kubernetes object name.
+func validateResourceName(s string) error {
+	if problems := validation.IsDNS1123Subdomain(s); len(problems) > 0 {
+		return fmt.Errorf("%s", strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// validateHost checks a host name or IP address.
+func validateHost(s string) error {
+	if s == "" || strings.ContainsAny(s, "@:/ ") {
+		return fmt.Errorf("enter a host name or IP address without port")
+	}
+	return nil
+}
+
+// validatePort checks a TCP port.
+func validatePort(s string) error {
+	port, err := strconv.Atoi(s)
+	if err != nil || port < 1 || port > 65535 {
+		return fmt.Errorf("port must be a number between 1 and 65535")
+	}
+	return nil
+}
+
+// validateEndpoint checks an S3 endpoint the way backup create --s3 does.
+func validateEndpoint(s string) error {
+	_, err := parseS3Endpoint(s)
+	return err
+}
+
+// validateBucket checks an S3 bucket name.
+func validateBucket(s string) error {
+	if !bucketRegex.MatchString(s) {
+		return fmt.Errorf("bucket names are 3-63 lowercase letters, digits, dots and hyphens")
+	}
+	return nil
+}
+
+// validatePositive checks a positive integer.
+func validatePositive(s string) error {
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || n < 1 {
+		return fmt.Errorf("enter a positive number")
+	}
+	return nil
+}
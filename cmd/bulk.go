@@ -0,0 +1,101 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// Values of --dry-run.
+const (
+	dryRunNone   = "none"
+	dryRunClient = "client"
+	dryRunServer = "server"
+)
+
+// A bulkResult is an outcome of an action on a single object.
+type bulkResult struct {
+	Name string
+	Err  error
+}
+
+// parseDryRun validates --dry-run value and returns DryRun option for API calls.
+func parseDryRun(value string) ([]string, error) {
+	switch value {
+	case "", dryRunNone, dryRunClient:
+		return nil, nil
+	case dryRunServer:
+		return []string{metav1.DryRunAll}, nil
+	default:
+		return nil, usageErrorf("invalid --dry-run value %q, use %s, %s or %s", value, dryRunNone, dryRunClient, dryRunServer)
+	}
+}
+
+// renderAffected prints BackupRequests a bulk action is going to touch.
+func renderAffected(w io.Writer, items []unstructured.Unstructured) {
+	t := table.NewWriter()
+	t.SetOutputMirror(w)
+	t.SetStyle(table.StyleLight)
+	t.AppendHeader(table.Row{"#", "BackupRequest Name", "Database Type", "Schedule", "Status"})
+	for i, item := range items {
+		dbType, _, _ := unstructured.NestedString(item.Object, "spec", "dbSpec", "dbType")
+		schedule, _, _ := unstructured.NestedString(item.Object, "spec", "schedule")
+		status, _, _ := unstructured.NestedString(item.Object, "status", "status")
+		t.AppendRow(table.Row{i + 1, item.GetName(), dbType, schedule, status})
+	}
+	t.AppendFooter(table.Row{"", "", "", "TOTAL", len(items)})
+	t.Render()
+}
+
+// runBulk calls action for every item using at most parallel workers, reporting progress as text.
+// Results keep the order of items.
+func runBulk(text string, items []unstructured.Unstructured, parallel int, action func(item *unstructured.Unstructured) error) []bulkResult {
+	bar := startProgress(text, len(items))
+	defer bar.Done()
+
+	results := make([]bulkResult, len(items))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for range max(1, parallel) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				results[i] = bulkResult{Name: items[i].GetName(), Err: action(&items[i])}
+				bar.Increment()
+			}
+		}()
+	}
+	for i := range items {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	return results
+}
+
+// renderBulkResults prints per-object results and returns the number of failures.
+func renderBulkResults(w io.Writer, results []bulkResult, done string) int {
+	t := table.NewWriter()
+	t.SetOutputMirror(w)
+	t.SetStyle(table.StyleLight)
+	t.AppendHeader(table.Row{"#", "BackupRequest Name", "Result", "Details"})
+	failed := 0
+	for i, res := range results {
+		if res.Err != nil {
+			failed++
+			t.AppendRow(table.Row{i + 1, res.Name, "failed", res.Err.Error()})
+			continue
+		}
+		t.AppendRow(table.Row{i + 1, res.Name, done, ""})
+	}
+	t.AppendFooter(table.Row{"", "", "FAILED", fmt.Sprintf("%d/%d", failed, len(results))})
+	t.Render()
+
+	return failed
+}
@@ -0,0 +1,345 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/oiler-backup/cli/internal/adapters"
+	"github.com/oiler-backup/cli/internal/completion"
+	"github.com/oiler-backup/cli/internal/config"
+	"github.com/oiler-backup/cli/internal/templates"
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+// completionTimeout bounds cluster requests made while completing, a slow TAB is worse than none.
+const completionTimeout = 2 * time.Second
+
+// updateFields are BackupRequest fields offered by backup update.
+var updateFields = []string{
+	"spec.schedule",
+	"spec.maxBackupCount",
+	"spec.dbSpec.dbType",
+	"spec.dbSpec.uri",
+	"spec.dbSpec.port",
+	"spec.dbSpec.dbName",
+	"spec.dbSpec.user",
+	"spec.dbSpec.pass",
+	"spec.s3Spec.endpoint",
+	"spec.s3Spec.bucketName",
+	"spec.s3Spec.auth.accessKey",
+	"spec.s3Spec.auth.secretKey",
+}
+
+// newCompletionCmd returns a command that prints shell completion scripts.
+func newCompletionCmd(f Factory) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "completion bash|zsh|fish|powershell",
+		Short: "Generate a shell completion script",
+		Long: `Generate a completion script for bash, zsh, fish or powershell.
+
+Besides commands and flags it completes BackupRequest names, adapter names, database types,
+template names, configuration parameters and kubeconfig contexts. Values read from the cluster
+are cached for ` + completion.DefaultTTL.String() + ` in ~/.oiler/cache/completion, and requests give up after ` + completionTimeout.String() + `.
+
+Bash (needs the bash-completion package):
+  source <(oiler-cli completion bash)
+  oiler-cli completion bash > /etc/bash_completion.d/oiler-cli
+
+Zsh:
+  oiler-cli completion zsh > "${fpath[1]}/_oiler-cli"
+
+Fish:
+  oiler-cli completion fish > ~/.config/fish/completions/oiler-cli.fish
+
+PowerShell:
+  oiler-cli completion powershell | Out-String | Invoke-Expression`,
+		Annotations:           map[string]string{skipConfigAnnotation: "true"},
+		ValidArgs:             []string{"bash", "zsh", "fish", "powershell"},
+		Args:                  cobra.MatchAll(cobra.ExactArgs(1), cobra.OnlyValidArgs),
+		DisableFlagsInUseLine: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var err error
+			switch args[0] {
+			case "bash":
+				err = cmd.Root().GenBashCompletionV2(cmd.OutOrStdout(), true)
+			case "zsh":
+				err = cmd.Root().GenZshCompletion(cmd.OutOrStdout())
+			case "fish":
+				err = cmd.Root().GenFishCompletion(cmd.OutOrStdout(), true)
+			case "powershell":
+				err = cmd.Root().GenPowerShellCompletionWithDesc(cmd.OutOrStdout())
+			}
+			if err != nil {
+				return fmt.Errorf("failed to generate completion script: %w", err)
+			}
+			return nil
+		},
+	}
+	return cmd
+}
+
+// completeTemplateSources offers values of --source of template commands.
+var completeTemplateSources = cobra.FixedCompletions([]string{string(templates.SourceLocal), string(templates.SourceCluster)}, cobra.ShellCompDirectiveNoFileComp)
+
+// completeAdapterFormats offers values of --format of adapter export and import.
+var completeAdapterFormats = cobra.FixedCompletions([]string{string(adapters.FormatYAML), string(adapters.FormatJSON), string(adapters.FormatEnv)}, cobra.ShellCompDirectiveNoFileComp)
+
+// registerFlagCompletion sets the completion of a flag, failing loudly on typos in flag names.
+func registerFlagCompletion(cmd *cobra.Command, flag string, fn cobra.CompletionFunc) {
+	if err := cmd.RegisterFlagCompletionFunc(flag, fn); err != nil {
+		panic(err)
+	}
+}
+
+// completeBackupRequestNames offers BackupRequest names not given yet, for at most maxArgs arguments (-1 for any).
+func completeBackupRequestNames(f Factory, maxArgs int) cobra.CompletionFunc {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if maxArgs >= 0 && len(args) >= maxArgs || strings.Contains(toComplete, "=") {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		return without(backupRequestNames(f), args), cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+// completeBackupUpdate offers BackupRequest names, and the fields to set once no name matches.
+func completeBackupUpdate(f Factory) cobra.CompletionFunc {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if strings.Contains(toComplete, "=") {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+
+		var names []string
+		for _, name := range without(backupRequestNames(f), args) {
+			if strings.HasPrefix(name, toComplete) {
+				names = append(names, name)
+			}
+		}
+		if len(names) > 0 && !strings.HasPrefix(toComplete, "spec.") {
+			return names, cobra.ShellCompDirectiveNoFileComp
+		}
+
+		values := make([]string, 0, len(updateFields))
+		for _, field := range updateFields {
+			values = append(values, field+"=")
+		}
+		return values, cobra.ShellCompDirectiveNoFileComp | cobra.ShellCompDirectiveNoSpace
+	}
+}
+
+// completeAdapterNames offers adapter names for the first maxArgs arguments.
+func completeAdapterNames(f Factory, maxArgs int) cobra.CompletionFunc {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) >= maxArgs {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		return without(adapterNamesCached(f), args), cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+// completeDBSpec offers database types followed by @ while the type part of dbType@dbUri:dbPort/dbName is typed.
+func completeDBSpec(f Factory) cobra.CompletionFunc {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if strings.Contains(toComplete, "@") {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		var values []string
+		for _, dbType := range dbTypes(f) {
+			values = append(values, dbType+"@")
+		}
+		return values, cobra.ShellCompDirectiveNoFileComp | cobra.ShellCompDirectiveNoSpace
+	}
+}
+
+// completeTemplateNames offers names of local and cluster templates.
+func completeTemplateNames(f Factory) cobra.CompletionFunc {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) > 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+
+		var names []string
+		if dir, err := config.Dir(); err == nil {
+			local, _ := templates.LocalStore{Dir: filepath.Join(dir, "templates")}.List(cmd.Context())
+			for _, t := range local {
+				names = append(names, t.Name)
+			}
+		}
+		names = append(names, cachedValues(f, "templates", func(ctx context.Context, restConfig *rest.Config) ([]string, error) {
+			cfg, err := f.Config()
+			if err != nil {
+				return nil, err
+			}
+			clientset, err := kubernetes.NewForConfig(restConfig)
+			if err != nil {
+				return nil, err
+			}
+			store, err := clusterTemplateStore(ctx, clientset, cfg.Namespace)
+			if err != nil {
+				return nil, err
+			}
+			cluster, err := store.List(ctx)
+			if err != nil {
+				return nil, err
+			}
+			names := make([]string, 0, len(cluster))
+			for _, t := range cluster {
+				names = append(names, t.Name)
+			}
+			return names, nil
+		})...)
+		return uniqueSorted(names), cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+// completeConfigParameters offers configuration parameters followed by =.
+func completeConfigParameters(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 || strings.Contains(toComplete, "=") {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	values := make([]string, 0, len(configParameters))
+	for _, parameter := range configParameters {
+		values = append(values, parameter+"=")
+	}
+	return values, cobra.ShellCompDirectiveNoFileComp | cobra.ShellCompDirectiveNoSpace
+}
+
+// completeKubeContexts offers contexts of the kubeconfig.
+func completeKubeContexts(f Factory) cobra.CompletionFunc {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		kubeConfig, err := f.KubeConfig()
+		if err != nil {
+			cobra.CompDebugln(err.Error(), false)
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		contexts := make([]string, 0, len(kubeConfig.Contexts))
+		for name := range kubeConfig.Contexts {
+			contexts = append(contexts, name)
+		}
+		sort.Strings(contexts)
+		return contexts, cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+// backupRequestNames returns names of all BackupRequests.
+func backupRequestNames(f Factory) []string {
+	return cachedValues(f, "backuprequests", func(ctx context.Context, restConfig *rest.Config) ([]string, error) {
+		dynClient, err := dynamic.NewForConfig(restConfig)
+		if err != nil {
+			return nil, err
+		}
+		list, err := dynClient.Resource(gvr).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, err
+		}
+		names := make([]string, 0, len(list.Items))
+		for _, item := range list.Items {
+			names = append(names, item.GetName())
+		}
+		return names, nil
+	})
+}
+
+// adapterNamesCached returns names of adapters registered in the adapter ConfigMap.
+func adapterNamesCached(f Factory) []string {
+	return cachedValues(f, "adapters", func(ctx context.Context, restConfig *rest.Config) ([]string, error) {
+		clientset, err := kubernetes.NewForConfig(restConfig)
+		if err != nil {
+			return nil, err
+		}
+		configMap, err := getAdapterConfigMap(ctx, f, clientset)
+		if err != nil {
+			return nil, err
+		}
+		return adapters.Names(configMap.Data), nil
+	})
+}
+
+// dbTypes returns database types BackupRequests can use, well-known ones if no adapters are found.
+func dbTypes(f Factory) []string {
+	if names := adapterNamesCached(f); len(names) > 0 {
+		return names
+	}
+	types := make([]string, 0, len(defaultDBPorts))
+	for dbType := range defaultDBPorts {
+		types = append(types, dbType)
+	}
+	sort.Strings(types)
+	return types
+}
+
+// cachedValues returns values of a kind from the completion cache, calling fetch when they are outdated.
+// Completion does not run PersistentPreRunE, so the configuration is loaded here.
+// fetch gets a context and a client configuration bounded by completionTimeout. If it fails, outdated values are better than none.
+func cachedValues(f Factory, kind string, fetch func(context.Context, *rest.Config) ([]string, error)) []string {
+	cfg, err := f.Config()
+	if err != nil {
+		cobra.CompDebugln(err.Error(), false)
+		return nil
+	}
+	restConfig, err := f.RESTConfig()
+	if err != nil {
+		cobra.CompDebugln(err.Error(), false)
+		return nil
+	}
+	restConfig = rest.CopyConfig(restConfig)
+	restConfig.Timeout = completionTimeout
+	ctx, cancel := context.WithTimeout(context.Background(), completionTimeout)
+	defer cancel()
+
+	cache, err := completionCache()
+	if err != nil {
+		cobra.CompDebugln(err.Error(), false)
+		values, _ := fetch(ctx, restConfig)
+		return values
+	}
+	key := completion.Key(kind, restConfig.Host, cfg.Namespace, cfg.AdapterConfigMap)
+	if values, ok := cache.Get(key); ok {
+		return values
+	}
+
+	values, err := fetch(ctx, restConfig)
+	if err != nil {
+		cobra.CompDebugln(err.Error(), false)
+		values, _ = cache.Stale(key)
+		return values
+	}
+	if err := cache.Put(key, values); err != nil {
+		cobra.CompDebugln(err.Error(), false)
+	}
+	return values
+}
+
+// completionCache returns the cache in ~/.oiler/cache/completion.
+func completionCache() (completion.Cache, error) {
+	dir, err := config.Dir()
+	if err != nil {
+		return completion.Cache{}, err
+	}
+	return completion.Cache{Dir: filepath.Join(dir, "cache", "completion"), TTL: completion.DefaultTTL}, nil
+}
+
+// without returns values not in exclude.
+func without(values, exclude []string) []string {
+	var result []string
+	for _, v := range values {
+		if !slices.Contains(exclude, v) {
+			result = append(result, v)
+		}
+	}
+	return result
+}
+
+// uniqueSorted sorts values and drops duplicates.
+func uniqueSorted(values []string) []string {
+	sort.Strings(values)
+	return slices.Compact(values)
+}
@@ -2,92 +2,150 @@ package cmd
 
 import (
 	"encoding/json"
+	"fmt"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/oiler-backup/cli/internal/config"
 	"github.com/spf13/cobra"
 )
 
-// configCmd is top-level command for actions with configuration.
-var configCmd = &cobra.Command{
-	Use:   "config",
-	Short: "Display the current configuration",
-	Long:  `Display the current configuration loaded from the config file or flags.`,
+// configParameters are parameters config set accepts.
+var configParameters = []string{"kube-config-path", "namespace", "adapter-config-map", "retries", "retry-backoff"}
+
+// newConfigCmd returns the top-level command for actions with configuration.
+func newConfigCmd(f Factory) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "config",
+		Short: "Display the current configuration",
+		Long:  `Display the current configuration loaded from the config file or flags.`,
+	}
+
+	cmd.AddCommand(newConfigGetCmd(f))
+	cmd.AddCommand(newConfigSetCmd(f))
+	return cmd
 }
 
-// configSetCmd sets parameters to config.
-var configSetCmd = &cobra.Command{
-	Use:   "set <parameter>=<value>",
-	Short: "Set a configuration parameter",
-	Long:  `Set a configuration parameter in the config file.`,
-	Args:  cobra.ExactArgs(1),
-	Run: func(cmd *cobra.Command, args []string) {
-		stopFn := startSpinner("[1/2] Preparing")
-		arg := args[0]
-		parts := strings.SplitN(arg, "=", 2)
-		if len(parts) != 2 {
-			stopFn()
-			log.Fatalf("Invalid argument format. Use <parameter>=<value>")
-		}
+// newConfigSetCmd returns a command that sets parameters to config.
+func newConfigSetCmd(f Factory) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "set <parameter>=<value>",
+		Short: "Set a configuration parameter",
+		Long:  `Set a configuration parameter in the config file.`,
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			stopFn := startSpinner("[1/2] Preparing")
+			arg := args[0]
+			parts := strings.SplitN(arg, "=", 2)
+			if len(parts) != 2 {
+				stopFn()
+				return usageErrorf("invalid argument format, use <parameter>=<value>")
+			}
 
-		parameter := parts[0]
-		value := parts[1]
+			parameter := parts[0]
+			value := parts[1]
 
-		configPath := filepath.Join(os.Getenv("HOME"), ".oiler", ".config.json")
-		configData, err := os.ReadFile(configPath)
-		if err != nil {
-			stopFn()
-			log.Fatalf("Failed to read config file: %v", err)
-		}
+			configPath := filepath.Join(os.Getenv("HOME"), ".oiler", ".config.json")
+			configData, err := os.ReadFile(configPath)
+			if err != nil {
+				stopFn()
+				return fmt.Errorf("failed to read config file: %w", err)
+			}
 
-		if err := json.Unmarshal(configData, &cfg); err != nil {
-			stopFn()
-			log.Fatalf("Failed to unmarshal config: %v", err)
-		}
-
-		switch parameter {
-		case "kube-config-path":
-			cfg.KubeConfigPath = value
-		case "namespace":
-			cfg.Namespace = value
-		default:
-			stopFn()
-			log.Fatalf("Unknown parameter: %s", parameter)
-		}
-		stopFn()
+			var cfg config.Config
+			if err := json.Unmarshal(configData, &cfg); err != nil {
+				stopFn()
+				return fmt.Errorf("failed to unmarshal config: %w", err)
+			}
 
-		stopFn = startSpinner("[2/2] Writing result")
-		configData, err = json.MarshalIndent(cfg, "", "  ")
-		if err != nil {
+			switch parameter {
+			case "kube-config-path":
+				cfg.KubeConfigPath = value
+			case "namespace":
+				cfg.Namespace = value
+			case "adapter-config-map":
+				cfg.AdapterConfigMap = value
+			case "retries":
+				retries, err := strconv.Atoi(value)
+				if err != nil || retries < 0 {
+					stopFn()
+					return usageErrorf("invalid retries %q, use a number from 0", value)
+				}
+				cfg.Retries = &retries
+			case "retry-backoff":
+				if backoff, err := time.ParseDuration(value); err != nil || backoff < 0 {
+					stopFn()
+					return usageErrorf("invalid retry backoff %q, use a duration like 500ms", value)
+				}
+				cfg.RetryBackoff = value
+			default:
+				stopFn()
+				return usageErrorf("unknown parameter: %s", parameter)
+			}
 			stopFn()
-			log.Fatalf("Failed to marshal config: %v", err)
-		}
 
-		if err := os.WriteFile(configPath, configData, 0644); err != nil {
+			stopFn = startSpinner("[2/2] Writing result")
+			configData, err = json.MarshalIndent(cfg, "", "  ")
+			if err != nil {
+				stopFn()
+				return fmt.Errorf("failed to marshal config: %w", err)
+			}
+
+			if err := os.WriteFile(configPath, configData, 0644); err != nil {
+				stopFn()
+				return fmt.Errorf("failed to write config file: %w", err)
+			}
+
 			stopFn()
-			log.Fatalf("Failed to write config file: %v", err)
-		}
+			log.Info("Successfully updated config")
+			return nil
+		},
+	}
+
+	cmd.ValidArgsFunction = completeConfigParameters
+	return cmd
+}
+
+// newConfigGetCmd returns a command that shows current configuration.
+func newConfigGetCmd(f Factory) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "get",
+		Short: "Display the current configuration",
+		Long:  `Display the current configuration loaded from the config file or flags.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := f.Config()
+			if err != nil {
+				return err
+			}
 
-		stopFn()
-		log.Info("Successfully updated config")
-	},
+			t := table.NewWriter()
+			t.SetOutputMirror(cmd.OutOrStdout())
+			t.SetStyle(table.StyleLight)
+			t.AppendHeader(table.Row{"#", "Parameter Name", "Value"})
+			t.AppendRow(table.Row{1, "kube_config_path", cfg.KubeConfigPath})
+			t.AppendSeparator()
+			t.AppendRow(table.Row{2, "namespace", cfg.Namespace})
+			t.AppendSeparator()
+			t.AppendRow(table.Row{3, "adapter_config_map", cfg.AdapterConfigMap})
+			t.AppendSeparator()
+			t.AppendRow(table.Row{4, "retries", retriesValue(cfg.Retries)})
+			t.AppendSeparator()
+			t.AppendRow(table.Row{5, "retry_backoff", cfg.RetryBackoff})
+			t.Render()
+			return nil
+		},
+	}
+	return cmd
 }
 
-// configGetCmd shows current configuration.
-var configGetCmd = &cobra.Command{
-	Use:   "get",
-	Short: "Display the current configuration",
-	Long:  `Display the current configuration loaded from the config file or flags.`,
-	Run: func(cmd *cobra.Command, args []string) {
-		t := table.NewWriter()
-		t.SetOutputMirror(os.Stdout)
-		t.SetStyle(table.StyleLight)
-		t.AppendHeader(table.Row{"#", "Parameter Name", "Value"})
-		t.AppendRow(table.Row{1, "kube_config_path", cfg.KubeConfigPath})
-		t.AppendSeparator()
-		t.AppendRow(table.Row{2, "namespace", cfg.Namespace})
-		t.Render()
-	},
+// retriesValue formats the retries parameter, empty if it is not set.
+func retriesValue(retries *int) string {
+	if retries == nil {
+		return ""
+	}
+	return strconv.Itoa(*retries)
 }
@@ -0,0 +1,581 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/oiler-backup/cli/internal/adapters"
+	"github.com/oiler-backup/cli/internal/doctor"
+	"github.com/oiler-backup/cli/internal/health"
+	backupv1 "github.com/oiler-backup/core/core/api/v1"
+	"github.com/spf13/cobra"
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+)
+
+// Checks of doctor, as named in its findings.
+const (
+	checkCRD            = "crd"
+	checkOperator       = "operator"
+	checkWebhooks       = "webhooks"
+	checkAdapterConfig  = "adapter-configmap"
+	checkAdapters       = "adapters"
+	checkBackupRequests = "backuprequests"
+)
+
+// Output formats of doctor.
+const (
+	doctorFormatTable = "table"
+	doctorFormatJSON  = "json"
+)
+
+// restartWarning is the number of container restarts doctor warns about.
+const restartWarning = 5
+
+// badWaitingReasons are reasons of waiting containers that do not resolve by themselves.
+var badWaitingReasons = []string{"CrashLoopBackOff", "ImagePullBackOff", "ErrImagePull", "CreateContainerConfigError", "InvalidImageName"}
+
+// doctorFlags are the flags of doctor.
+type doctorFlags struct {
+	format     string
+	probe      adapterProbeFlags
+	skipProbes bool
+	stuckAfter time.Duration
+}
+
+// newDoctorCmd returns a command that diagnoses the operator installation.
+func newDoctorCmd(f Factory) *cobra.Command {
+	flags := &doctorFlags{probe: defaultProbeFlags}
+	cmd := &cobra.Command{
+		Use:   "doctor",
+		Short: "Diagnose the operator installation",
+		Long: `Diagnose the Oiler operator installation and report findings with severities and hints.
+
+Checks that the BackupRequest CRD serves the version oiler-cli was built for, the operator
+Deployment is ready, admission webhooks of the operator have ready endpoints, the adapter
+ConfigMap is consistent with BackupRequests, adapters are reachable, and no BackupRequest
+failed or is stuck without being processed by the operator.
+
+Exits with code 1 if any finding is an error. Use --format json for a machine-readable report.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			stopFn := startSpinner("[1/7] Preparing")
+			switch flags.format {
+			case doctorFormatTable, doctorFormatJSON:
+			default:
+				stopFn()
+				return usageErrorf("unknown --format value %q, use %s or %s", flags.format, doctorFormatTable, doctorFormatJSON)
+			}
+			switch flags.probe.via {
+			case probeViaDirect, probeViaProxy, probeViaPod:
+			default:
+				stopFn()
+				return usageErrorf("unknown --via value %q, use %s, %s or %s", flags.probe.via, probeViaDirect, probeViaProxy, probeViaPod)
+			}
+
+			cfg, err := f.Config()
+			if err != nil {
+				stopFn()
+				return err
+			}
+			clientset, err := f.ClientSet()
+			if err != nil {
+				stopFn()
+				return fmt.Errorf("failed to get client: %w", err)
+			}
+			dynClient, err := f.DynamicClient()
+			if err != nil {
+				stopFn()
+				return fmt.Errorf("failed to get client: %w", err)
+			}
+			stopFn()
+
+			d := &doctorRun{f: f, flags: flags, clientset: clientset, dynClient: dynClient, namespace: cfg.Namespace, report: doctor.NewReport()}
+
+			stopFn = startSpinner("[2/7] Checking BackupRequest CRD")
+			crdServed := d.checkCRD(ctx)
+			stopFn()
+
+			stopFn = startSpinner("[3/7] Checking operator Deployment")
+			d.checkOperator(ctx)
+			stopFn()
+
+			stopFn = startSpinner("[4/7] Checking webhooks")
+			d.checkWebhooks(ctx)
+			stopFn()
+
+			stopFn = startSpinner("[5/7] Checking BackupRequests")
+			var backupRequests []backupv1.BackupRequest
+			if crdServed {
+				backupRequests = d.checkBackupRequests(ctx)
+			} else {
+				d.report.Add(checkBackupRequests, doctor.Info, "Skipped, the BackupRequest CRD is not usable", "")
+			}
+			stopFn()
+
+			stopFn = startSpinner("[6/7] Checking adapter ConfigMap")
+			entries := d.checkAdapterConfigMap(ctx, backupRequests, crdServed)
+			stopFn()
+
+			stopFn = startSpinner("[7/7] Probing adapters")
+			if flags.skipProbes {
+				d.report.Add(checkAdapters, doctor.Info, "Skipped, --skip-probes is set", "")
+			} else {
+				d.checkAdapters(ctx, entries)
+			}
+			stopFn()
+
+			if err := printDoctorReport(cmd, d.report, flags.format); err != nil {
+				return err
+			}
+			if n := d.report.Count(doctor.Error); n > 0 {
+				return fmt.Errorf("doctor found %d errors", n)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&flags.format, "format", doctorFormatTable, "Output format: table or json")
+	cmd.Flags().StringVar(&flags.probe.via, "via", probeViaProxy, "How to reach adapters: direct, proxy (API-server service proxy) or pod (temporary pod)")
+	cmd.Flags().DurationVar(&flags.probe.timeout, "timeout", defaultProbeFlags.timeout, "Timeout for a single adapter probe")
+	cmd.Flags().BoolVar(&flags.skipProbes, "skip-probes", false, "Do not probe adapters")
+	cmd.Flags().DurationVar(&flags.stuckAfter, "stuck-after", 10*time.Minute, "Age after which a BackupRequest the operator has not processed is reported as stuck")
+	registerFlagCompletion(cmd, "format", cobra.FixedCompletions([]string{doctorFormatTable, doctorFormatJSON}, cobra.ShellCompDirectiveNoFileComp))
+	registerFlagCompletion(cmd, "via", cobra.FixedCompletions([]string{probeViaDirect, probeViaProxy, probeViaPod}, cobra.ShellCompDirectiveNoFileComp))
+	return cmd
+}
+
+// A doctorRun holds the clients and the report of a doctor invocation.
+type doctorRun struct {
+	f         Factory
+	flags     *doctorFlags
+	clientset kubernetes.Interface
+	dynClient dynamic.Interface
+	namespace string
+	report    *doctor.Report
+}
+
+// checkCRD checks that the BackupRequest CRD is established and serves the version of the CLI.
+// It reports whether BackupRequests can be used.
+func (d *doctorRun) checkCRD(ctx context.Context) bool {
+	crd, err := d.dynClient.Resource(crdGVR).Get(ctx, backupRequestCRD, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		d.report.Add(checkCRD, doctor.Error, fmt.Sprintf("CRD %s is not installed", backupRequestCRD),
+			"Install the Oiler operator, the CRD is part of its installation.")
+		return false
+	}
+	if err != nil {
+		// Users without access to CRDs may still be able to use BackupRequests.
+		d.report.Add(checkCRD, doctor.Warning, fmt.Sprintf("Failed to get CRD %s: %v", backupRequestCRD, err), hint(err))
+		return true
+	}
+
+	served, storage := crdVersions(crd)
+	usable := true
+	if !slices.Contains(served, gvr.Version) {
+		d.report.Add(checkCRD, doctor.Error,
+			fmt.Sprintf("CRD %s does not serve %s oiler-cli was built for, it serves %s", backupRequestCRD, backupv1.GroupVersion, strings.Join(orNone(served), ", ")),
+			"Install an operator and oiler-cli release that match.")
+		usable = false
+	} else if storage != "" && storage != gvr.Version {
+		d.report.Add(checkCRD, doctor.Info,
+			fmt.Sprintf("CRD %s stores version %s, oiler-cli uses %s", backupRequestCRD, storage, gvr.Version),
+			"BackupRequests are converted by the API server; upgrade oiler-cli if fields are missing.")
+	}
+
+	if !crdEstablished(crd) {
+		d.report.Add(checkCRD, doctor.Error, fmt.Sprintf("CRD %s is not established", backupRequestCRD),
+			fmt.Sprintf("Inspect its conditions with kubectl describe crd %s.", backupRequestCRD))
+		return false
+	}
+	if usable {
+		d.report.Add(checkCRD, doctor.OK, fmt.Sprintf("CRD %s serves %s", backupRequestCRD, strings.Join(served, ", ")), "")
+	}
+	return usable
+}
+
+// crdVersions returns the versions crd serves and the one it stores objects in.
+func crdVersions(crd *unstructured.Unstructured) ([]string, string) {
+	versions, _, _ := unstructured.NestedSlice(crd.Object, "spec", "versions")
+	var served []string
+	storage := ""
+	for _, v := range versions {
+		version, ok := v.(map[string]any)
+		if !ok {
+			continue
+		}
+		name, _, _ := unstructured.NestedString(version, "name")
+		if isServed, _, _ := unstructured.NestedBool(version, "served"); isServed {
+			served = append(served, name)
+		}
+		if isStorage, _, _ := unstructured.NestedBool(version, "storage"); isStorage {
+			storage = name
+		}
+	}
+	return served, storage
+}
+
+// crdEstablished reports whether crd has the Established condition.
+func crdEstablished(crd *unstructured.Unstructured) bool {
+	conditions, _, _ := unstructured.NestedSlice(crd.Object, "status", "conditions")
+	for _, c := range conditions {
+		condition, ok := c.(map[string]any)
+		if !ok {
+			continue
+		}
+		if condition["type"] == "Established" {
+			return condition["status"] == "True"
+		}
+	}
+	return false
+}
+
+// orNone returns names or a single "none".
+func orNone(names []string) []string {
+	if len(names) == 0 {
+		return []string{"none"}
+	}
+	return names
+}
+
+// checkOperator checks that the operator Deployment is ready and its pods are not failing.
+func (d *doctorRun) checkOperator(ctx context.Context) {
+	deployments, err := d.clientset.AppsV1().Deployments(d.namespace).List(ctx, metav1.ListOptions{LabelSelector: adapters.OperatorSelector})
+	if err != nil {
+		d.report.Add(checkOperator, doctor.Error, fmt.Sprintf("Failed to list Deployments in %s: %v", d.namespace, err), hint(err))
+		return
+	}
+	if len(deployments.Items) == 0 {
+		d.report.Add(checkOperator, doctor.Error,
+			fmt.Sprintf("No operator Deployment (%s) in namespace %s", adapters.OperatorSelector, d.namespace),
+			"Install the operator, or set its namespace with oiler-cli config set namespace=<namespace>.")
+		return
+	}
+
+	for _, deployment := range deployments.Items {
+		d.checkDeployment(ctx, &deployment)
+	}
+}
+
+// checkDeployment checks readiness and pods of the operator deployment.
+func (d *doctorRun) checkDeployment(ctx context.Context, deployment *appsv1.Deployment) {
+	name := deployment.Namespace + "/" + deployment.Name
+	logsHint := fmt.Sprintf("Inspect the operator with kubectl describe deployment -n %s %s and kubectl logs -n %s deployment/%s.",
+		deployment.Namespace, deployment.Name, deployment.Namespace, deployment.Name)
+	want := int32(1)
+	if deployment.Spec.Replicas != nil {
+		want = *deployment.Spec.Replicas
+	}
+	ready := deployment.Status.ReadyReplicas
+	switch {
+	case want == 0:
+		d.report.Add(checkOperator, doctor.Error, fmt.Sprintf("Deployment %s is scaled to zero", name),
+			fmt.Sprintf("Scale it up with kubectl scale deployment -n %s %s --replicas=1.", deployment.Namespace, deployment.Name))
+	case ready == 0:
+		d.report.Add(checkOperator, doctor.Error, fmt.Sprintf("Deployment %s has no ready replicas of %d", name, want), logsHint)
+	case ready < want:
+		d.report.Add(checkOperator, doctor.Warning, fmt.Sprintf("Deployment %s has %d of %d replicas ready", name, ready, want), logsHint)
+	default:
+		d.report.Add(checkOperator, doctor.OK, fmt.Sprintf("Deployment %s has %d of %d replicas ready", name, ready, want), "")
+	}
+
+	selector, err := metav1.LabelSelectorAsSelector(deployment.Spec.Selector)
+	if err != nil {
+		return
+	}
+	pods, err := d.clientset.CoreV1().Pods(deployment.Namespace).List(ctx, metav1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		log.Debugf("Skipping operator pods: %v", err)
+		return
+	}
+	for _, pod := range pods.Items {
+		for _, status := range pod.Status.ContainerStatuses {
+			if waiting := status.State.Waiting; waiting != nil && slices.Contains(badWaitingReasons, waiting.Reason) {
+				d.report.Add(checkOperator, doctor.Error,
+					fmt.Sprintf("Container %s of pod %s is in %s", status.Name, pod.Name, waiting.Reason),
+					fmt.Sprintf("Inspect it with kubectl describe pod -n %s %s and kubectl logs -n %s %s -c %s --previous.",
+						pod.Namespace, pod.Name, pod.Namespace, pod.Name, status.Name))
+			} else if status.RestartCount >= restartWarning {
+				d.report.Add(checkOperator, doctor.Warning,
+					fmt.Sprintf("Container %s of pod %s restarted %d times", status.Name, pod.Name, status.RestartCount),
+					fmt.Sprintf("Inspect it with kubectl logs -n %s %s -c %s --previous.", pod.Namespace, pod.Name, status.Name))
+			}
+		}
+	}
+}
+
+// A doctorWebhook is an admission webhook of either kind, reduced to what doctor checks.
+type doctorWebhook struct {
+	kind          string
+	configuration string
+	name          string
+	clientConfig  admissionregistrationv1.WebhookClientConfig
+	failurePolicy *admissionregistrationv1.FailurePolicyType
+}
+
+// checkWebhooks checks that admission webhooks for BackupRequests point at services with ready endpoints.
+func (d *doctorRun) checkWebhooks(ctx context.Context) {
+	var webhooks []doctorWebhook
+	validating, err := d.clientset.AdmissionregistrationV1().ValidatingWebhookConfigurations().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		d.report.Add(checkWebhooks, doctor.Warning, fmt.Sprintf("Failed to list ValidatingWebhookConfigurations: %v", err), hint(err))
+		return
+	}
+	for _, c := range validating.Items {
+		for _, w := range c.Webhooks {
+			if rulesCoverBackups(w.Rules) {
+				webhooks = append(webhooks, doctorWebhook{"validating", c.Name, w.Name, w.ClientConfig, w.FailurePolicy})
+			}
+		}
+	}
+	mutating, err := d.clientset.AdmissionregistrationV1().MutatingWebhookConfigurations().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		d.report.Add(checkWebhooks, doctor.Warning, fmt.Sprintf("Failed to list MutatingWebhookConfigurations: %v", err), hint(err))
+		return
+	}
+	for _, c := range mutating.Items {
+		for _, w := range c.Webhooks {
+			if rulesCoverBackups(w.Rules) {
+				webhooks = append(webhooks, doctorWebhook{"mutating", c.Name, w.Name, w.ClientConfig, w.FailurePolicy})
+			}
+		}
+	}
+
+	if len(webhooks) == 0 {
+		d.report.Add(checkWebhooks, doctor.Info, fmt.Sprintf("No admission webhooks are registered for %s", gvr.Group), "")
+		return
+	}
+	for _, w := range webhooks {
+		d.checkWebhook(ctx, w)
+	}
+}
+
+// rulesCoverBackups reports whether webhook rules match the API group of BackupRequests.
+func rulesCoverBackups(rules []admissionregistrationv1.RuleWithOperations) bool {
+	for _, r := range rules {
+		if slices.Contains(r.APIGroups, gvr.Group) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkWebhook checks that the service of w exists and has ready endpoints.
+func (d *doctorRun) checkWebhook(ctx context.Context, w doctorWebhook) {
+	name := fmt.Sprintf("%s webhook %s", w.kind, w.name)
+	// Requests fail while a webhook with the default policy Fail is down, otherwise they only skip it.
+	severity := doctor.Error
+	if w.failurePolicy != nil && *w.failurePolicy == admissionregistrationv1.Ignore {
+		severity = doctor.Warning
+	}
+
+	if len(w.clientConfig.CABundle) == 0 {
+		d.report.Add(checkWebhooks, doctor.Warning, fmt.Sprintf("The %s has no caBundle", name),
+			"Check that the CA is injected, e.g. that cert-manager and its cainjector are running.")
+	}
+
+	service := w.clientConfig.Service
+	if service == nil {
+		d.report.Add(checkWebhooks, doctor.Info, fmt.Sprintf("The %s calls a URL, it is not checked", name), "")
+		return
+	}
+	ref := service.Namespace + "/" + service.Name
+	if _, err := d.clientset.CoreV1().Services(service.Namespace).Get(ctx, service.Name, metav1.GetOptions{}); err != nil {
+		if apierrors.IsNotFound(err) {
+			d.report.Add(checkWebhooks, severity, fmt.Sprintf("Service %s of the %s does not exist", ref, name),
+				fmt.Sprintf("Reinstall the operator or delete the stale configuration %s.", w.configuration))
+		} else {
+			d.report.Add(checkWebhooks, doctor.Warning, fmt.Sprintf("Failed to get service %s of the %s: %v", ref, name, err), hint(err))
+		}
+		return
+	}
+
+	endpointSlices, err := d.clientset.DiscoveryV1().EndpointSlices(service.Namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: discoveryv1.LabelServiceName + "=" + service.Name,
+	})
+	if err != nil {
+		d.report.Add(checkWebhooks, doctor.Warning, fmt.Sprintf("Failed to list endpoints of service %s: %v", ref, err), hint(err))
+		return
+	}
+	ready := 0
+	for _, slice := range endpointSlices.Items {
+		for _, endpoint := range slice.Endpoints {
+			if endpoint.Conditions.Ready == nil || *endpoint.Conditions.Ready {
+				ready++
+			}
+		}
+	}
+	if ready == 0 {
+		d.report.Add(checkWebhooks, severity, fmt.Sprintf("Service %s of the %s has no ready endpoints", ref, name),
+			"The webhook is served by the operator, check the operator Deployment.")
+		return
+	}
+	d.report.Add(checkWebhooks, doctor.OK, fmt.Sprintf("Service %s of the %s has %d ready endpoints", ref, name, ready), "")
+}
+
+// checkBackupRequests reports failed BackupRequests and those the operator has not processed, and returns all of them.
+func (d *doctorRun) checkBackupRequests(ctx context.Context) []backupv1.BackupRequest {
+	backupRequests, err := listBackupRequests(ctx, d.dynClient, metav1.ListOptions{})
+	if err != nil {
+		d.report.Add(checkBackupRequests, doctor.Error, err.Error(), hint(err))
+		return nil
+	}
+
+	problems := 0
+	now := time.Now()
+	for _, br := range backupRequests {
+		switch {
+		case strings.Contains(strings.ToLower(br.Status.Status), "fail"):
+			problems++
+			d.report.Add(checkBackupRequests, doctor.Warning, fmt.Sprintf("BackupRequest %s: last backup failed", br.Name),
+				fmt.Sprintf("Inspect its jobs with oiler-cli ui, or kubectl get jobs -n %s.", d.namespace))
+		case br.Status.Status == "" && br.Status.CronJobData.Name == "" && !br.CreationTimestamp.IsZero() &&
+			now.Sub(br.CreationTimestamp.Time) > d.flags.stuckAfter:
+			problems++
+			d.report.Add(checkBackupRequests, doctor.Warning,
+				fmt.Sprintf("BackupRequest %s was created %s ago and is not processed by the operator", br.Name, now.Sub(br.CreationTimestamp.Time).Round(time.Minute)),
+				"Check the operator Deployment and its logs.")
+		}
+	}
+	if problems == 0 {
+		d.report.Add(checkBackupRequests, doctor.OK, fmt.Sprintf("%d BackupRequests, none failed or stuck", len(backupRequests)), "")
+	}
+	return backupRequests
+}
+
+// checkAdapterConfigMap checks adapter URLs and that every database type of backupRequests has an adapter.
+// It returns the adapters with valid URLs.
+func (d *doctorRun) checkAdapterConfigMap(ctx context.Context, backupRequests []backupv1.BackupRequest, checkUsage bool) map[string]string {
+	configMap, err := getAdapterConfigMap(ctx, d.f, d.clientset)
+	if err != nil {
+		d.report.Add(checkAdapterConfig, doctor.Error, err.Error(), hint(err))
+		return nil
+	}
+	ref := configMap.Namespace + "/" + configMap.Name
+	if len(configMap.Data) == 0 {
+		d.report.Add(checkAdapterConfig, doctor.Warning, fmt.Sprintf("Adapter ConfigMap %s has no adapters", ref),
+			"Register adapters with oiler-cli adapter add.")
+	}
+
+	problems := 0
+	valid := make(map[string]string, len(configMap.Data))
+	for _, name := range sortedKeys(configMap.Data) {
+		if _, err := health.ParseURL(configMap.Data[name]); err != nil {
+			problems++
+			d.report.Add(checkAdapterConfig, doctor.Error, fmt.Sprintf("Adapter %s has an invalid URL: %v", name, err),
+				"Fix it with oiler-cli adapter edit.")
+			continue
+		}
+		valid[name] = configMap.Data[name]
+	}
+
+	if checkUsage {
+		users := map[string][]string{}
+		for _, br := range backupRequests {
+			users[br.Spec.DbSpec.DbType] = append(users[br.Spec.DbSpec.DbType], br.Name)
+		}
+		for _, dbType := range sortedKeys(users) {
+			if _, ok := configMap.Data[dbType]; !ok {
+				problems++
+				d.report.Add(checkAdapterConfig, doctor.Error,
+					fmt.Sprintf("No adapter for database type %s used by BackupRequests %s", dbType, strings.Join(users[dbType], ", ")),
+					fmt.Sprintf("Register one with oiler-cli adapter add %s <url>.", dbType))
+			}
+		}
+		for _, name := range sortedKeys(configMap.Data) {
+			if _, ok := users[name]; !ok {
+				d.report.Add(checkAdapterConfig, doctor.Info, fmt.Sprintf("Adapter %s is not used by any BackupRequest", name), "")
+			}
+		}
+	}
+
+	if problems == 0 && len(configMap.Data) > 0 {
+		d.report.Add(checkAdapterConfig, doctor.OK, fmt.Sprintf("Adapter ConfigMap %s has %d valid adapters", ref, len(configMap.Data)), "")
+	}
+	return valid
+}
+
+// checkAdapters probes adapters concurrently.
+func (d *doctorRun) checkAdapters(ctx context.Context, entries map[string]string) {
+	names := sortedKeys(entries)
+	results := make([]health.Result, len(names))
+	var wg sync.WaitGroup
+	for i, name := range names {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = probeAdapter(ctx, d.clientset, d.namespace, entries[name], d.flags.probe)
+		}()
+	}
+	wg.Wait()
+
+	for i, name := range names {
+		res := results[i]
+		if !res.Healthy() {
+			message := fmt.Sprintf("Adapter %s at %s is %s", name, entries[name], strings.ToLower(res.Status))
+			if res.Err != nil {
+				message += ": " + res.Err.Error()
+			}
+			d.report.Add(checkAdapters, doctor.Error, message,
+				fmt.Sprintf("Check the adapter Deployment, or probe it another way with oiler-cli adapter health %s --via=%s.", name, otherVia(d.flags.probe.via)))
+			continue
+		}
+		message := fmt.Sprintf("Adapter %s is %s", name, strings.ToLower(res.Status))
+		if res.Version != "" {
+			message += ", version " + res.Version
+		}
+		d.report.Add(checkAdapters, doctor.OK, message, "")
+	}
+}
+
+// otherVia suggests a way to reach adapters other than via.
+func otherVia(via string) string {
+	if via == probeViaPod {
+		return probeViaProxy
+	}
+	return probeViaPod
+}
+
+// sortedKeys returns the keys of m in order.
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// printDoctorReport prints report as a table or as JSON, as format requests.
+func printDoctorReport(cmd *cobra.Command, report *doctor.Report, format string) error {
+	if format == doctorFormatJSON {
+		encoder := json.NewEncoder(cmd.OutOrStdout())
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(report)
+	}
+
+	t := table.NewWriter()
+	t.SetOutputMirror(cmd.OutOrStdout())
+	t.SetStyle(table.StyleLight)
+	t.AppendHeader(table.Row{"#", "Check", "Severity", "Finding", "Hint"})
+	for i, finding := range report.Findings {
+		t.AppendRow(table.Row{i + 1, finding.Check, strings.ToUpper(finding.Severity.String()), finding.Message, finding.Hint})
+	}
+	t.AppendFooter(table.Row{"", "", "", "ERRORS / WARNINGS", fmt.Sprintf("%d / %d", report.Count(doctor.Error), report.Count(doctor.Warning))})
+	t.Render()
+	return nil
+}
@@ -0,0 +1,178 @@
+package cmd
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/oiler-backup/cli/internal/health"
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/utils/ptr"
+)
+
+// installOperator adds the CRD, a ready operator Deployment and its webhook to the fake cluster of f.
+func installOperator(t *testing.T, f *fakeFactory) {
+	t.Helper()
+	ctx := t.Context()
+
+	crd := &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": "apiextensions.k8s.io/v1",
+		"kind":       "CustomResourceDefinition",
+		"metadata":   map[string]any{"name": backupRequestCRD},
+		"spec": map[string]any{
+			"group":    gvr.Group,
+			"versions": []any{map[string]any{"name": gvr.Version, "served": true, "storage": true}},
+		},
+		"status": map[string]any{
+			"conditions": []any{map[string]any{"type": "Established", "status": "True"}},
+		},
+	}}
+	if _, err := f.dynClient.Resource(crdGVR).Create(ctx, crd, metav1.CreateOptions{}); err != nil {
+		t.Fatalf("failed to create CRD: %v", err)
+	}
+
+	labels := map[string]string{"control-plane": "controller-manager"}
+	creates := []func() error{
+		func() error {
+			_, err := f.clientset.AppsV1().Deployments(testNamespace).Create(ctx, &appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Name: "oiler-controller-manager", Namespace: testNamespace, Labels: labels},
+				Spec: appsv1.DeploymentSpec{
+					Replicas: ptr.To[int32](1),
+					Selector: &metav1.LabelSelector{MatchLabels: labels},
+				},
+				Status: appsv1.DeploymentStatus{ReadyReplicas: 1},
+			}, metav1.CreateOptions{})
+			return err
+		},
+		func() error {
+			_, err := f.clientset.CoreV1().Services(testNamespace).Create(ctx, &corev1.Service{
+				ObjectMeta: metav1.ObjectMeta{Name: "oiler-webhook-service", Namespace: testNamespace},
+			}, metav1.CreateOptions{})
+			return err
+		},
+		func() error {
+			_, err := f.clientset.DiscoveryV1().EndpointSlices(testNamespace).Create(ctx, &discoveryv1.EndpointSlice{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "oiler-webhook-service-abcde",
+					Namespace: testNamespace,
+					Labels:    map[string]string{discoveryv1.LabelServiceName: "oiler-webhook-service"},
+				},
+				Endpoints: []discoveryv1.Endpoint{{Addresses: []string{"10.0.0.7"}, Conditions: discoveryv1.EndpointConditions{Ready: ptr.To(true)}}},
+			}, metav1.CreateOptions{})
+			return err
+		},
+		func() error {
+			_, err := f.clientset.AdmissionregistrationV1().ValidatingWebhookConfigurations().Create(ctx, &admissionregistrationv1.ValidatingWebhookConfiguration{
+				ObjectMeta: metav1.ObjectMeta{Name: "oiler-validating-webhook-configuration"},
+				Webhooks: []admissionregistrationv1.ValidatingWebhook{{
+					Name: "vbackuprequest.oiler.backup",
+					ClientConfig: admissionregistrationv1.WebhookClientConfig{
+						Service:  &admissionregistrationv1.ServiceReference{Namespace: testNamespace, Name: "oiler-webhook-service"},
+						CABundle: []byte("ca"),
+					},
+					Rules: []admissionregistrationv1.RuleWithOperations{{
+						Rule: admissionregistrationv1.Rule{APIGroups: []string{gvr.Group}, APIVersions: []string{gvr.Version}, Resources: []string{gvr.Resource}},
+					}},
+				}},
+			}, metav1.CreateOptions{})
+			return err
+		},
+	}
+	for _, create := range creates {
+		if err := create(); err != nil {
+			t.Fatalf("failed to install operator: %v", err)
+		}
+	}
+}
+
+func TestDoctor(t *testing.T) {
+	f := newFakeFactory(t, testAdapters(), testAdapterUsers()...)
+	installOperator(t, f)
+	out, err := runCmd(t, f, "doctor", "--skip-probes")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	assertGolden(t, "doctor", out)
+}
+
+func TestDoctorBroken(t *testing.T) {
+	f := newFakeFactory(t, map[string]string{"mysql": "ftp://mysql-adapter:21"}, testAdapterUsers()...)
+	out, err := runCmd(t, f, "doctor", "--skip-probes")
+	assertExitCode(t, err, exitError)
+	assertGolden(t, "doctor_broken", out)
+}
+
+func TestDoctorJSON(t *testing.T) {
+	stuck := newBackupRequest("audit", "postgres", "0 4 * * *", "", nil)
+	stuck.CreationTimestamp = metav1.NewTime(time.Now().Add(-time.Hour))
+	f := newFakeFactory(t, testAdapters(), stuck)
+	installOperator(t, f)
+	deployment, err := f.clientset.AppsV1().Deployments(testNamespace).Get(t.Context(), "oiler-controller-manager", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	deployment.Spec.Replicas = ptr.To[int32](2)
+	if _, err := f.clientset.AppsV1().Deployments(testNamespace).Update(t.Context(), deployment, metav1.UpdateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := runCmd(t, f, "doctor", "--skip-probes", "--format", "json")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var report struct {
+		Findings []struct {
+			Check    string `json:"check"`
+			Severity string `json:"severity"`
+			Message  string `json:"message"`
+			Hint     string `json:"hint"`
+		} `json:"findings"`
+		Counts map[string]int `json:"counts"`
+	}
+	if err := json.Unmarshal([]byte(out), &report); err != nil {
+		t.Fatalf("invalid JSON report %q: %v", out, err)
+	}
+	if report.Counts["warning"] != 2 || report.Counts["error"] != 0 {
+		t.Errorf("counts = %v, want 2 warnings and no errors", report.Counts)
+	}
+
+	var messages []string
+	for _, finding := range report.Findings {
+		if finding.Severity == "warning" {
+			messages = append(messages, finding.Check+": "+finding.Message)
+		}
+	}
+	want := []string{
+		"operator: Deployment oiler-backup-system/oiler-controller-manager has 1 of 2 replicas ready",
+		"backuprequests: BackupRequest audit was created 1h0m0s ago and is not processed by the operator",
+	}
+	if strings.Join(messages, "\n") != strings.Join(want, "\n") {
+		t.Errorf("warnings = %q, want %q", messages, want)
+	}
+}
+
+func TestDoctorProbe(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(health.VersionHeader, "1.4.0")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	f := newFakeFactory(t, map[string]string{"postgres": server.URL, "mysql": "http://127.0.0.1:1"})
+	installOperator(t, f)
+	out, err := runCmd(t, f, "doctor", "--via", "direct", "--timeout", "2s")
+	assertExitCode(t, err, exitError)
+	for _, want := range []string{"Adapter postgres is serving, version 1.4.0", "Adapter mysql at http://127.0.0.1:1 is"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output does not contain %q:\n%s", want, out)
+		}
+	}
+}
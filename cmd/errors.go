@@ -0,0 +1,179 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+
+	"github.com/jedib0t/go-pretty/v6/text"
+	"github.com/oiler-backup/cli/internal/templates"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+// Exit codes of oiler-cli. They are part of the interface scripts rely on, keep them stable.
+const (
+	exitOK          = 0
+	exitError       = 1
+	exitWaitFailed  = 2
+	exitTimeout     = 3
+	exitUsage       = 4
+	exitNotFound    = 5
+	exitForbidden   = 6
+	exitConflict    = 7
+	exitUnavailable = 8
+	// exitInterrupted follows the shell convention of 128 + SIGINT.
+	exitInterrupted = 130
+)
+
+// exitCodesHelp documents exit codes in the help of the root command.
+const exitCodesHelp = `Exit codes:
+  0    success
+  1    other errors
+  2    backup wait condition can not be met anymore
+  3    timeout
+  4    invalid arguments, flags or input
+  5    object not found
+  6    not authenticated or not allowed by RBAC
+  7    conflict: object exists, was changed concurrently or is in use
+  8    cluster not reachable
+  130  interrupted by Ctrl-C or SIGTERM`
+
+// A cliError is an error with an exit code and an optional hint for the user.
+type cliError struct {
+	code int
+	err  error
+	hint string
+}
+
+// Error implements error.
+func (e *cliError) Error() string {
+	return e.err.Error()
+}
+
+// Unwrap returns the underlying error.
+func (e *cliError) Unwrap() error {
+	return e.err
+}
+
+// usageErrorf reports invalid arguments, flags or input.
+func usageErrorf(format string, args ...any) error {
+	return &cliError{code: exitUsage, err: fmt.Errorf(format, args...)}
+}
+
+// notFoundErrorf reports a missing object.
+func notFoundErrorf(format string, args ...any) error {
+	return &cliError{code: exitNotFound, err: fmt.Errorf(format, args...)}
+}
+
+// conflictErrorf reports an object that exists already or is in use.
+func conflictErrorf(format string, args ...any) error {
+	return &cliError{code: exitConflict, err: fmt.Errorf(format, args...)}
+}
+
+// withHint attaches a hint to err, keeping its exit code.
+func withHint(err error, hint string) error {
+	return &cliError{code: exitCode(err), err: err, hint: hint}
+}
+
+// exitCode maps err to an exit code.
+func exitCode(err error) int {
+	if err == nil {
+		return exitOK
+	}
+	var cliErr *cliError
+	if errors.As(err, &cliErr) {
+		return cliErr.code
+	}
+
+	switch {
+	case apierrors.IsNotFound(err), errors.Is(err, templates.ErrNotFound):
+		return exitNotFound
+	case apierrors.IsUnauthorized(err), apierrors.IsForbidden(err):
+		return exitForbidden
+	case apierrors.IsAlreadyExists(err), apierrors.IsConflict(err):
+		return exitConflict
+	case apierrors.IsInvalid(err), apierrors.IsBadRequest(err):
+		return exitUsage
+	case errors.Is(err, context.Canceled):
+		return exitInterrupted
+	case apierrors.IsTimeout(err), apierrors.IsServerTimeout(err), errors.Is(err, context.DeadlineExceeded):
+		return exitTimeout
+	case isUnreachable(err):
+		return exitUnavailable
+	}
+	return exitError
+}
+
+// hint returns advice on how to fix err, empty if there is none.
+func hint(err error) string {
+	var cliErr *cliError
+	if errors.As(err, &cliErr) && cliErr.hint != "" {
+		return cliErr.hint
+	}
+
+	switch {
+	case apierrors.IsNotFound(err) && isMissingResource(err):
+		return "The BackupRequest resource is not known to the cluster. Is the Oiler operator installed?"
+	case apierrors.IsNotFound(err):
+		return "Check the name, e.g. with oiler-cli backup list or oiler-cli adapter list."
+	case apierrors.IsUnauthorized(err):
+		return "Credentials of the kubeconfig are missing or expired. Log in to the cluster again."
+	case apierrors.IsForbidden(err):
+		return "Your user lacks RBAC permissions for this action. Ask a cluster administrator, or check with kubectl auth can-i."
+	case apierrors.IsAlreadyExists(err):
+		return "Choose another name or delete the existing object first."
+	case apierrors.IsConflict(err):
+		return "The object was changed by someone else meanwhile. Run the command again."
+	case isUnreachable(err):
+		return "Check kube_config_path with oiler-cli config get and that the cluster is reachable."
+	case exitCode(err) == exitTimeout:
+		return "The cluster did not answer in time. Try again or raise the timeout, e.g. --request-timeout."
+	}
+	return ""
+}
+
+// isMissingResource reports whether a not found error is about the resource type rather than an object.
+func isMissingResource(err error) bool {
+	var status apierrors.APIStatus
+	if !errors.As(err, &status) {
+		return false
+	}
+	details := status.Status().Details
+	return details == nil || details.Name == ""
+}
+
+// isUnreachable reports whether err means the API server could not be reached.
+func isUnreachable(err error) bool {
+	var opErr *net.OpError
+	var dnsErr *net.DNSError
+	if errors.As(err, &opErr) || errors.As(err, &dnsErr) {
+		return true
+	}
+	return strings.Contains(err.Error(), "connection refused") || strings.Contains(err.Error(), "no such host")
+}
+
+// renderError prints err for humans and returns the exit code.
+func renderError(w io.Writer, err error, color bool) int {
+	prefix, hintPrefix := "Error:", "Hint:"
+	if color {
+		prefix = text.Colors{text.FgRed, text.Bold}.Sprint(prefix)
+		hintPrefix = text.Colors{text.FgYellow}.Sprint(hintPrefix)
+	}
+
+	fmt.Fprintf(w, "%s %s\n", prefix, capitalize(err.Error()))
+	if h := hint(err); h != "" {
+		fmt.Fprintf(w, "%s %s\n", hintPrefix, h)
+	}
+	return exitCode(err)
+}
+
+// capitalize upper-cases the first letter of an error message.
+func capitalize(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
@@ -0,0 +1,156 @@
+package cmd
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/oiler-backup/cli/internal/config"
+	"github.com/oiler-backup/cli/internal/k8s"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+// A Factory provides the configuration and Kubernetes clients to commands.
+// Commands get it from their constructor, so tests can pass fake clients.
+type Factory interface {
+	// Config returns the oiler-cli configuration, with --adapter-configmap applied.
+	Config() (*config.Config, error)
+	// RESTConfig returns the Kubernetes client configuration.
+	RESTConfig() (*rest.Config, error)
+	// ClientSet returns a typed Kubernetes client.
+	ClientSet() (kubernetes.Interface, error)
+	// DynamicClient returns a dynamic Kubernetes client, used for BackupRequests.
+	DynamicClient() (dynamic.Interface, error)
+	// KubeConfig returns the merged kubeconfig, e.g. to list contexts.
+	KubeConfig() (clientcmdapi.Config, error)
+	// ForContext returns a Factory for another kubeconfig context, itself for an empty one.
+	ForContext(kubeContext string) Factory
+}
+
+// A clientFactory is the Factory backed by the configuration file, kubeconfig and flags of the root command.
+// Configuration and clients are created once and reused.
+type clientFactory struct {
+	flags       *rootFlags
+	kubeContext string
+
+	mu     sync.Mutex
+	cfg    *config.Config
+	client *k8s.Client
+}
+
+// newClientFactory returns a Factory for flags, loading the configuration file on first use.
+func newClientFactory(flags *rootFlags) *clientFactory {
+	return &clientFactory{flags: flags}
+}
+
+// Config implements Factory.
+func (f *clientFactory) Config() (*config.Config, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.config()
+}
+
+// config is Config for callers holding mu.
+func (f *clientFactory) config() (*config.Config, error) {
+	if f.cfg != nil {
+		return f.cfg, nil
+	}
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return nil, withHint(fmt.Errorf("failed to load config: %w", err),
+			"Create ~/.oiler/.config.json with kube_config_path and namespace, see the Configuration section of the README.")
+	}
+	if f.flags.adapterConfigMap != "" {
+		cfg.AdapterConfigMap = f.flags.adapterConfigMap
+	}
+	f.cfg = cfg
+	return cfg, nil
+}
+
+// kubeOptions returns options for kube_config_path, kubeconfig flags and the context of f.
+func (f *clientFactory) kubeOptions() (k8s.Options, error) {
+	cfg, err := f.config()
+	if err != nil {
+		return k8s.Options{}, err
+	}
+	opts := f.flags.kube
+	opts.KubeConfigPath = cfg.KubeConfigPath
+	opts.Retry.MaxBackoff = k8s.DefaultMaxBackoff
+	opts.Retry.Logf = log.Debugf
+	if f.kubeContext != "" {
+		opts.Context = f.kubeContext
+	}
+	return opts, nil
+}
+
+// kubeClient returns the client of f, checking its configuration on first use.
+func (f *clientFactory) kubeClient() (*k8s.Client, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.client != nil {
+		return f.client, nil
+	}
+
+	opts, err := f.kubeOptions()
+	if err != nil {
+		return nil, err
+	}
+	client := k8s.NewClient(opts)
+	restConfig, err := client.RESTConfig()
+	if err != nil {
+		return nil, withHint(err, "Set the path with oiler-cli config set kube-config-path=<path>, or pick a context with --context.")
+	}
+	log.Debugf("Using context %q, API server %s", client.CurrentContext(), restConfig.Host)
+	f.client = client
+	return client, nil
+}
+
+// RESTConfig implements Factory.
+func (f *clientFactory) RESTConfig() (*rest.Config, error) {
+	client, err := f.kubeClient()
+	if err != nil {
+		return nil, err
+	}
+	return client.RESTConfig()
+}
+
+// ClientSet implements Factory.
+func (f *clientFactory) ClientSet() (kubernetes.Interface, error) {
+	client, err := f.kubeClient()
+	if err != nil {
+		return nil, err
+	}
+	return client.ClientSet()
+}
+
+// DynamicClient implements Factory.
+func (f *clientFactory) DynamicClient() (dynamic.Interface, error) {
+	client, err := f.kubeClient()
+	if err != nil {
+		return nil, err
+	}
+	return client.DynamicClient()
+}
+
+// KubeConfig implements Factory. It works even if the current context is broken.
+func (f *clientFactory) KubeConfig() (clientcmdapi.Config, error) {
+	f.mu.Lock()
+	opts, err := f.kubeOptions()
+	f.mu.Unlock()
+	if err != nil {
+		return clientcmdapi.Config{}, err
+	}
+	return k8s.NewClient(opts).RawConfig()
+}
+
+// ForContext implements Factory.
+func (f *clientFactory) ForContext(kubeContext string) Factory {
+	if kubeContext == "" {
+		return f
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return &clientFactory{flags: f.flags, kubeContext: kubeContext, cfg: f.cfg}
+}
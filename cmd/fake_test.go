@@ -0,0 +1,259 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/oiler-backup/cli/internal/adapters"
+	"github.com/oiler-backup/cli/internal/config"
+	"github.com/oiler-backup/cli/internal/rbac"
+	backupv1 "github.com/oiler-backup/core/core/api/v1"
+	"go.uber.org/zap"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/rest"
+	k8stesting "k8s.io/client-go/testing"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+var update = flag.Bool("update", false, "rewrite golden files in testdata")
+
+// testNamespace is the namespace of the fake Oiler installation.
+const testNamespace = "oiler-backup-system"
+
+// TestMain silences logs of helpers called outside of commands.
+func TestMain(m *testing.M) {
+	log = zap.NewNop().Sugar()
+	os.Exit(m.Run())
+}
+
+// A fakeFactory is a Factory backed by fake clients.
+type fakeFactory struct {
+	cfg       *config.Config
+	clientset *k8sfake.Clientset
+	dynClient *dynamicfake.FakeDynamicClient
+	// denied are permissions SelfSubjectAccessReviews deny, by rbac.Permission.String.
+	denied map[string]bool
+	// contexts are the kubeconfig contexts passed to ForContext.
+	contexts []string
+	// flags are the flags of the root command f was last given to.
+	flags *rootFlags
+}
+
+// newFakeFactory returns a fakeFactory with the adapter ConfigMap holding adapterEntries and backupRequests.
+func newFakeFactory(t *testing.T, adapterEntries map[string]string, backupRequests ...backupv1.BackupRequest) *fakeFactory {
+	t.Helper()
+
+	objects := make([]runtime.Object, 0, len(backupRequests))
+	for _, br := range backupRequests {
+		objects = append(objects, toUnstructured(t, br))
+	}
+
+	f := &fakeFactory{
+		cfg: &config.Config{KubeConfigPath: "/nonexistent", Namespace: testNamespace},
+		clientset: k8sfake.NewSimpleClientset(&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: adapters.DefaultConfigMapName, Namespace: testNamespace},
+			Data:       adapterEntries,
+		}),
+		dynClient: dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+			map[schema.GroupVersionResource]string{gvr: "BackupRequestList"}, objects...),
+		denied: map[string]bool{},
+	}
+	f.clientset.PrependReactor("create", "selfsubjectaccessreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		review := action.(k8stesting.CreateAction).GetObject().(*authorizationv1.SelfSubjectAccessReview)
+		attrs := review.Spec.ResourceAttributes
+		p := rbac.Permission{Verb: attrs.Verb, Group: attrs.Group, Resource: attrs.Resource, Subresource: attrs.Subresource}
+		review.Status.Allowed = !f.denied[p.String()]
+		return true, review, nil
+	})
+	// The object tracker only patches existing objects, server-side apply also creates them.
+	f.dynClient.PrependReactor("patch", "*", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		patch := action.(k8stesting.PatchAction)
+		if patch.GetPatchType() != types.ApplyPatchType {
+			return false, nil, nil
+		}
+		object := &unstructured.Unstructured{}
+		if err := json.Unmarshal(patch.GetPatch(), &object.Object); err != nil {
+			return true, nil, err
+		}
+		tracker := f.dynClient.Tracker()
+		_, err := tracker.Get(patch.GetResource(), patch.GetNamespace(), patch.GetName())
+		if apierrors.IsNotFound(err) {
+			err = tracker.Create(patch.GetResource(), object, patch.GetNamespace())
+		} else if err == nil {
+			err = tracker.Update(patch.GetResource(), object, patch.GetNamespace())
+		}
+		return true, object, err
+	})
+	return f
+}
+
+// deny makes SelfSubjectAccessReviews deny perms.
+func (f *fakeFactory) deny(perms ...rbac.Permission) {
+	for _, p := range perms {
+		f.denied[p.String()] = true
+	}
+}
+
+// Config implements Factory.
+func (f *fakeFactory) Config() (*config.Config, error) {
+	if f.flags == nil || f.flags.adapterConfigMap == "" {
+		return f.cfg, nil
+	}
+	cfg := *f.cfg
+	cfg.AdapterConfigMap = f.flags.adapterConfigMap
+	return &cfg, nil
+}
+
+// RESTConfig implements Factory.
+func (f *fakeFactory) RESTConfig() (*rest.Config, error) {
+	return &rest.Config{Host: "https://fake"}, nil
+}
+
+// ClientSet implements Factory.
+func (f *fakeFactory) ClientSet() (kubernetes.Interface, error) {
+	return f.clientset, nil
+}
+
+// DynamicClient implements Factory.
+func (f *fakeFactory) DynamicClient() (dynamic.Interface, error) {
+	return f.dynClient, nil
+}
+
+// KubeConfig implements Factory.
+func (f *fakeFactory) KubeConfig() (clientcmdapi.Config, error) {
+	return clientcmdapi.Config{
+		CurrentContext: "fake",
+		Contexts:       map[string]*clientcmdapi.Context{"fake": {Cluster: "fake", AuthInfo: "fake"}},
+	}, nil
+}
+
+// ForContext implements Factory. All contexts share the fake clients.
+func (f *fakeFactory) ForContext(kubeContext string) Factory {
+	f.contexts = append(f.contexts, kubeContext)
+	return f
+}
+
+// adapterEntries returns the adapter ConfigMap data stored in the fake cluster.
+func (f *fakeFactory) adapterEntries(t *testing.T) map[string]string {
+	t.Helper()
+	configMap, err := f.clientset.CoreV1().ConfigMaps(testNamespace).Get(t.Context(), adapters.DefaultConfigMapName, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("failed to get adapter ConfigMap: %v", err)
+	}
+	return configMap.Data
+}
+
+// backupRequest returns the BackupRequest name stored in the fake cluster, failing the test if it is missing.
+func (f *fakeFactory) backupRequest(t *testing.T, name string) backupv1.BackupRequest {
+	t.Helper()
+	item, err := f.dynClient.Resource(gvr).Get(t.Context(), name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("failed to get BackupRequest %s: %v", name, err)
+	}
+	br, err := toBackupRequest(item)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return br
+}
+
+// newBackupRequest returns a BackupRequest for tests.
+func newBackupRequest(name, dbType, schedule, status string, labels map[string]string) backupv1.BackupRequest {
+	return backupv1.BackupRequest{
+		TypeMeta:   metav1.TypeMeta{APIVersion: backupv1.GroupVersion.String(), Kind: "BackupRequest"},
+		ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels},
+		Spec: backupv1.BackupRequestSpec{
+			DbSpec: backupv1.DatabaseSpec{
+				DbType: dbType,
+				URI:    name + ".db.svc.cluster.local",
+				Port:   5432,
+				DbName: name,
+			},
+			S3Spec: backupv1.S3Spec{
+				Endpoint:   "http://minio:9000",
+				BucketName: "backups",
+			},
+			Schedule:       schedule,
+			MaxBackupCount: 3,
+		},
+		Status: backupv1.BackupRequestStatus{Status: status},
+	}
+}
+
+// toUnstructured converts br for the fake dynamic client.
+func toUnstructured(t *testing.T, br backupv1.BackupRequest) *unstructured.Unstructured {
+	t.Helper()
+	object, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&br)
+	if err != nil {
+		t.Fatalf("failed to convert BackupRequest %s: %v", br.Name, err)
+	}
+	return &unstructured.Unstructured{Object: object}
+}
+
+// runCmd executes oiler-cli with args against f and returns what it printed to stdout.
+func runCmd(t *testing.T, f *fakeFactory, args ...string) (string, error) {
+	t.Helper()
+	return runCmdContext(t, t.Context(), f, args...)
+}
+
+// runCmdContext is runCmd with the context of the command, e.g. to interrupt it.
+func runCmdContext(t *testing.T, ctx context.Context, f *fakeFactory, args ...string) (string, error) {
+	t.Helper()
+	cmd := newRootCmd(func(flags *rootFlags) Factory {
+		f.flags = flags
+		return f
+	})
+	var out, errOut bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetErr(&errOut)
+	cmd.SetArgs(append(args, "--log-format=json"))
+	err := cmd.ExecuteContext(ctx)
+	return out.String(), err
+}
+
+// assertGolden compares got with testdata/name.golden, rewriting the file with -update.
+func assertGolden(t *testing.T, name, got string) {
+	t.Helper()
+	path := filepath.Join("testdata", name+".golden")
+	if *update {
+		if err := os.MkdirAll("testdata", 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(got), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read golden file, run go test with -update to create it: %v", err)
+	}
+	if got != string(want) {
+		t.Errorf("output differs from %s:\n--- got\n%s\n--- want\n%s", path, got, want)
+	}
+}
+
+// assertExitCode checks that err maps to exit code want.
+func assertExitCode(t *testing.T, err error, want int) {
+	t.Helper()
+	if got := exitCode(err); got != want {
+		t.Errorf("exit code = %d, want %d (err: %v)", got, want, err)
+	}
+}
@@ -1,15 +1,25 @@
 package cmd
 
 import (
-	"time"
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
 
-	"github.com/briandowns/spinner"
+	"github.com/oiler-backup/cli/internal/adapters"
+	"github.com/oiler-backup/cli/internal/progress"
 	backupv1 "github.com/oiler-backup/core/core/api/v1"
+	"golang.org/x/term"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
-	"k8s.io/client-go/rest"
-	"k8s.io/client-go/tools/clientcmd"
 )
 
 var (
@@ -18,55 +28,207 @@ var (
 		Version:  backupv1.GroupVersion.Version,
 		Resource: "backuprequests",
 	}
+
+	// reporter shows progress on stderr, set up by flags of the root command.
+	reporter = progress.New(os.Stderr, progress.Options{})
+
+	// stdin buffers standard input for every prompt reading lines, so none of them
+	// drops input a previous one has read ahead.
+	stdin = bufio.NewReader(os.Stdin)
 )
 
-// getConfig returns configuration.
-func getConfig() (*rest.Config, error) {
-	config, err := rest.InClusterConfig()
+// getAdapterConfigMap locates and returns the adapter ConfigMap.
+// --adapter-configmap takes precedence over adapter_config_map from config file.
+func getAdapterConfigMap(ctx context.Context, f Factory, clientset kubernetes.Interface) (*corev1.ConfigMap, error) {
+	cfg, err := f.Config()
 	if err != nil {
-		config, err = clientcmd.BuildConfigFromFlags("", cfg.KubeConfigPath)
-		if err != nil {
-			return nil, err
-		}
+		return nil, err
 	}
-	return config, nil
-}
 
-// getClientSet returns clientset.
-func getClientSet() (*kubernetes.Clientset, error) {
-	config, err := getConfig()
+	loc, err := adapters.Locate(ctx, clientset, cfg.Namespace, cfg.AdapterConfigMap)
 	if err != nil {
 		return nil, err
 	}
-	clientset, err := kubernetes.NewForConfig(config)
+
+	configMap, err := clientset.CoreV1().ConfigMaps(loc.Namespace).Get(ctx, loc.Name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil, notFoundErrorf("adapter ConfigMap %s (from %s) does not exist; check the operator installation or --adapter-configmap", loc, loc.Source)
+	}
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("failed to get adapter ConfigMap %s: %w", loc, err)
+	}
+	if configMap.Data == nil {
+		configMap.Data = map[string]string{}
 	}
 
-	return clientset, nil
+	return configMap, nil
 }
 
-// getDynamicClient returns dynamicClient.
-func getDynamicClient() (*dynamic.DynamicClient, error) {
-	config, err := getConfig()
+// listBackupRequests returns all BackupRequest resources matching opts.
+func listBackupRequests(ctx context.Context, dynClient dynamic.Interface, opts metav1.ListOptions) ([]backupv1.BackupRequest, error) {
+	list, err := dynClient.Resource(gvr).List(ctx, opts)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("failed to list BackupRequest resources: %w", err)
+	}
+
+	backupRequests := make([]backupv1.BackupRequest, 0, len(list.Items))
+	for _, item := range list.Items {
+		backupRequest, err := toBackupRequest(&item)
+		if err != nil {
+			return nil, err
+		}
+		backupRequests = append(backupRequests, backupRequest)
+	}
+
+	return backupRequests, nil
+}
+
+// selectBackupRequestObjects returns BackupRequests by names, or all matching label and field selectors.
+func selectBackupRequestObjects(ctx context.Context, dynClient dynamic.Interface, names []string, selector, fieldSelector string) ([]unstructured.Unstructured, error) {
+	hasSelector := selector != "" || fieldSelector != ""
+	switch {
+	case len(names) > 0 && hasSelector:
+		return nil, usageErrorf("specify BackupRequest names or selectors, not both")
+	case len(names) == 0 && !hasSelector:
+		return nil, usageErrorf("specify BackupRequest names or selectors")
+	case hasSelector:
+		list, err := dynClient.Resource(gvr).List(ctx, metav1.ListOptions{LabelSelector: selector, FieldSelector: fieldSelector})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list BackupRequest resources: %w", err)
+		}
+		return list.Items, nil
 	}
 
-	dynClient, err := dynamic.NewForConfig(config)
+	items := make([]unstructured.Unstructured, 0, len(names))
+	for _, name := range names {
+		item, err := dynClient.Resource(gvr).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get BackupRequest %s: %w", name, err)
+		}
+		items = append(items, *item)
+	}
+	return items, nil
+}
+
+// selectBackupRequests is selectBackupRequestObjects returning typed BackupRequests.
+func selectBackupRequests(ctx context.Context, dynClient dynamic.Interface, names []string, selector, fieldSelector string) ([]backupv1.BackupRequest, error) {
+	items, err := selectBackupRequestObjects(ctx, dynClient, names, selector, fieldSelector)
 	if err != nil {
 		return nil, err
 	}
 
-	return dynClient, nil
+	backupRequests := make([]backupv1.BackupRequest, 0, len(items))
+	for _, item := range items {
+		backupRequest, err := toBackupRequest(&item)
+		if err != nil {
+			return nil, err
+		}
+		backupRequests = append(backupRequests, backupRequest)
+	}
+	return backupRequests, nil
+}
+
+// toBackupRequest converts unstructured item into BackupRequest.
+func toBackupRequest(item *unstructured.Unstructured) (backupv1.BackupRequest, error) {
+	var backupRequest backupv1.BackupRequest
+	jsonItem, err := item.MarshalJSON()
+	if err != nil {
+		return backupRequest, fmt.Errorf("failed to marshal object: %w", err)
+	}
+	if err := json.Unmarshal(jsonItem, &backupRequest); err != nil {
+		return backupRequest, fmt.Errorf("failed to unmarshal BackupRequest resource: %w", err)
+	}
+	return backupRequest, nil
 }
 
-// startSpinner starts spinner to brighten the wait up.
-// Useless but funny.
+// adapterUsage groups BackupRequests by the adapter (DbSpec.DbType) they rely on.
+func adapterUsage(backupRequests []backupv1.BackupRequest) map[string][]backupv1.BackupRequest {
+	usage := make(map[string][]backupv1.BackupRequest)
+	for _, br := range backupRequests {
+		usage[br.Spec.DbSpec.DbType] = append(usage[br.Spec.DbSpec.DbType], br)
+	}
+	return usage
+}
+
+// startSpinner reports a step of a command until the returned function is called.
 func startSpinner(text string) func() {
-	s := spinner.New([]string{".", "..", "..."}, 500*time.Millisecond)
-	s.Prefix = text
-	s.Start()
+	return reporter.Start(text).Done
+}
+
+// startProgress reports a step of a command made of total actions, e.g. a bulk update.
+func startProgress(text string, total int) *progress.Task {
+	return reporter.StartBar(text, total)
+}
+
+// isTerminal reports whether w writes to a terminal.
+func isTerminal(w io.Writer) bool {
+	file, ok := w.(*os.File)
+	return ok && term.IsTerminal(int(file.Fd()))
+}
+
+// readInterruptibly runs read, a blocking terminal read, and gives up when ctx is done.
+// An abandoned read ends with the process.
+func readInterruptibly[T any](ctx context.Context, read func() (T, error)) (T, error) {
+	type result struct {
+		value T
+		err   error
+	}
+	done := make(chan result, 1)
+	go func() {
+		value, err := read()
+		done <- result{value, err}
+	}()
+
+	select {
+	case r := <-done:
+		return r.value, r.err
+	case <-ctx.Done():
+		var zero T
+		return zero, ctx.Err()
+	}
+}
+
+// readInput reads a file, or stdin if path is -.
+func readInput(path string) ([]byte, error) {
+	if path == "-" {
+		return io.ReadAll(stdin)
+	}
+	return os.ReadFile(path)
+}
+
+// readSecret prompts on stderr for a value on the terminal without echoing it.
+// Echo is turned back on when ctx ends while waiting for input.
+func readSecret(ctx context.Context, prompt string) (string, error) {
+	reporter.StopAll()
+	fd := int(os.Stdin.Fd())
+	state, _ := term.GetState(fd)
+	fmt.Fprintf(os.Stderr, "%s: ", prompt)
+	value, err := readInterruptibly(ctx, func() ([]byte, error) {
+		return term.ReadPassword(fd)
+	})
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		if state != nil {
+			term.Restore(fd, state)
+		}
+		return "", err
+	}
+	return string(value), nil
+}
+
+// confirm asks a yes/no question on the terminal, prompting on stderr.
+// Without a terminal or when ctx ends it answers no, so scripts have to opt in explicitly.
+func confirm(ctx context.Context, question string) bool {
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		log.Warnf("Cannot ask %q without a terminal, pass --yes to proceed", question)
+		return false
+	}
 
-	return s.Stop
+	reporter.StopAll()
+	fmt.Fprintf(os.Stderr, "%s [y/N]: ", question)
+	answer, _ := readInterruptibly(ctx, func() (string, error) {
+		return stdin.ReadString('\n')
+	})
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
 }
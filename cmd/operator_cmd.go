@@ -0,0 +1,539 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/oiler-backup/cli/internal/adapters"
+	"github.com/oiler-backup/cli/internal/operator"
+	"github.com/oiler-backup/cli/internal/rbac"
+	"github.com/spf13/cobra"
+	appsv1 "k8s.io/api/apps/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/yaml"
+)
+
+// rolloutPollInterval is how often operator install and upgrade check the rollout with --wait.
+const rolloutPollInterval = 2 * time.Second
+
+// operatorApplyFlags are the flags of operator install and upgrade.
+type operatorApplyFlags struct {
+	version string
+	images  []string
+	dryRun  string
+	wait    bool
+	timeout time.Duration
+}
+
+// operatorUninstallFlags are the flags of operator uninstall.
+type operatorUninstallFlags struct {
+	keepBackups bool
+	yes         bool
+	dryRun      string
+}
+
+// newOperatorCmd returns the top-level command for managing the operator installation.
+func newOperatorCmd(f Factory) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "operator",
+		Short: "Install and manage the Oiler operator",
+		Long: `Install, upgrade, uninstall and inspect the Oiler operator in the configured namespace.
+
+Manifests of the operator (CRDs, RBAC, Deployment, Service and the adapter ConfigMap) are embedded
+in oiler-cli for every supported operator version and applied with server-side apply.
+These commands need wide permissions, like creating CRDs and ClusterRoles.`,
+	}
+
+	cmd.AddCommand(newOperatorInstallCmd(f))
+	cmd.AddCommand(newOperatorUpgradeCmd(f))
+	cmd.AddCommand(newOperatorUninstallCmd(f))
+	cmd.AddCommand(newOperatorStatusCmd(f))
+	return cmd
+}
+
+// newOperatorInstallCmd returns a command that installs the operator.
+func newOperatorInstallCmd(f Factory) *cobra.Command {
+	flags := &operatorApplyFlags{}
+	cmd := &cobra.Command{
+		Use:   "install",
+		Short: "Install the operator",
+		Long: `Install the operator into the configured namespace, creating the namespace if needed.
+
+Refused if an operator Deployment exists already, use operator upgrade then.
+--dry-run=client prints the manifests, --dry-run=server sends dry-run requests to the API server.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return applyOperator(cmd, f, flags, false)
+		},
+	}
+	addOperatorApplyFlags(cmd, flags)
+	return cmd
+}
+
+// newOperatorUpgradeCmd returns a command that upgrades the operator.
+func newOperatorUpgradeCmd(f Factory) *cobra.Command {
+	flags := &operatorApplyFlags{}
+	cmd := &cobra.Command{
+		Use:   "upgrade",
+		Short: "Upgrade the operator",
+		Long: `Apply the manifests of --version over the installed operator.
+
+Adapters registered in the adapter ConfigMap and BackupRequests are kept.
+--dry-run=client prints the manifests, --dry-run=server sends dry-run requests to the API server.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return applyOperator(cmd, f, flags, true)
+		},
+	}
+	addOperatorApplyFlags(cmd, flags)
+	return cmd
+}
+
+// addOperatorApplyFlags registers flags of operator install and upgrade on cmd.
+func addOperatorApplyFlags(cmd *cobra.Command, flags *operatorApplyFlags) {
+	cmd.Flags().StringVar(&flags.version, "version", operator.DefaultVersion, "Operator version to apply")
+	cmd.Flags().StringArrayVar(&flags.images, "image", nil, "Image override as [container=]image, the manager container by default; can be repeated")
+	cmd.Flags().StringVar(&flags.dryRun, "dry-run", dryRunNone, "none, client (only print manifests) or server (send dry-run requests)")
+	cmd.Flags().Lookup("dry-run").NoOptDefVal = dryRunClient
+	cmd.Flags().BoolVar(&flags.wait, "wait", false, "Wait until the operator Deployment is rolled out")
+	cmd.Flags().DurationVar(&flags.timeout, "timeout", 5*time.Minute, "How long to wait with --wait")
+	registerFlagCompletion(cmd, "version", cobra.FixedCompletions(operator.Versions(), cobra.ShellCompDirectiveNoFileComp))
+	registerFlagCompletion(cmd, "dry-run", cobra.FixedCompletions([]string{dryRunNone, dryRunClient, dryRunServer}, cobra.ShellCompDirectiveNoFileComp))
+}
+
+// applyOperator installs the operator, or upgrades it if upgrade is set.
+func applyOperator(cmd *cobra.Command, f Factory, flags *operatorApplyFlags, upgrade bool) error {
+	ctx := cmd.Context()
+	stopFn := startSpinner("[1/3] Preparing")
+	dryRun, err := parseDryRun(flags.dryRun)
+	if err != nil {
+		stopFn()
+		return err
+	}
+	images, err := parseImageOverrides(flags.images)
+	if err != nil {
+		stopFn()
+		return err
+	}
+	cfg, err := f.Config()
+	if err != nil {
+		stopFn()
+		return err
+	}
+	objects, err := operator.Render(flags.version, operator.Values{Namespace: cfg.Namespace, Images: images})
+	if err != nil {
+		stopFn()
+		return usageErrorf("%w", err)
+	}
+	if flags.dryRun == dryRunClient {
+		stopFn()
+		return printManifests(cmd.OutOrStdout(), objects)
+	}
+
+	clientset, err := f.ClientSet()
+	if err != nil {
+		stopFn()
+		return fmt.Errorf("failed to get client: %w", err)
+	}
+	dynClient, err := f.DynamicClient()
+	if err != nil {
+		stopFn()
+		return fmt.Errorf("failed to get client: %w", err)
+	}
+	if err := checkAccess(ctx, f, operatorPermissions(objects, "create", "patch")...); err != nil {
+		stopFn()
+		return err
+	}
+	installed, err := operator.Find(ctx, clientset, cfg.Namespace)
+	if err != nil {
+		stopFn()
+		return err
+	}
+	stopFn()
+
+	switch {
+	case !upgrade && installed != nil:
+		return withHint(conflictErrorf("operator %s is already installed in namespace %s as Deployment %s",
+			operator.InstalledVersion(installed), cfg.Namespace, installed.Name), "Use oiler-cli operator upgrade.")
+	case upgrade && installed == nil:
+		return withHint(notFoundErrorf("no operator is installed in namespace %s", cfg.Namespace), "Use oiler-cli operator install.")
+	case upgrade:
+		log.Infof("Upgrading operator in %s from %s to %s", cfg.Namespace, operator.InstalledVersion(installed), flags.version)
+	}
+
+	bar := startProgress("[2/3] Applying manifests", len(objects))
+	results := make([]string, 0, len(objects))
+	for _, object := range objects {
+		resource := operator.ResourceFor(dynClient, object)
+		result := "configured"
+		if _, err := resource.Get(ctx, object.GetName(), metav1.GetOptions{}); apierrors.IsNotFound(err) {
+			result = "created"
+		}
+		_, err := resource.Apply(ctx, object.GetName(), object, metav1.ApplyOptions{FieldManager: operator.FieldManager, Force: true, DryRun: dryRun})
+		if err != nil {
+			bar.Done()
+			return fmt.Errorf("failed to apply %s %s: %w", object.GetKind(), object.GetName(), err)
+		}
+		if dryRun != nil {
+			result += " (dry run)"
+		}
+		results = append(results, result)
+		bar.Increment()
+	}
+	bar.Done()
+
+	renderOperatorObjects(cmd.OutOrStdout(), objects, results, "APPLIED")
+
+	if flags.wait && dryRun == nil {
+		stopFn = startSpinner("[3/3] Waiting for the operator rollout")
+		err := waitForRollout(ctx, clientset, objects, flags.timeout)
+		stopFn()
+		return err
+	}
+	return nil
+}
+
+// parseImageOverrides parses --image values into images by container name.
+func parseImageOverrides(values []string) (map[string]string, error) {
+	images := make(map[string]string, len(values))
+	for _, value := range values {
+		container, image, ok := strings.Cut(value, "=")
+		if !ok {
+			container, image = operator.ManagerContainer, value
+		}
+		if container == "" || image == "" {
+			return nil, usageErrorf("invalid --image %q, use [container=]image", value)
+		}
+		images[container] = image
+	}
+	return images, nil
+}
+
+// operatorPermissions returns verbs on the resources of objects.
+func operatorPermissions(objects []*unstructured.Unstructured, verbs ...string) []rbac.Permission {
+	var perms []rbac.Permission
+	seen := map[rbac.Permission]bool{}
+	for _, verb := range verbs {
+		for _, object := range objects {
+			gvr := operator.GroupVersionResource(object)
+			p := rbac.Permission{Verb: verb, Group: gvr.Group, Resource: gvr.Resource, Namespaced: operator.Namespaced(object)}
+			if !seen[p] {
+				seen[p] = true
+				perms = append(perms, p)
+			}
+		}
+	}
+	return perms
+}
+
+// printManifests writes objects as a multi-document YAML stream.
+func printManifests(w io.Writer, objects []*unstructured.Unstructured) error {
+	for _, object := range objects {
+		data, err := yaml.Marshal(object.Object)
+		if err != nil {
+			return fmt.Errorf("failed to encode %s %s: %w", object.GetKind(), object.GetName(), err)
+		}
+		fmt.Fprintf(w, "---\n%s", data)
+	}
+	return nil
+}
+
+// waitForRollout waits until the operator Deployment among objects has all replicas updated and available.
+func waitForRollout(ctx context.Context, clientset kubernetes.Interface, objects []*unstructured.Unstructured, timeout time.Duration) error {
+	for _, object := range objects {
+		if object.GetKind() != "Deployment" {
+			continue
+		}
+		err := wait.PollUntilContextTimeout(ctx, rolloutPollInterval, timeout, true, func(ctx context.Context) (bool, error) {
+			d, err := clientset.AppsV1().Deployments(object.GetNamespace()).Get(ctx, object.GetName(), metav1.GetOptions{})
+			if err != nil {
+				return false, err
+			}
+			return rolledOut(d), nil
+		})
+		switch {
+		case errors.Is(ctx.Err(), context.Canceled):
+			return fmt.Errorf("stopped waiting for Deployment %s: %w", object.GetName(), ctx.Err())
+		case wait.Interrupted(err):
+			return &cliError{
+				code: exitTimeout,
+				err:  fmt.Errorf("Deployment %s was not rolled out within %s", object.GetName(), timeout),
+				hint: "Check the operator with oiler-cli operator status or oiler-cli doctor.",
+			}
+		case err != nil:
+			return err
+		}
+	}
+	return nil
+}
+
+// rolledOut reports whether the latest spec of d is rolled out to all replicas.
+func rolledOut(d *appsv1.Deployment) bool {
+	replicas := ptrOr(d.Spec.Replicas, 1)
+	return d.Status.ObservedGeneration >= d.Generation &&
+		d.Status.UpdatedReplicas == replicas &&
+		d.Status.AvailableReplicas == replicas
+}
+
+// renderOperatorObjects prints objects with the result of an action on each, counting those done in the footer.
+func renderOperatorObjects(w io.Writer, objects []*unstructured.Unstructured, results []string, done string) {
+	t := table.NewWriter()
+	t.SetOutputMirror(w)
+	t.SetStyle(table.StyleLight)
+	t.AppendHeader(table.Row{"#", "Kind", "Name", "Namespace", "Result"})
+	for i, object := range objects {
+		t.AppendRow(table.Row{i + 1, object.GetKind(), object.GetName(), object.GetNamespace(), results[i]})
+	}
+	t.AppendFooter(table.Row{"", "", "", done, len(results)})
+	t.Render()
+}
+
+// newOperatorUninstallCmd returns a command that uninstalls the operator.
+func newOperatorUninstallCmd(f Factory) *cobra.Command {
+	flags := &operatorUninstallFlags{}
+	cmd := &cobra.Command{
+		Use:   "uninstall",
+		Short: "Uninstall the operator",
+		Long: `Delete the objects of the operator from the cluster. The namespace is kept.
+
+Deleting the CRDs deletes all BackupRequests, so existing ones are counted and a confirmation is
+asked unless --yes is set. --keep-backups keeps the CRDs, BackupRequests and the adapter ConfigMap
+for a later install. --dry-run=client only lists objects, --dry-run=server also sends dry-run requests.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			stopFn := startSpinner("[1/3] Preparing")
+			dryRun, err := parseDryRun(flags.dryRun)
+			if err != nil {
+				stopFn()
+				return err
+			}
+			cfg, err := f.Config()
+			if err != nil {
+				stopFn()
+				return err
+			}
+			clientset, err := f.ClientSet()
+			if err != nil {
+				stopFn()
+				return fmt.Errorf("failed to get client: %w", err)
+			}
+			dynClient, err := f.DynamicClient()
+			if err != nil {
+				stopFn()
+				return fmt.Errorf("failed to get client: %w", err)
+			}
+			installed, err := operator.Find(ctx, clientset, cfg.Namespace)
+			if err != nil {
+				stopFn()
+				return err
+			}
+			objects, err := operator.Render(renderedVersion(installed), operator.Values{Namespace: cfg.Namespace})
+			if err != nil {
+				stopFn()
+				return err
+			}
+			if flags.dryRun != dryRunClient {
+				if err := checkAccess(ctx, f, operatorPermissions(objects, "delete")...); err != nil {
+					stopFn()
+					return err
+				}
+			}
+			stopFn()
+			if installed == nil {
+				log.Warnf("No operator Deployment in namespace %s, deleting what is left of the installation", cfg.Namespace)
+			}
+
+			stopFn = startSpinner("[2/3] Counting BackupRequests")
+			backupRequests, err := listBackupRequests(ctx, dynClient, metav1.ListOptions{})
+			stopFn()
+			if err != nil && !(apierrors.IsNotFound(err) && isMissingResource(err)) {
+				return err
+			}
+			switch {
+			case flags.keepBackups:
+				log.Infof("Keeping CRDs, the adapter ConfigMap and %d BackupRequests", len(backupRequests))
+			case len(backupRequests) > 0:
+				log.Warnf("%d BackupRequests exist, deleting the CRDs deletes them and stops their backups; use --keep-backups to keep them", len(backupRequests))
+			}
+
+			var toDelete []*unstructured.Unstructured
+			results := make([]string, 0, len(objects))
+			for _, object := range objects {
+				if keepOnUninstall(object, flags.keepBackups) {
+					continue
+				}
+				toDelete = append(toDelete, object)
+			}
+			// Dependents go first, the reverse of the apply order.
+			for i, j := 0, len(toDelete)-1; i < j; i, j = i+1, j-1 {
+				toDelete[i], toDelete[j] = toDelete[j], toDelete[i]
+			}
+
+			if flags.dryRun == dryRunClient {
+				for range toDelete {
+					results = append(results, "would be deleted")
+				}
+				renderOperatorObjects(cmd.OutOrStdout(), toDelete, results, "TOTAL")
+				return nil
+			}
+			question := fmt.Sprintf("Uninstall the operator from namespace %s?", cfg.Namespace)
+			if len(backupRequests) > 0 && !flags.keepBackups {
+				question = fmt.Sprintf("Uninstall the operator from namespace %s and delete %d BackupRequest(s)?", cfg.Namespace, len(backupRequests))
+			}
+			if dryRun == nil && !flags.yes && !confirm(ctx, question) {
+				log.Info("Uninstall cancelled")
+				return nil
+			}
+
+			bar := startProgress("[3/3] Deleting objects", len(toDelete))
+			policy := metav1.DeletePropagationBackground
+			for _, object := range toDelete {
+				err := operator.ResourceFor(dynClient, object).Delete(ctx, object.GetName(), metav1.DeleteOptions{PropagationPolicy: &policy, DryRun: dryRun})
+				switch {
+				case apierrors.IsNotFound(err):
+					results = append(results, "not found")
+				case err != nil:
+					bar.Done()
+					return fmt.Errorf("failed to delete %s %s: %w", object.GetKind(), object.GetName(), err)
+				case dryRun != nil:
+					results = append(results, "deleted (dry run)")
+				default:
+					results = append(results, "deleted")
+				}
+				bar.Increment()
+			}
+			bar.Done()
+
+			renderOperatorObjects(cmd.OutOrStdout(), toDelete, results, "TOTAL")
+			log.Infof("Namespace %s is kept, delete it with kubectl delete namespace %s if nothing else lives there", cfg.Namespace, cfg.Namespace)
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&flags.keepBackups, "keep-backups", false, "Keep CRDs, BackupRequests and the adapter ConfigMap")
+	cmd.Flags().BoolVarP(&flags.yes, "yes", "y", false, "Uninstall without confirmation")
+	cmd.Flags().StringVar(&flags.dryRun, "dry-run", dryRunNone, "none, client (only list objects) or server (send dry-run requests)")
+	cmd.Flags().Lookup("dry-run").NoOptDefVal = dryRunClient
+	registerFlagCompletion(cmd, "dry-run", cobra.FixedCompletions([]string{dryRunNone, dryRunClient, dryRunServer}, cobra.ShellCompDirectiveNoFileComp))
+	return cmd
+}
+
+// keepOnUninstall reports whether operator uninstall leaves object in place, keeping backups if keepBackups is set.
+func keepOnUninstall(object *unstructured.Unstructured, keepBackups bool) bool {
+	switch {
+	case object.GetKind() == "Namespace":
+		return true
+	case !keepBackups:
+		return false
+	case object.GetKind() == "CustomResourceDefinition":
+		return true
+	default:
+		return object.GetKind() == "ConfigMap" && object.GetName() == adapters.DefaultConfigMapName
+	}
+}
+
+// renderedVersion picks the embedded manifests matching the installed operator, the default ones if there are none.
+func renderedVersion(installed *appsv1.Deployment) string {
+	if installed != nil {
+		for _, v := range operator.Versions() {
+			if v == operator.InstalledVersion(installed) {
+				return v
+			}
+		}
+	}
+	return operator.DefaultVersion
+}
+
+// newOperatorStatusCmd returns a command that shows the objects of the operator installation.
+func newOperatorStatusCmd(f Factory) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "status",
+		Short: "Show the operator installation",
+		Long: `Show the objects of the operator installation in the configured namespace, their versions and
+whether they exist, and readiness of the operator Deployment.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			stopFn := startSpinner("[1/2] Preparing")
+			cfg, err := f.Config()
+			if err != nil {
+				stopFn()
+				return err
+			}
+			clientset, err := f.ClientSet()
+			if err != nil {
+				stopFn()
+				return fmt.Errorf("failed to get client: %w", err)
+			}
+			dynClient, err := f.DynamicClient()
+			if err != nil {
+				stopFn()
+				return fmt.Errorf("failed to get client: %w", err)
+			}
+			installed, err := operator.Find(ctx, clientset, cfg.Namespace)
+			if err != nil {
+				stopFn()
+				return err
+			}
+			objects, err := operator.Render(renderedVersion(installed), operator.Values{Namespace: cfg.Namespace})
+			if err != nil {
+				stopFn()
+				return err
+			}
+			stopFn()
+
+			stopFn = startSpinner("[2/2] Getting objects")
+			t := table.NewWriter()
+			t.SetOutputMirror(cmd.OutOrStdout())
+			t.SetStyle(table.StyleLight)
+			t.AppendHeader(table.Row{"#", "Kind", "Name", "Namespace", "Version", "Status"})
+			present := 0
+			for i, object := range objects {
+				version, status := "-", "missing"
+				live, err := operator.ResourceFor(dynClient, object).Get(ctx, object.GetName(), metav1.GetOptions{})
+				switch {
+				case apierrors.IsNotFound(err):
+				case err != nil:
+					status = "unknown: " + err.Error()
+				default:
+					present++
+					status = "present"
+					if v := live.GetLabels()[operator.VersionLabel]; v != "" {
+						version = v
+					}
+				}
+				if object.GetKind() == "Deployment" && installed != nil && installed.Name == object.GetName() {
+					status = fmt.Sprintf("%d/%d ready, image %s", installed.Status.ReadyReplicas, ptrOr(installed.Spec.Replicas, 1), operator.ManagerImage(installed))
+				}
+				t.AppendRow(table.Row{i + 1, object.GetKind(), object.GetName(), object.GetNamespace(), version, status})
+			}
+			stopFn()
+			t.AppendFooter(table.Row{"", "", "", "", "PRESENT", fmt.Sprintf("%d/%d", present, len(objects))})
+			t.Render()
+
+			if installed == nil {
+				return withHint(notFoundErrorf("no operator is installed in namespace %s", cfg.Namespace), "Install it with oiler-cli operator install.")
+			}
+			return nil
+		},
+	}
+	return cmd
+}
+
+// ptrOr returns *p, or def if p is nil.
+func ptrOr[T any](p *T, def T) T {
+	if p == nil {
+		return def
+	}
+	return *p
+}
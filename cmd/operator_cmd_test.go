@@ -0,0 +1,106 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/oiler-backup/cli/internal/operator"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+var deploymentGVR = schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}
+
+func TestOperatorInstall(t *testing.T) {
+	f := newFakeFactory(t, nil)
+	out, err := runCmd(t, f, "operator", "install", "--image", "registry.local/oiler:dev")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	assertGolden(t, "operator_install", out)
+
+	deployment, err := f.dynClient.Resource(deploymentGVR).Namespace(testNamespace).Get(t.Context(), "oiler-backup-controller-manager", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("operator Deployment was not applied: %v", err)
+	}
+	containers, _, _ := unstructured.NestedSlice(deployment.Object, "spec", "template", "spec", "containers")
+	if image := containers[0].(map[string]any)["image"]; image != "registry.local/oiler:dev" {
+		t.Errorf("manager image = %v, want registry.local/oiler:dev", image)
+	}
+	if _, err := f.dynClient.Resource(crdGVR).Get(t.Context(), backupRequestCRD, metav1.GetOptions{}); err != nil {
+		t.Errorf("CRD was not applied: %v", err)
+	}
+}
+
+func TestOperatorInstallDryRunClient(t *testing.T) {
+	f := newFakeFactory(t, nil)
+	out, err := runCmd(t, f, "operator", "install", "--dry-run")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, want := range []string{"kind: CustomResourceDefinition", "namespace: " + testNamespace, "image: ashadrinnn/oiler-backup:" + operator.DefaultVersion} {
+		if !strings.Contains(out, want) {
+			t.Errorf("manifests do not contain %q", want)
+		}
+	}
+	if _, err := f.dynClient.Resource(deploymentGVR).Namespace(testNamespace).Get(t.Context(), "oiler-backup-controller-manager", metav1.GetOptions{}); err == nil {
+		t.Error("--dry-run=client applied the Deployment")
+	}
+}
+
+func TestOperatorInstallConflict(t *testing.T) {
+	f := newFakeFactory(t, nil)
+	installOperator(t, f)
+	_, err := runCmd(t, f, "operator", "install")
+	assertExitCode(t, err, exitConflict)
+}
+
+func TestOperatorUpgradeNotInstalled(t *testing.T) {
+	f := newFakeFactory(t, nil)
+	_, err := runCmd(t, f, "operator", "upgrade")
+	assertExitCode(t, err, exitNotFound)
+}
+
+func TestOperatorUninstall(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		keepsCRD bool
+	}{
+		{name: "all", args: []string{"operator", "uninstall", "--yes"}},
+		{name: "keep backups", args: []string{"operator", "uninstall", "--yes", "--keep-backups"}, keepsCRD: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newFakeFactory(t, nil, testBackupRequests()...)
+			if _, err := runCmd(t, f, "operator", "install"); err != nil {
+				t.Fatalf("install failed: %v", err)
+			}
+			if _, err := f.clientset.AppsV1().Deployments(testNamespace).Create(t.Context(), &appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "oiler-backup-controller-manager",
+					Namespace: testNamespace,
+					Labels:    map[string]string{"control-plane": "controller-manager", operator.VersionLabel: operator.DefaultVersion},
+				},
+			}, metav1.CreateOptions{}); err != nil {
+				t.Fatal(err)
+			}
+
+			if _, err := runCmd(t, f, tt.args...); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if _, err := f.dynClient.Resource(deploymentGVR).Namespace(testNamespace).Get(t.Context(), "oiler-backup-controller-manager", metav1.GetOptions{}); err == nil {
+				t.Error("operator Deployment was not deleted")
+			}
+			_, err := f.dynClient.Resource(crdGVR).Get(t.Context(), backupRequestCRD, metav1.GetOptions{})
+			if kept := err == nil; kept != tt.keepsCRD {
+				t.Errorf("CRD kept = %v, want %v", kept, tt.keepsCRD)
+			}
+			if _, err := f.dynClient.Resource(schema.GroupVersionResource{Version: "v1", Resource: "namespaces"}).Get(t.Context(), testNamespace, metav1.GetOptions{}); err != nil {
+				t.Errorf("namespace was deleted: %v", err)
+			}
+		})
+	}
+}
@@ -1,55 +1,181 @@
 package cmd
 
 import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/jedib0t/go-pretty/v6/text"
 	"github.com/oiler-backup/cli/internal/config"
+	"github.com/oiler-backup/cli/internal/k8s"
+	"github.com/oiler-backup/cli/internal/logging"
+	"github.com/oiler-backup/cli/internal/progress"
 	"github.com/spf13/cobra"
 	"go.uber.org/zap"
+	"golang.org/x/term"
+	"k8s.io/client-go/rest"
 )
 
-const (
-	CM_NAME = "database-config"
-)
+// skipConfigAnnotation marks commands that work without the configuration file.
+const skipConfigAnnotation = "oiler.backup/skip-config"
 
-var cfg *config.Config
 var log *zap.SugaredLogger
 
-// rootCmd is a top-level command
-var rootCmd = &cobra.Command{
-	Use:   "oiler-cli",
-	Short: "CLI for Oiler Kubernetes Operator",
-	Long:  `CLI tool to interact with Oiler Kubernetes Operator.`,
+// rootFlags are the persistent flags of the root command.
+type rootFlags struct {
+	// kube are the kubeconfig flags the Factory builds clients with.
+	kube             k8s.Options
+	logFormat        string
+	verbose          bool
+	quiet            bool
+	noColor          bool
+	adapterConfigMap string
 }
 
-// Execute executes incoming command
-func Execute(logger *zap.SugaredLogger) {
-	log = logger
-	if err := rootCmd.Execute(); err != nil {
-		log.Fatalf("Error while executing command: %v", err)
+// newRootCmd returns the top-level command of oiler-cli.
+// Commands get the Factory newFactory returns for the flags of the root command.
+func newRootCmd(newFactory func(flags *rootFlags) Factory) *cobra.Command {
+	flags := &rootFlags{}
+	f := newFactory(flags)
+	cmd := &cobra.Command{
+		Use:   "oiler-cli",
+		Short: "CLI for Oiler Kubernetes Operator",
+		Long: `CLI tool to interact with Oiler Kubernetes Operator.
+
+` + exitCodesHelp,
+		SilenceErrors: true,
+		SilenceUsage:  true,
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			format, err := logging.ParseFormat(flags.logFormat)
+			if err != nil {
+				return usageErrorf("invalid --log-format: %w", err)
+			}
+			logger, err := logging.New(format, flags.verbose)
+			if err != nil {
+				return err
+			}
+			log = logger
+			reporter = progress.New(cmd.ErrOrStderr(), progress.Options{Quiet: flags.quiet, NoColor: flags.noColor})
+			if flags.noColor {
+				text.DisableColors()
+			}
+
+			if cmd.Annotations[skipConfigAnnotation] == "true" || cmd.Name() == "help" {
+				return nil
+			}
+			cfg, err := f.Config()
+			if err != nil {
+				return err
+			}
+			return applyRetryConfig(cmd, cfg, &flags.kube)
+		},
 	}
+
+	cmd.AddCommand(newConfigCmd(f))
+	cmd.AddCommand(newBackupCmd(f))
+	cmd.AddCommand(newAdapterCmd(f))
+	cmd.AddCommand(newTemplateCmd(f))
+	cmd.AddCommand(newUICmd(f))
+	cmd.AddCommand(newAuthCmd(f))
+	cmd.AddCommand(newDoctorCmd(f))
+	cmd.AddCommand(newOperatorCmd(f))
+	cmd.AddCommand(newVersionCmd(f))
+	cmd.AddCommand(newCompletionCmd(f))
+
+	cmd.PersistentFlags().StringVar(&flags.logFormat, "log-format", string(logging.FormatText), "Log format: text or json")
+	cmd.PersistentFlags().BoolVarP(&flags.verbose, "verbose", "v", false, "Log debug messages")
+	cmd.PersistentFlags().BoolVarP(&flags.quiet, "quiet", "q", false, "Do not report progress")
+	cmd.PersistentFlags().BoolVar(&flags.noColor, "no-color", false, "Disable colors, also set by the NO_COLOR environment variable")
+	cmd.PersistentFlags().StringVar(&flags.adapterConfigMap, "adapter-configmap", "", "Adapter ConfigMap as [namespace/]name (default discovered from the operator installation)")
+	cmd.PersistentFlags().StringVar(&flags.kube.Context, "context", "", "Kubeconfig context to use (default current)")
+	cmd.PersistentFlags().StringVar(&flags.kube.Cluster, "cluster", "", "Kubeconfig cluster to use instead of the one of the context")
+	cmd.PersistentFlags().StringVar(&flags.kube.User, "user", "", "Kubeconfig user to use instead of the one of the context")
+	cmd.PersistentFlags().StringVar(&flags.kube.Impersonate, "as", "", "User to impersonate")
+	cmd.PersistentFlags().StringArrayVar(&flags.kube.ImpersonateGroups, "as-group", nil, "Group to impersonate, can be repeated")
+	cmd.PersistentFlags().Float32Var(&flags.kube.QPS, "qps", rest.DefaultQPS, "Maximum requests per second to the API server")
+	cmd.PersistentFlags().IntVar(&flags.kube.Burst, "burst", rest.DefaultBurst, "Maximum burst of requests to the API server")
+	cmd.PersistentFlags().IntVar(&flags.kube.Retry.MaxRetries, "retries", k8s.DefaultRetries, "Retries of API requests failing for transient reasons, 0 to disable")
+	cmd.PersistentFlags().DurationVar(&flags.kube.Retry.Backoff, "retry-backoff", k8s.DefaultBackoff, "Delay before the first retry, doubled for each further one")
+	cmd.PersistentFlags().DurationVar(&flags.kube.Timeout, "request-timeout", 0, "Time limit of a single API request, e.g. 30s (default no limit)")
+	registerFlagCompletion(cmd, "context", completeKubeContexts(f))
+	cmd.CompletionOptions.DisableDefaultCmd = true
+
+	cmd.SetFlagErrorFunc(func(cmd *cobra.Command, err error) error {
+		return usageErrorf("%w", err)
+	})
+	usageArgs(cmd)
+	return cmd
 }
 
-// init is a default function to register commands.
-func init() {
-	var err error
-	cfg, err = config.LoadConfig()
-	if err != nil {
-		log.Fatalf("Failed to load config: %v", err)
+// applyRetryConfig takes retries and retry_backoff of the configuration file for retry flags of kube not set.
+func applyRetryConfig(cmd *cobra.Command, cfg *config.Config, kube *k8s.Options) error {
+	if cfg.Retries != nil && !cmd.Flags().Changed("retries") {
+		kube.Retry.MaxRetries = *cfg.Retries
+	}
+	if cfg.RetryBackoff != "" && !cmd.Flags().Changed("retry-backoff") {
+		backoff, err := time.ParseDuration(cfg.RetryBackoff)
+		if err != nil {
+			return usageErrorf("invalid retry_backoff in config file: %w", err)
+		}
+		kube.Retry.Backoff = backoff
+	}
+	if kube.Retry.MaxRetries < 0 || kube.Retry.Backoff < 0 {
+		return usageErrorf("retries and retry backoff must not be negative")
 	}
+	return nil
+}
+
+// Execute executes incoming command
+// The first SIGINT or SIGTERM cancels the context of the command, a second one kills the process.
+func Execute(logger *zap.SugaredLogger) {
+	log = logger
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		stop()
+		reporter.StopAll()
+	}()
 
-	configCmd.AddCommand(configGetCmd)
-	configCmd.AddCommand(configSetCmd)
+	root := newRootCmd(func(flags *rootFlags) Factory {
+		return newClientFactory(flags)
+	})
+	cmd, err := root.ExecuteContextC(ctx)
+	if err == nil {
+		return
+	}
+	log.Sync()
 
-	backupCmd.AddCommand(backupListCmd)
-	backupCmd.AddCommand(backupCreateCmd)
-	backupCmd.AddCommand(backupDeleteCmd)
-	backupCmd.AddCommand(backupUpdateCmd)
-	setupFlags()
+	if ctx.Err() != nil && exitCode(err) != exitInterrupted {
+		err = &cliError{code: exitInterrupted, err: fmt.Errorf("interrupted: %w", err)}
+	}
 
-	adapterCmd.AddCommand(adapterAddCmd)
-	adapterCmd.AddCommand(adapterDeleteCmd)
-	adapterCmd.AddCommand(adapterListCmd)
+	if strings.HasPrefix(err.Error(), "unknown command") {
+		err = usageErrorf("%w", err)
+	}
+	if exitCode(err) == exitUsage && hint(err) == "" {
+		err = withHint(err, fmt.Sprintf("Run '%s --help' for usage.", cmd.CommandPath()))
+	}
+	log.Debugf("Command failed: %+v", err)
+	noColor, _ := root.PersistentFlags().GetBool("no-color")
+	os.Exit(renderError(os.Stderr, err, !noColor && term.IsTerminal(int(os.Stderr.Fd()))))
+}
 
-	rootCmd.AddCommand(configCmd)
-	rootCmd.AddCommand(backupCmd)
-	rootCmd.AddCommand(adapterCmd)
+// usageArgs turns argument validation errors of c and its subcommands into usage errors.
+func usageArgs(c *cobra.Command) {
+	if validate := c.Args; validate != nil {
+		c.Args = func(cmd *cobra.Command, args []string) error {
+			if err := validate(cmd, args); err != nil {
+				return usageErrorf("%w", err)
+			}
+			return nil
+		}
+	}
+	for _, sub := range c.Commands() {
+		usageArgs(sub)
+	}
 }
@@ -0,0 +1,396 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/oiler-backup/cli/internal/adapters"
+	"github.com/oiler-backup/cli/internal/config"
+	"github.com/oiler-backup/cli/internal/k8s"
+	"github.com/oiler-backup/cli/internal/templates"
+	"github.com/spf13/cobra"
+	"k8s.io/client-go/kubernetes"
+)
+
+// templateAnnotation records the template a BackupRequest was created from.
+const templateAnnotation = "oiler.backup/template"
+
+// templateCreateFlags are the flags of template create.
+type templateCreateFlags struct {
+	source string
+	file   string
+	force  bool
+	// template holds fields given by flags, they override those of --file.
+	template    templates.Template
+	labels      []string
+	annotations []string
+}
+
+// newTemplateCmd returns the top-level command for actions with BackupRequest templates.
+func newTemplateCmd(f Factory) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "template",
+		Short: "Manage BackupRequest templates",
+		Long: `Manage named BackupRequest templates expanded by backup create --template.
+
+Templates are stored locally in ~/.oiler/templates or, to share them, in the ` + templates.ConfigMapName + ` ConfigMap
+in the operator namespace. A local template shadows a cluster one with the same name.
+String fields may reference variables as ${var}, set with backup create --var; ${name} is the BackupRequest name.`,
+	}
+
+	cmd.AddCommand(newTemplateListCmd(f))
+	cmd.AddCommand(newTemplateShowCmd(f))
+	cmd.AddCommand(newTemplateCreateCmd(f))
+	cmd.AddCommand(newTemplateDeleteCmd(f))
+	return cmd
+}
+
+// newTemplateListCmd returns a command that lists templates.
+func newTemplateListCmd(f Factory) *cobra.Command {
+	var source string
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List BackupRequest templates",
+		Long:  `List local and cluster BackupRequest templates.`,
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			stopFn := startSpinner("[1/3] Preparing")
+			stores, err := templateStores(ctx, f, source)
+			if err != nil {
+				stopFn()
+				return err
+			}
+			stopFn()
+
+			stopFn = startSpinner("[2/3] Getting templates")
+			type listedTemplate struct {
+				templates.Template
+				source templates.Source
+			}
+			var listed []listedTemplate
+			for _, store := range stores {
+				list, err := store.List(ctx)
+				if err != nil {
+					stopFn()
+					return fmt.Errorf("failed to list %s templates: %w", store.Source(), err)
+				}
+				for _, tmpl := range list {
+					listed = append(listed, listedTemplate{tmpl, store.Source()})
+				}
+			}
+			stopFn()
+
+			stopFn = startSpinner("[3/3] Generating results")
+			t := table.NewWriter()
+			t.SetOutputMirror(cmd.OutOrStdout())
+			t.SetStyle(table.StyleLight)
+			t.AppendHeader(table.Row{"#", "Template Name", "Source", "Description", "Schedule", "Max Backups", "Variables"})
+			for i, tmpl := range listed {
+				t.AppendRow(table.Row{i + 1, tmpl.Name, tmpl.source, tmpl.Description, tmpl.Schedule, tmpl.MaxBackupCount, strings.Join(tmpl.Variables(), ", ")})
+				t.AppendSeparator()
+			}
+			t.AppendFooter(table.Row{"", "", "", "", "", "TOTAL", len(listed)})
+			stopFn()
+			t.Render()
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&source, "source", "", "Only list local or cluster templates")
+	registerFlagCompletion(cmd, "source", completeTemplateSources)
+	return cmd
+}
+
+// newTemplateShowCmd returns a command that prints a template.
+func newTemplateShowCmd(f Factory) *cobra.Command {
+	var source string
+	cmd := &cobra.Command{
+		Use:   "show <name>",
+		Short: "Show a BackupRequest template",
+		Long:  `Print a BackupRequest template as YAML.`,
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			stores, err := templateStores(ctx, f, source)
+			if err != nil {
+				return err
+			}
+			tmpl, found, err := templates.Find(ctx, args[0], stores...)
+			if err != nil {
+				return err
+			}
+			data, err := templates.Encode(tmpl)
+			if err != nil {
+				return fmt.Errorf("failed to encode template: %w", err)
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "# source: %s\n", found)
+			if vars := tmpl.Variables(); len(vars) > 0 {
+				fmt.Fprintf(cmd.OutOrStdout(), "# variables: %s\n", strings.Join(vars, ", "))
+			}
+			cmd.OutOrStdout().Write(data)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&source, "source", "", "Only look up local or cluster templates")
+	cmd.ValidArgsFunction = completeTemplateNames(f)
+	registerFlagCompletion(cmd, "source", completeTemplateSources)
+	return cmd
+}
+
+// newTemplateCreateCmd returns a command that saves a template.
+func newTemplateCreateCmd(f Factory) *cobra.Command {
+	flags := &templateCreateFlags{}
+	cmd := &cobra.Command{
+		Use:   "create <name>",
+		Short: "Create a BackupRequest template",
+		Long: `Create a BackupRequest template from flags or from a YAML file.
+
+Fields: description, db, s3, schedule, maxBackupCount, labels and annotations. Flags override fields from --file.
+Credentials are never stored in templates.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			stopFn := startSpinner("[1/3] Preparing")
+			tmpl := flags.template
+			if flags.file != "" {
+				data, err := readInput(flags.file)
+				if err != nil {
+					stopFn()
+					return fmt.Errorf("failed to read %s: %w", flags.file, err)
+				}
+				tmpl, err = templates.Parse(data)
+				if err != nil {
+					stopFn()
+					return usageErrorf("invalid template in %s: %w", flags.file, err)
+				}
+				overrideTemplateFields(cmd, &tmpl, flags.template)
+			}
+			tmpl.Name = args[0]
+
+			labels, err := k8s.ParseKeyValues(flags.labels)
+			if err != nil {
+				stopFn()
+				return usageErrorf("invalid --label: %w", err)
+			}
+			annotations, err := k8s.ParseKeyValues(flags.annotations)
+			if err != nil {
+				stopFn()
+				return usageErrorf("invalid --annotation: %w", err)
+			}
+			tmpl.Labels = mergeMaps(tmpl.Labels, labels)
+			tmpl.Annotations = mergeMaps(tmpl.Annotations, annotations)
+			if err := templates.Validate(tmpl); err != nil {
+				stopFn()
+				return usageErrorf("invalid template: %w", err)
+			}
+
+			stores, err := templateStores(ctx, f, flags.source)
+			if err != nil {
+				stopFn()
+				return err
+			}
+			store := stores[0]
+			stopFn()
+
+			stopFn = startSpinner("[2/3] Checking existing templates")
+			_, err = store.Get(ctx, tmpl.Name)
+			if err == nil && !flags.force {
+				stopFn()
+				return conflictErrorf("template %s already exists in %s templates, use --force to replace it", tmpl.Name, store.Source())
+			}
+			if err != nil && !errors.Is(err, templates.ErrNotFound) {
+				stopFn()
+				return fmt.Errorf("failed to get template: %w", err)
+			}
+			stopFn()
+
+			stopFn = startSpinner("[3/3] Saving template")
+			if err := store.Save(ctx, tmpl); err != nil {
+				stopFn()
+				return fmt.Errorf("failed to save template: %w", err)
+			}
+			stopFn()
+			log.Infof("Successfully saved %s template %s", store.Source(), tmpl.Name)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&flags.source, "source", string(templates.SourceLocal), "Where to store the template: local or cluster")
+	cmd.Flags().StringVarP(&flags.file, "file", "f", "", "YAML file to read the template from, - for stdin")
+	cmd.Flags().BoolVar(&flags.force, "force", false, "Replace an existing template")
+	cmd.Flags().StringVar(&flags.template.Description, "description", "", "Description of the template")
+	cmd.Flags().StringVar(&flags.template.DB, "db", "", "DB specification in the format dbType@dbUri:dbPort/dbName, may use ${var}")
+	cmd.Flags().StringVar(&flags.template.S3, "s3", "", "S3 specification in the format [scheme://]endpoint:port/bucket, may use ${var}")
+	cmd.Flags().StringVar(&flags.template.Schedule, "schedule", "", "Cron schedule for backups")
+	cmd.Flags().Int64Var(&flags.template.MaxBackupCount, "max-backup-count", 0, "Maximum number of backups to retain")
+	cmd.Flags().StringArrayVar(&flags.labels, "label", nil, "Label as <key>=<value>, can be repeated")
+	cmd.Flags().StringArrayVar(&flags.annotations, "annotation", nil, "Annotation as <key>=<value>, can be repeated")
+	registerFlagCompletion(cmd, "db", completeDBSpec(f))
+	registerFlagCompletion(cmd, "source", completeTemplateSources)
+	return cmd
+}
+
+// newTemplateDeleteCmd returns a command that removes a template.
+func newTemplateDeleteCmd(f Factory) *cobra.Command {
+	var source string
+	cmd := &cobra.Command{
+		Use:   "delete <name>",
+		Short: "Delete a BackupRequest template",
+		Long:  `Delete a BackupRequest template. BackupRequests created from it are not affected.`,
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			stopFn := startSpinner("[1/2] Preparing")
+			stores, err := templateStores(ctx, f, source)
+			if err != nil {
+				stopFn()
+				return err
+			}
+			stopFn()
+
+			stopFn = startSpinner("[2/2] Deleting template")
+			if err := stores[0].Delete(ctx, args[0]); err != nil {
+				stopFn()
+				return fmt.Errorf("failed to delete template: %w", err)
+			}
+			stopFn()
+			log.Infof("Successfully deleted %s template %s", stores[0].Source(), args[0])
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&source, "source", string(templates.SourceLocal), "Where the template is stored: local or cluster")
+	cmd.ValidArgsFunction = completeTemplateNames(f)
+	registerFlagCompletion(cmd, "source", completeTemplateSources)
+	return cmd
+}
+
+// overrideTemplateFields sets fields of tmpl given explicitly by template create flags to those of flagged.
+func overrideTemplateFields(cmd *cobra.Command, tmpl *templates.Template, flagged templates.Template) {
+	flags := cmd.Flags()
+	if flags.Changed("description") {
+		tmpl.Description = flagged.Description
+	}
+	if flags.Changed("db") {
+		tmpl.DB = flagged.DB
+	}
+	if flags.Changed("s3") {
+		tmpl.S3 = flagged.S3
+	}
+	if flags.Changed("schedule") {
+		tmpl.Schedule = flagged.Schedule
+	}
+	if flags.Changed("max-backup-count") {
+		tmpl.MaxBackupCount = flagged.MaxBackupCount
+	}
+}
+
+// templateStores returns stores for source, or local and cluster stores if source is empty.
+func templateStores(ctx context.Context, f Factory, source string) ([]templates.Store, error) {
+	sources := []templates.Source{templates.SourceLocal, templates.SourceCluster}
+	if source != "" {
+		s, err := templates.ParseSource(source)
+		if err != nil {
+			return nil, usageErrorf("invalid --source: %w", err)
+		}
+		sources = []templates.Source{s}
+	}
+
+	stores := make([]templates.Store, 0, len(sources))
+	for _, s := range sources {
+		switch s {
+		case templates.SourceLocal:
+			dir, err := config.Dir()
+			if err != nil {
+				return nil, err
+			}
+			stores = append(stores, templates.LocalStore{Dir: filepath.Join(dir, "templates")})
+		case templates.SourceCluster:
+			cfg, err := f.Config()
+			if err != nil {
+				return nil, err
+			}
+			clientset, err := f.ClientSet()
+			if err != nil {
+				return nil, fmt.Errorf("failed to get client: %w", err)
+			}
+			store, err := clusterTemplateStore(ctx, clientset, cfg.Namespace)
+			if err != nil {
+				return nil, err
+			}
+			stores = append(stores, store)
+		}
+	}
+	return stores, nil
+}
+
+// clusterTemplateStore returns the store of cluster templates, kept in the operator namespace
+// next to the adapter ConfigMap rather than in the namespace of BackupRequests.
+func clusterTemplateStore(ctx context.Context, clientset kubernetes.Interface, namespace string) (templates.ClusterStore, error) {
+	loc, err := adapters.Locate(ctx, clientset, namespace, "")
+	if err != nil {
+		return templates.ClusterStore{}, fmt.Errorf("failed to find the operator namespace for templates: %w", err)
+	}
+	return templates.ClusterStore{Client: clientset, Namespace: loc.Namespace, Name: templates.ConfigMapName}, nil
+}
+
+// applyBackupTemplate fills backup create flags not set explicitly from --template.
+// It returns labels and annotations of the template, explicit --label and --annotation take precedence over them.
+func applyBackupTemplate(f Factory, cmd *cobra.Command, flags *backupCreateFlags) (map[string]string, map[string]string, error) {
+	vars, err := k8s.ParseKeyValues(flags.vars)
+	if err != nil {
+		return nil, nil, usageErrorf("invalid --var: %w", err)
+	}
+	vars[templates.NameVariable] = flags.name
+
+	stores, err := templateStores(cmd.Context(), f, "")
+	if err != nil {
+		return nil, nil, err
+	}
+	tmpl, _, err := templates.Find(cmd.Context(), flags.template, stores...)
+	if err != nil {
+		return nil, nil, err
+	}
+	tmpl, err = tmpl.Expand(vars)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if !cmd.Flags().Changed("db") && tmpl.DB != "" {
+		flags.db = tmpl.DB
+	}
+	if !cmd.Flags().Changed("s3") && tmpl.S3 != "" {
+		flags.s3 = tmpl.S3
+	}
+	if !cmd.Flags().Changed("schedule") && tmpl.Schedule != "" {
+		flags.schedule = tmpl.Schedule
+	}
+	if !cmd.Flags().Changed("max-backup-count") && tmpl.MaxBackupCount != 0 {
+		flags.maxBackupCount = tmpl.MaxBackupCount
+	}
+
+	annotations := mergeMaps(tmpl.Annotations, map[string]string{templateAnnotation: tmpl.Name})
+	return tmpl.Labels, annotations, nil
+}
+
+// mergeMaps returns entries of base overridden by entries of overrides.
+func mergeMaps(base, overrides map[string]string) map[string]string {
+	if len(base) == 0 && len(overrides) == 0 {
+		return nil
+	}
+	merged := make(map[string]string, len(base)+len(overrides))
+	for k, v := range base {
+		merged[k] = v
+	}
+	for k, v := range overrides {
+		merged[k] = v
+	}
+	return merged
+}
@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/oiler-backup/cli/internal/adapters"
+	"github.com/oiler-backup/cli/internal/templates"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestTemplateClusterStoreInOperatorNamespace(t *testing.T) {
+	f := newFakeFactory(t, testAdapters())
+	_, err := f.clientset.AppsV1().Deployments(testNamespace).Create(t.Context(), &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "manager", Namespace: testNamespace, Labels: map[string]string{"control-plane": "controller-manager"}},
+		Spec: appsv1.DeploymentSpec{Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{Name: "manager", Env: []corev1.EnvVar{{Name: adapters.OperatorNamespaceEnv, Value: "adapters"}}}},
+		}}},
+	}, metav1.CreateOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := runCmd(t, f, "template", "create", "gold", "--source", "cluster", "--schedule", "0 */6 * * *"); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := f.clientset.CoreV1().ConfigMaps("adapters").Get(t.Context(), templates.ConfigMapName, metav1.GetOptions{}); err != nil {
+		t.Fatalf("template ConfigMap in the operator namespace: %v", err)
+	}
+	_, err = f.clientset.CoreV1().ConfigMaps(testNamespace).Get(t.Context(), templates.ConfigMapName, metav1.GetOptions{})
+	if !apierrors.IsNotFound(err) {
+		t.Fatalf("template ConfigMap in the namespace of BackupRequests: err = %v, want not found", err)
+	}
+
+	out, err := runCmd(t, f, "template", "list", "--source", "cluster")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out, "gold") {
+		t.Fatalf("template list = %q, want gold", out)
+	}
+}
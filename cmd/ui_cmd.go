@@ -0,0 +1,736 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/jedib0t/go-pretty/v6/text"
+	"github.com/oiler-backup/cli/internal/adapters"
+	"github.com/oiler-backup/cli/internal/health"
+	"github.com/oiler-backup/cli/internal/tui"
+	"github.com/oiler-backup/cli/internal/watch"
+	backupv1 "github.com/oiler-backup/core/core/api/v1"
+	"github.com/spf13/cobra"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/yaml"
+)
+
+// Views of the dashboard.
+type uiView int
+
+const (
+	uiViewBackups uiView = iota
+	uiViewDetail
+	uiViewAdapters
+)
+
+// How much history the detail view shows.
+const (
+	uiRecentJobs = 5
+	uiLogLines   = 20
+)
+
+// Key help shown at the bottom of each view.
+var uiHelp = map[uiView]string{
+	uiViewBackups:  "↑↓ select  enter details  r run now  s suspend/resume  d delete  e edit  tab adapters  q quit",
+	uiViewDetail:   "esc back  l reload  r run now  s suspend/resume  d delete  e edit  tab adapters  q quit",
+	uiViewAdapters: "↑↓ select  h probe  tab/esc backups  q quit",
+}
+
+// An uiAdapter is a row of the adapters view. result is nil until probed.
+type uiAdapter struct {
+	name   string
+	url    string
+	result *health.Result
+}
+
+// An uiDetail holds what the detail view shows besides the BackupRequest itself.
+type uiDetail struct {
+	name    string
+	loading bool
+	jobs    []batchv1.Job
+	logJob  string
+	logs    []string
+	err     error
+}
+
+// An uiApp is the state of the dashboard. It is only changed by the main loop,
+// background work posts changes through updates.
+type uiApp struct {
+	// ctx ends with the dashboard, actions use it for API calls.
+	ctx       context.Context
+	screen    *tui.Screen
+	factory   Factory
+	dynClient dynamic.Interface
+	clientset kubernetes.Interface
+	// viaSpec tells whether suspension uses spec.suspend or the CronJobs, unknown if suspendErr is set.
+	viaSpec    bool
+	suspendErr error
+
+	view            uiView
+	rows            map[string]*watchedBackup
+	selected        int
+	detail          uiDetail
+	adapters        []uiAdapter
+	adaptersLoaded  bool
+	adapterSelected int
+
+	status  string
+	pending func()
+	updates chan func()
+}
+
+// newUICmd returns a command that opens the dashboard.
+func newUICmd(f Factory) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "ui",
+		Short: "Open a terminal dashboard",
+		Long: `Open a full-screen dashboard with BackupRequests and their live status, details with recent jobs
+and logs, and adapters with their health.
+
+BackupRequests can be run now, suspended or resumed, deleted and edited in $EDITOR from the dashboard.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, cancel := context.WithCancel(cmd.Context())
+			defer cancel()
+
+			stopFn := startSpinner("[1/2] Preparing")
+			dynClient, err := f.DynamicClient()
+			if err != nil {
+				stopFn()
+				return fmt.Errorf("failed to get client: %w", err)
+			}
+			clientset, err := f.ClientSet()
+			if err != nil {
+				stopFn()
+				return fmt.Errorf("failed to get client: %w", err)
+			}
+			viaSpec, suspendErr := suspendViaSpec(ctx, dynClient)
+			stopFn()
+
+			stopFn = startSpinner("[2/2] Starting watch")
+			events, err := watch.BackupRequests(ctx, dynClient, gvr, nil)
+			stopFn()
+			if err != nil {
+				return fmt.Errorf("failed to watch BackupRequests: %w", err)
+			}
+
+			screen, err := tui.Open()
+			if err != nil {
+				return fmt.Errorf("failed to open dashboard: %w", err)
+			}
+			app := &uiApp{
+				ctx:        ctx,
+				screen:     screen,
+				factory:    f,
+				dynClient:  dynClient,
+				clientset:  clientset,
+				viaSpec:    viaSpec,
+				suspendErr: suspendErr,
+				rows:       map[string]*watchedBackup{},
+				updates:    make(chan func(), 16),
+			}
+			app.run(ctx, events)
+			screen.Close()
+			return nil
+		},
+	}
+	return cmd
+}
+
+// run processes watch events, keys and background results until the user quits.
+func (a *uiApp) run(ctx context.Context, events <-chan watch.Event) {
+	keys := a.screen.Keys()
+	redraw := time.NewTicker(200 * time.Millisecond)
+	defer redraw.Stop()
+
+	dirty := true
+	width, height := a.screen.Size()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case e, ok := <-events:
+			if !ok {
+				return
+			}
+			applyWatchEvent(a.rows, e)
+			dirty = true
+		case k, ok := <-keys:
+			if !ok || !a.handleKey(k) {
+				return
+			}
+			dirty = true
+		case update := <-a.updates:
+			update()
+			dirty = true
+		case <-redraw.C:
+			if w, h := a.screen.Size(); w != width || h != height {
+				width, height = w, h
+				dirty = true
+			}
+			if dirty {
+				a.screen.Draw(a.render(width, height))
+				dirty = false
+			}
+		}
+	}
+}
+
+// handleKey reacts to a key press and reports whether the dashboard should keep running.
+func (a *uiApp) handleKey(k tui.Key) bool {
+	if k.Code == tui.KeyCtrlC || (k.Code == tui.KeyRune && k.Rune == 'q') {
+		return false
+	}
+	if a.pending != nil {
+		if k.Code == tui.KeyRune && (k.Rune == 'y' || k.Rune == 'Y') {
+			a.pending()
+		} else {
+			a.status = "Cancelled"
+		}
+		a.pending = nil
+		return true
+	}
+
+	switch a.view {
+	case uiViewBackups:
+		a.handleBackupsKey(k)
+	case uiViewDetail:
+		a.handleDetailKey(k)
+	case uiViewAdapters:
+		a.handleAdaptersKey(k)
+	}
+	return true
+}
+
+// handleBackupsKey handles keys of the BackupRequest list.
+func (a *uiApp) handleBackupsKey(k tui.Key) {
+	names := a.names()
+	a.selected = moveSelection(k, a.selected, len(names))
+	switch {
+	case k.Code == tui.KeyEnter && len(names) > 0:
+		a.openDetail(names[a.selected])
+	case k.Code == tui.KeyTab:
+		a.openAdapters()
+	default:
+		if len(names) > 0 {
+			a.handleActionKey(k, names[a.selected])
+		}
+	}
+}
+
+// handleDetailKey handles keys of the detail view.
+func (a *uiApp) handleDetailKey(k tui.Key) {
+	switch {
+	case k.Code == tui.KeyEsc || k.Code == tui.KeyBackspace || k.Code == tui.KeyLeft:
+		a.view = uiViewBackups
+	case k.Code == tui.KeyTab:
+		a.openAdapters()
+	case k.Code == tui.KeyRune && k.Rune == 'l':
+		a.openDetail(a.detail.name)
+	default:
+		a.handleActionKey(k, a.detail.name)
+	}
+}
+
+// handleAdaptersKey handles keys of the adapters view.
+func (a *uiApp) handleAdaptersKey(k tui.Key) {
+	a.adapterSelected = moveSelection(k, a.adapterSelected, len(a.adapters))
+	switch {
+	case k.Code == tui.KeyEsc || k.Code == tui.KeyTab || k.Code == tui.KeyBackspace:
+		a.view = uiViewBackups
+	case k.Code == tui.KeyRune && k.Rune == 'h':
+		a.loadAdapters()
+	}
+}
+
+// handleActionKey runs actions on the BackupRequest name.
+func (a *uiApp) handleActionKey(k tui.Key, name string) {
+	row, exists := a.rows[name]
+	if k.Code != tui.KeyRune || !exists {
+		return
+	}
+	br := row.br
+
+	switch k.Rune {
+	case 'r':
+		a.async(fmt.Sprintf("Starting a backup of %s", name), func() (string, error) {
+			job, err := runBackupNow(a.ctx, a.clientset, br)
+			if err != nil {
+				return "", err
+			}
+			return fmt.Sprintf("Started job %s for %s", job, name), nil
+		})
+	case 's':
+		suspend := br.Annotations[suspendedAnnotation] != "true"
+		done := "resumed"
+		if suspend {
+			done = "suspended"
+		}
+		a.async(fmt.Sprintf("Updating %s", name), func() (string, error) {
+			if a.suspendErr != nil {
+				return "", a.suspendErr
+			}
+			if err := suspendBackupRequest(a.ctx, a.dynClient, a.clientset, br, a.viaSpec, suspend, ""); err != nil {
+				return "", err
+			}
+			return fmt.Sprintf("Successfully %s %s", done, name), nil
+		})
+	case 'd':
+		a.status = fmt.Sprintf("Delete BackupRequest %s? [y/N]", name)
+		a.pending = func() {
+			a.view = uiViewBackups
+			a.async(fmt.Sprintf("Deleting %s", name), func() (string, error) {
+				if err := a.dynClient.Resource(gvr).Delete(a.ctx, name, metav1.DeleteOptions{}); err != nil {
+					return "", err
+				}
+				return fmt.Sprintf("Successfully deleted %s", name), nil
+			})
+		}
+	case 'e':
+		a.edit(name)
+	}
+}
+
+// async runs fn in the background showing progress, and its result or error when done.
+func (a *uiApp) async(progress string, fn func() (string, error)) {
+	a.status = progress + "..."
+	go func() {
+		message, err := fn()
+		a.updates <- func() {
+			if err != nil {
+				a.status = text.FgRed.Sprintf("Error: %v", err)
+				return
+			}
+			a.status = message
+		}
+	}()
+}
+
+// edit opens the BackupRequest in $EDITOR and updates it with the result.
+func (a *uiApp) edit(name string) {
+	item, err := a.dynClient.Resource(gvr).Get(a.ctx, name, metav1.GetOptions{})
+	if err != nil {
+		a.status = text.FgRed.Sprintf("Error: %v", err)
+		return
+	}
+	unstructured.RemoveNestedField(item.Object, "metadata", "managedFields")
+	content, err := yaml.Marshal(item.Object)
+	if err != nil {
+		a.status = text.FgRed.Sprintf("Error: %v", err)
+		return
+	}
+
+	a.screen.Suspend()
+	edited, err := editInEditor(content, "oiler-backup-*.yaml")
+	if resumeErr := a.screen.Resume(); resumeErr != nil && err == nil {
+		err = resumeErr
+	}
+	if err != nil {
+		a.status = text.FgRed.Sprintf("Error: %v", err)
+		return
+	}
+	if bytes.Equal(edited, content) {
+		a.status = "Edit cancelled, no changes made"
+		return
+	}
+
+	var obj unstructured.Unstructured
+	if err := yaml.Unmarshal(edited, &obj.Object); err != nil {
+		a.status = text.FgRed.Sprintf("Error: invalid YAML: %v", err)
+		return
+	}
+	a.async(fmt.Sprintf("Updating %s", name), func() (string, error) {
+		updated, err := a.dynClient.Resource(gvr).Update(a.ctx, &obj, metav1.UpdateOptions{})
+		if err != nil {
+			return "", err
+		}
+		if err := reapplySuspension(a.ctx, a.factory, a.dynClient, []unstructured.Unstructured{*updated}); err != nil {
+			return "", fmt.Errorf("updated %s, but %w", name, err)
+		}
+		return fmt.Sprintf("Successfully updated %s", name), nil
+	})
+}
+
+// openDetail shows the detail view of name and loads its recent jobs and logs.
+func (a *uiApp) openDetail(name string) {
+	a.view = uiViewDetail
+	a.detail = uiDetail{name: name, loading: true}
+	row, exists := a.rows[name]
+	if !exists {
+		a.detail.loading = false
+		return
+	}
+
+	cronJob := row.br.Status.CronJobData
+	go func() {
+		jobs, logJob, logs, err := loadBackupHistory(a.ctx, a.clientset, cronJob)
+		a.updates <- func() {
+			if a.detail.name != name {
+				return
+			}
+			a.detail = uiDetail{name: name, jobs: jobs, logJob: logJob, logs: logs, err: err}
+		}
+	}()
+}
+
+// openAdapters shows the adapters view, loading and probing adapters on first use.
+func (a *uiApp) openAdapters() {
+	a.view = uiViewAdapters
+	if !a.adaptersLoaded {
+		a.loadAdapters()
+	}
+}
+
+// loadAdapters reads the adapter ConfigMap and probes every adapter concurrently.
+func (a *uiApp) loadAdapters() {
+	a.adaptersLoaded = true
+	a.status = "Probing adapters..."
+	go func() {
+		cfg, err := a.factory.Config()
+		if err != nil {
+			a.updates <- func() { a.status = text.FgRed.Sprintf("Error: %v", err) }
+			return
+		}
+		configMap, err := getAdapterConfigMap(a.ctx, a.factory, a.clientset)
+		if err != nil {
+			a.updates <- func() { a.status = text.FgRed.Sprintf("Error: %v", err) }
+			return
+		}
+
+		names := adapters.Names(configMap.Data)
+		rows := make([]uiAdapter, 0, len(names))
+		for _, name := range names {
+			rows = append(rows, uiAdapter{name: name, url: configMap.Data[name]})
+		}
+		a.updates <- func() { a.adapters = rows }
+
+		var wg sync.WaitGroup
+		for i, row := range rows {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				result := probeAdapter(a.ctx, a.clientset, cfg.Namespace, row.url, defaultProbeFlags)
+				a.updates <- func() {
+					if i < len(a.adapters) && a.adapters[i].name == row.name {
+						a.adapters[i].result = &result
+					}
+				}
+			}()
+		}
+		wg.Wait()
+		a.updates <- func() { a.status = fmt.Sprintf("Probed %d adapter(s)", len(rows)) }
+	}()
+}
+
+// names returns watched BackupRequest names in display order.
+func (a *uiApp) names() []string {
+	names := make([]string, 0, len(a.rows))
+	for name := range a.rows {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// render returns screen lines of the current view.
+func (a *uiApp) render(width, height int) []string {
+	tabs := []string{" Backups ", " Adapters "}
+	active := 0
+	if a.view == uiViewAdapters {
+		active = 1
+	}
+	tabs[active] = text.Colors{text.ReverseVideo}.Sprint(tabs[active])
+	lines := []string{
+		text.Bold.Sprint("Oiler Backup") + "  " + strings.Join(tabs, " ") + "  " + time.Now().Format(time.TimeOnly),
+		"",
+	}
+
+	// Two lines are kept for the status and the key help.
+	bodyHeight := max(1, height-len(lines)-2)
+	var body []string
+	switch a.view {
+	case uiViewBackups:
+		body = a.renderBackups(bodyHeight)
+	case uiViewDetail:
+		body = a.renderDetail(width)
+	case uiViewAdapters:
+		body = a.renderAdapters(bodyHeight)
+	}
+	if len(body) > bodyHeight {
+		body = body[:bodyHeight]
+	}
+	lines = append(lines, body...)
+	for len(lines) < height-2 {
+		lines = append(lines, "")
+	}
+
+	return append(lines, a.status, text.Faint.Sprint(uiHelp[a.view]))
+}
+
+// renderBackups renders the BackupRequest list, scrolled to keep the selection visible.
+func (a *uiApp) renderBackups(height int) []string {
+	names := a.names()
+	if len(names) == 0 {
+		return []string{"No BackupRequests found"}
+	}
+	a.selected = min(a.selected, len(names)-1)
+
+	// Table borders, header and footer take 6 lines.
+	first, last := visibleRange(a.selected, len(names), max(1, height-6))
+	t := table.NewWriter()
+	t.SetStyle(table.StyleLight)
+	t.AppendHeader(table.Row{"#", "BackupRequest Name", "Database Type", "Schedule", "Suspended", "Status", "Last Backup"})
+	for i := first; i < last; i++ {
+		br := a.rows[names[i]].br
+		suspended, _ := suspensionState(br)
+		lastBackup := "-"
+		if br.Status.LastBackupTime != nil {
+			lastBackup = br.Status.LastBackupTime.Local().Format(time.DateTime)
+		}
+		status := statusColor(br.Status.Status).Sprint(statusOrDash(br.Status.Status))
+		t.AppendRow(table.Row{i + 1, br.Name, br.Spec.DbSpec.DbType, br.Spec.Schedule, suspended, status, lastBackup})
+	}
+	t.AppendFooter(table.Row{"", "", "", "", "", "TOTAL", len(names)})
+	t.SetRowPainter(func(row table.Row) text.Colors {
+		if row[1] == names[a.selected] {
+			return text.Colors{text.ReverseVideo}
+		}
+		return nil
+	})
+	return strings.Split(t.Render(), "\n")
+}
+
+// renderDetail renders spec, status, recent jobs and logs of the selected BackupRequest.
+func (a *uiApp) renderDetail(width int) []string {
+	row, exists := a.rows[a.detail.name]
+	if !exists {
+		return []string{fmt.Sprintf("BackupRequest %s no longer exists", a.detail.name)}
+	}
+	br := row.br
+	spec := br.Spec
+
+	suspended, _ := suspensionState(br)
+	lastBackup := "-"
+	if br.Status.LastBackupTime != nil {
+		lastBackup = br.Status.LastBackupTime.Local().Format(time.DateTime)
+	}
+	cronJob := "-"
+	if br.Status.CronJobData.Name != "" {
+		cronJob = br.Status.CronJobData.Namespace + "/" + br.Status.CronJobData.Name
+	}
+	lines := []string{
+		text.Bold.Sprint("BackupRequest " + br.Name),
+		fmt.Sprintf("  Database     %s@%s:%d/%s (user %s)", spec.DbSpec.DbType, spec.DbSpec.URI, spec.DbSpec.Port, spec.DbSpec.DbName, spec.DbSpec.User),
+		fmt.Sprintf("  S3           %s/%s", spec.S3Spec.Endpoint, spec.S3Spec.BucketName),
+		fmt.Sprintf("  Schedule     %s, keep %d backup(s)", spec.Schedule, spec.MaxBackupCount),
+		fmt.Sprintf("  Suspended    %s", suspended),
+		fmt.Sprintf("  Status       %s, last backup %s", statusColor(br.Status.Status).Sprint(statusOrDash(br.Status.Status)), lastBackup),
+		fmt.Sprintf("  CronJob      %s", cronJob),
+		"",
+		text.Bold.Sprint("Recent jobs"),
+	}
+
+	switch {
+	case a.detail.loading:
+		return append(lines, "  Loading...")
+	case a.detail.err != nil:
+		return append(lines, text.FgRed.Sprintf("  %v", a.detail.err))
+	case len(a.detail.jobs) == 0:
+		lines = append(lines, "  No jobs yet")
+	}
+	for _, job := range a.detail.jobs {
+		started := "-"
+		if job.Status.StartTime != nil {
+			started = job.Status.StartTime.Local().Format(time.DateTime)
+		}
+		result := jobResult(job)
+		lines = append(lines, fmt.Sprintf("  %-*s  %s  %s", min(width/2, 50), job.Name, started, statusColor(result).Sprint(result)))
+	}
+
+	if a.detail.logJob != "" {
+		lines = append(lines, "", text.Bold.Sprintf("Logs of %s", a.detail.logJob))
+		for _, line := range a.detail.logs {
+			lines = append(lines, "  "+line)
+		}
+	}
+	return lines
+}
+
+// renderAdapters renders adapters with their health.
+func (a *uiApp) renderAdapters(height int) []string {
+	if len(a.adapters) == 0 {
+		return []string{"No adapters loaded"}
+	}
+	a.adapterSelected = min(a.adapterSelected, len(a.adapters)-1)
+
+	first, last := visibleRange(a.adapterSelected, len(a.adapters), max(1, height-6))
+	t := table.NewWriter()
+	t.SetStyle(table.StyleLight)
+	t.AppendHeader(table.Row{"#", "Adapter Name", "URL", "Status", "Latency", "Version", "Details"})
+	unhealthy := 0
+	for _, adapter := range a.adapters {
+		if adapter.result != nil && !adapter.result.Healthy() {
+			unhealthy++
+		}
+	}
+	for i := first; i < last; i++ {
+		adapter := a.adapters[i]
+		status, latency, version, details := "probing...", "", "", ""
+		if r := adapter.result; r != nil {
+			status = healthColor(*r).Sprint(r.Status)
+			latency = r.Latency.Round(time.Millisecond).String()
+			version = r.Version
+			if r.Err != nil {
+				details = r.Err.Error()
+			}
+		}
+		t.AppendRow(table.Row{i + 1, adapter.name, adapter.url, status, latency, version, details})
+	}
+	t.AppendFooter(table.Row{"", "", "", "", "", "UNHEALTHY", fmt.Sprintf("%d/%d", unhealthy, len(a.adapters))})
+	t.SetRowPainter(func(row table.Row) text.Colors {
+		if row[1] == a.adapters[a.adapterSelected].name {
+			return text.Colors{text.ReverseVideo}
+		}
+		return nil
+	})
+	return strings.Split(t.Render(), "\n")
+}
+
+// healthColor picks a highlight for a probe result.
+func healthColor(r health.Result) text.Colors {
+	if r.Healthy() {
+		return text.Colors{text.FgGreen}
+	}
+	return text.Colors{text.FgRed, text.Bold}
+}
+
+// moveSelection applies navigation keys to selected among n rows.
+func moveSelection(k tui.Key, selected, n int) int {
+	if n == 0 {
+		return 0
+	}
+	switch {
+	case k.Code == tui.KeyUp || (k.Code == tui.KeyRune && k.Rune == 'k'):
+		selected--
+	case k.Code == tui.KeyDown || (k.Code == tui.KeyRune && k.Rune == 'j'):
+		selected++
+	case k.Code == tui.KeyPageUp:
+		selected -= 10
+	case k.Code == tui.KeyPageDown:
+		selected += 10
+	case k.Code == tui.KeyHome:
+		selected = 0
+	case k.Code == tui.KeyEnd:
+		selected = n - 1
+	}
+	return max(0, min(selected, n-1))
+}
+
+// visibleRange returns rows [first, last) to show so that selected fits into height rows.
+func visibleRange(selected, n, height int) (int, int) {
+	if n <= height {
+		return 0, n
+	}
+	first := max(0, min(selected-height/2, n-height))
+	return first, first + height
+}
+
+// jobResult describes a Job state.
+func jobResult(job batchv1.Job) string {
+	switch {
+	case job.Status.Succeeded > 0:
+		return "successful"
+	case job.Status.Failed > 0:
+		return "failed"
+	default:
+		return "running"
+	}
+}
+
+// loadBackupHistory returns recent Jobs of the CronJob, newest first, and the last log lines of the newest one.
+func loadBackupHistory(ctx context.Context, clientset kubernetes.Interface, cronJob backupv1.CreatedCronJobData) ([]batchv1.Job, string, []string, error) {
+	if cronJob.Name == "" {
+		return nil, "", nil, fmt.Errorf("the operator has not created a CronJob yet")
+	}
+
+	list, err := clientset.BatchV1().Jobs(cronJob.Namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("failed to list jobs: %w", err)
+	}
+	var jobs []batchv1.Job
+	for _, job := range list.Items {
+		if ownedByCronJob(&job, cronJob.Name) {
+			jobs = append(jobs, job)
+		}
+	}
+	sort.Slice(jobs, func(i, j int) bool {
+		return jobs[j].CreationTimestamp.Before(&jobs[i].CreationTimestamp)
+	})
+	if len(jobs) > uiRecentJobs {
+		jobs = jobs[:uiRecentJobs]
+	}
+	if len(jobs) == 0 {
+		return nil, "", nil, nil
+	}
+
+	latest := jobs[0]
+	pods, err := clientset.CoreV1().Pods(latest.Namespace).List(ctx, metav1.ListOptions{LabelSelector: "job-name=" + latest.Name})
+	if err != nil || len(pods.Items) == 0 {
+		return jobs, "", nil, nil
+	}
+	pod := pods.Items[len(pods.Items)-1]
+	tail := int64(uiLogLines)
+	raw, err := clientset.CoreV1().Pods(pod.Namespace).GetLogs(pod.Name, &corev1.PodLogOptions{TailLines: &tail}).DoRaw(ctx)
+	if err != nil {
+		return jobs, latest.Name, []string{fmt.Sprintf("failed to get logs: %v", err)}, nil
+	}
+	logs := strings.ReplaceAll(strings.TrimRight(string(raw), "\n"), "\t", "    ")
+	return jobs, latest.Name, strings.Split(strings.ReplaceAll(logs, "\r", ""), "\n"), nil
+}
+
+// runBackupNow starts a Job from the CronJob of br, like kubectl create job --from=cronjob/<name>.
+func runBackupNow(ctx context.Context, clientset kubernetes.Interface, br backupv1.BackupRequest) (string, error) {
+	ref := br.Status.CronJobData
+	if ref.Name == "" {
+		return "", fmt.Errorf("the operator has not created a CronJob yet")
+	}
+	cronJob, err := clientset.BatchV1().CronJobs(ref.Namespace).Get(ctx, ref.Name, metav1.GetOptions{})
+	if err != nil {
+		return "", fmt.Errorf("failed to get CronJob %s/%s: %w", ref.Namespace, ref.Name, err)
+	}
+
+	suffix := fmt.Sprintf("-manual-%d", time.Now().Unix())
+	prefix := cronJob.Name
+	if len(prefix)+len(suffix) > 63 {
+		prefix = prefix[:63-len(suffix)]
+	}
+	annotations := mergeMaps(cronJob.Spec.JobTemplate.Annotations, map[string]string{"cronjob.kubernetes.io/instantiate": "manual"})
+	job := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            prefix + suffix,
+			Namespace:       cronJob.Namespace,
+			Labels:          cronJob.Spec.JobTemplate.Labels,
+			Annotations:     annotations,
+			OwnerReferences: []metav1.OwnerReference{*metav1.NewControllerRef(cronJob, batchv1.SchemeGroupVersion.WithKind("CronJob"))},
+		},
+		Spec: cronJob.Spec.JobTemplate.Spec,
+	}
+
+	created, err := clientset.BatchV1().Jobs(cronJob.Namespace).Create(ctx, job, metav1.CreateOptions{})
+	if err != nil {
+		return "", fmt.Errorf("failed to create Job: %w", err)
+	}
+	return created.Name, nil
+}
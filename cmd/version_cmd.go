@@ -0,0 +1,222 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/oiler-backup/cli/internal/buildinfo"
+	"github.com/oiler-backup/cli/internal/operator"
+	backupv1 "github.com/oiler-backup/core/core/api/v1"
+	"github.com/spf13/cobra"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// Output formats of version.
+const (
+	versionFormatTable = "table"
+	versionFormatJSON  = "json"
+)
+
+// versionFlags are the flags of version.
+type versionFlags struct {
+	clientOnly bool
+	format     string
+}
+
+// A versionReport is what version prints.
+type versionReport struct {
+	Client clientVersion `json:"client"`
+	// Server is nil with --client or if the cluster could not be asked.
+	Server *serverVersion `json:"server,omitempty"`
+	// Warnings explain why the CLI and the installation may be incompatible.
+	Warnings []string `json:"warnings"`
+}
+
+// A clientVersion describes the build of oiler-cli.
+type clientVersion struct {
+	buildinfo.Info
+	// APIVersion is the BackupRequest API version oiler-cli was compiled against.
+	APIVersion string `json:"apiVersion"`
+}
+
+// A serverVersion describes the operator installation.
+type serverVersion struct {
+	Namespace string `json:"namespace"`
+	// OperatorVersion is empty if no operator Deployment is found.
+	OperatorVersion string `json:"operatorVersion,omitempty"`
+	OperatorImage   string `json:"operatorImage,omitempty"`
+	CRDInstalled    bool   `json:"crdInstalled"`
+	// CRDServedVersions are the versions the BackupRequest CRD serves.
+	CRDServedVersions []string `json:"crdServedVersions,omitempty"`
+	// CRDStorageVersion is the version new BackupRequests are stored in.
+	CRDStorageVersion string `json:"crdStorageVersion,omitempty"`
+	// CRDStoredVersions are all versions BackupRequests have ever been stored in.
+	CRDStoredVersions []string `json:"crdStoredVersions,omitempty"`
+}
+
+// newVersionCmd returns a command that prints versions of oiler-cli and the operator installation.
+func newVersionCmd(f Factory) *cobra.Command {
+	flags := &versionFlags{}
+	cmd := &cobra.Command{
+		Use:   "version",
+		Short: "Print client and server versions",
+		Long: `Print the version, commit and build date of oiler-cli, the BackupRequest API version it was
+compiled against, the operator version found in the cluster and the versions of the BackupRequest CRD.
+
+Warns if the operator or the CRD do not match what oiler-cli was built for. Works without the
+configuration file and the cluster with --client.`,
+		Args:        cobra.NoArgs,
+		Annotations: map[string]string{skipConfigAnnotation: "true"},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			switch flags.format {
+			case versionFormatTable, versionFormatJSON:
+			default:
+				return usageErrorf("unknown --format value %q, use %s or %s", flags.format, versionFormatTable, versionFormatJSON)
+			}
+
+			report := versionReport{
+				Client:   clientVersion{Info: buildinfo.Get(), APIVersion: backupv1.GroupVersion.String()},
+				Warnings: []string{},
+			}
+			if !flags.clientOnly {
+				stopFn := startSpinner("Getting server versions")
+				server, err := getServerVersion(ctx, f)
+				stopFn()
+				if err != nil {
+					log.Warnf("Cannot get server versions: %v", err)
+				} else {
+					report.Server = server
+					report.Warnings = compatibilityWarnings(server)
+				}
+			}
+			for _, warning := range report.Warnings {
+				log.Warn(warning)
+			}
+
+			if flags.format == versionFormatJSON {
+				encoder := json.NewEncoder(cmd.OutOrStdout())
+				encoder.SetIndent("", "  ")
+				return encoder.Encode(report)
+			}
+			renderVersionReport(cmd, report)
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&flags.clientOnly, "client", false, "Only print the version of oiler-cli, without asking the cluster")
+	cmd.Flags().StringVar(&flags.format, "format", versionFormatTable, "Output format: table or json")
+	registerFlagCompletion(cmd, "format", cobra.FixedCompletions([]string{versionFormatTable, versionFormatJSON}, cobra.ShellCompDirectiveNoFileComp))
+	return cmd
+}
+
+// getServerVersion detects the operator version and the BackupRequest CRD versions.
+func getServerVersion(ctx context.Context, f Factory) (*serverVersion, error) {
+	cfg, err := f.Config()
+	if err != nil {
+		return nil, err
+	}
+	clientset, err := f.ClientSet()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get client: %w", err)
+	}
+	dynClient, err := f.DynamicClient()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get client: %w", err)
+	}
+
+	server := &serverVersion{Namespace: cfg.Namespace}
+	installed, err := operator.Find(ctx, clientset, cfg.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	if installed != nil {
+		server.OperatorVersion = operator.InstalledVersion(installed)
+		server.OperatorImage = operator.ManagerImage(installed)
+	}
+
+	crd, err := dynClient.Resource(crdGVR).Get(ctx, backupRequestCRD, metav1.GetOptions{})
+	switch {
+	case apierrors.IsNotFound(err):
+		return server, nil
+	case err != nil:
+		return nil, fmt.Errorf("failed to get CRD %s: %w", backupRequestCRD, err)
+	}
+	server.CRDInstalled = true
+	server.CRDServedVersions, server.CRDStorageVersion = crdVersions(crd)
+	server.CRDStoredVersions, _, _ = unstructured.NestedStringSlice(crd.Object, "status", "storedVersions")
+	return server, nil
+}
+
+// compatibilityWarnings explains why oiler-cli may not work with the installation described by server.
+func compatibilityWarnings(server *serverVersion) []string {
+	warnings := []string{}
+	switch {
+	case !server.CRDInstalled:
+		warnings = append(warnings, fmt.Sprintf("CRD %s is not installed, install the operator with oiler-cli operator install", backupRequestCRD))
+	case !slices.Contains(server.CRDServedVersions, gvr.Version):
+		warnings = append(warnings, fmt.Sprintf("CRD %s serves %s, but oiler-cli was built for %s",
+			backupRequestCRD, strings.Join(orNone(server.CRDServedVersions), ", "), backupv1.GroupVersion))
+	}
+
+	switch {
+	case server.OperatorVersion == "":
+		warnings = append(warnings, fmt.Sprintf("No operator Deployment in namespace %s", server.Namespace))
+	case server.OperatorVersion == "unknown":
+		warnings = append(warnings, "The operator version is unknown, compatibility with oiler-cli cannot be checked")
+	case !slices.Contains(operator.Versions(), server.OperatorVersion):
+		warnings = append(warnings, fmt.Sprintf("Operator %s is not a version oiler-cli was built for (%s), upgrade the operator or oiler-cli",
+			server.OperatorVersion, strings.Join(operator.Versions(), ", ")))
+	}
+	return warnings
+}
+
+// renderVersionReport prints report as a table.
+func renderVersionReport(cmd *cobra.Command, report versionReport) {
+	t := table.NewWriter()
+	t.SetOutputMirror(cmd.OutOrStdout())
+	t.SetStyle(table.StyleLight)
+	t.AppendHeader(table.Row{"#", "Component", "Version"})
+	rows := []table.Row{
+		{"oiler-cli", report.Client.Version},
+		{"commit", report.Client.Commit},
+		{"build date", report.Client.BuildDate},
+		{"go", report.Client.GoVersion},
+		{"oiler-backup/core", report.Client.CoreVersion},
+		{"BackupRequest API", report.Client.APIVersion},
+	}
+	if server := report.Server; server != nil {
+		rows = append(rows,
+			table.Row{"operator", orDash(server.OperatorVersion)},
+			table.Row{"operator image", orDash(server.OperatorImage)},
+			table.Row{"CRD served versions", orDash(strings.Join(server.CRDServedVersions, ", "))},
+			table.Row{"CRD storage version", orDash(server.CRDStorageVersion)},
+			table.Row{"CRD stored versions", orDash(strings.Join(server.CRDStoredVersions, ", "))},
+		)
+	}
+	for i, row := range rows {
+		t.AppendRow(append(table.Row{i + 1}, row...))
+	}
+	if report.Server != nil {
+		compatible := "yes"
+		if len(report.Warnings) > 0 {
+			compatible = "no"
+		}
+		t.AppendFooter(table.Row{"", "COMPATIBLE", compatible})
+	}
+	t.Render()
+}
+
+// orDash replaces an empty value with a dash.
+func orDash(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return value
+}
@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/oiler-backup/cli/internal/operator"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// runVersion runs version with args and decodes its JSON report.
+func runVersion(t *testing.T, f *fakeFactory, args ...string) versionReport {
+	t.Helper()
+	out, err := runCmd(t, f, append([]string{"version", "--format", "json"}, args...)...)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var report versionReport
+	if err := json.Unmarshal([]byte(out), &report); err != nil {
+		t.Fatalf("invalid JSON report %q: %v", out, err)
+	}
+	return report
+}
+
+func TestVersionClient(t *testing.T) {
+	report := runVersion(t, newFakeFactory(t, nil), "--client")
+	if report.Client.APIVersion != "backup.oiler.backup/v1" {
+		t.Errorf("API version = %q, want backup.oiler.backup/v1", report.Client.APIVersion)
+	}
+	if report.Server != nil || len(report.Warnings) != 0 {
+		t.Errorf("--client reported server %+v and warnings %q", report.Server, report.Warnings)
+	}
+}
+
+func TestVersionCompatibility(t *testing.T) {
+	tests := []struct {
+		name            string
+		install         bool
+		operatorVersion string
+		wantWarnings    []string
+	}{
+		{name: "compatible", install: true, operatorVersion: operator.DefaultVersion},
+		{name: "unknown operator version", install: true, operatorVersion: "9.9.9", wantWarnings: []string{"Operator 9.9.9 is not a version"}},
+		{name: "not installed", wantWarnings: []string{"CRD backuprequests.backup.oiler.backup is not installed", "No operator Deployment"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newFakeFactory(t, nil)
+			if tt.install {
+				installOperator(t, f)
+				deployment, err := f.clientset.AppsV1().Deployments(testNamespace).Get(t.Context(), "oiler-controller-manager", metav1.GetOptions{})
+				if err != nil {
+					t.Fatal(err)
+				}
+				deployment.Labels[operator.VersionLabel] = tt.operatorVersion
+				if _, err := f.clientset.AppsV1().Deployments(testNamespace).Update(t.Context(), deployment, metav1.UpdateOptions{}); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			report := runVersion(t, f)
+			if report.Server == nil {
+				t.Fatal("no server versions reported")
+			}
+			if tt.install && (report.Server.OperatorVersion != tt.operatorVersion || report.Server.CRDStorageVersion != "v1") {
+				t.Errorf("server = %+v, want operator %s and CRD storage version v1", report.Server, tt.operatorVersion)
+			}
+			if len(report.Warnings) != len(tt.wantWarnings) {
+				t.Fatalf("warnings = %q, want %d", report.Warnings, len(tt.wantWarnings))
+			}
+			for i, want := range tt.wantWarnings {
+				if !strings.HasPrefix(report.Warnings[i], want) {
+					t.Errorf("warning %d = %q, want prefix %q", i, report.Warnings[i], want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,194 @@
+// Package adapters encodes, decodes, validates and compares sets of adapters
+// stored in the adapter ConfigMap, where each key is an adapter name and each
+// value is its URL.
+package adapters
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/oiler-backup/cli/internal/health"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"sigs.k8s.io/yaml"
+)
+
+// A Format is a file format adapters can be exported to or imported from.
+type Format string
+
+// Supported formats.
+const (
+	FormatYAML Format = "yaml"
+	FormatJSON Format = "json"
+	FormatEnv  Format = "env"
+)
+
+// ParseFormat validates format name.
+func ParseFormat(s string) (Format, error) {
+	switch Format(strings.ToLower(s)) {
+	case FormatYAML, "yml":
+		return FormatYAML, nil
+	case FormatJSON:
+		return FormatJSON, nil
+	case FormatEnv:
+		return FormatEnv, nil
+	default:
+		return "", fmt.Errorf("unknown format %q, use yaml, json or env", s)
+	}
+}
+
+// FormatFromPath guesses format from file extension, falling back to YAML.
+func FormatFromPath(path string) Format {
+	f, err := ParseFormat(strings.TrimPrefix(filepath.Ext(path), "."))
+	if err != nil {
+		return FormatYAML
+	}
+	return f
+}
+
+// Names returns adapter names sorted alphabetically.
+func Names(entries map[string]string) []string {
+	names := make([]string, 0, len(entries))
+	for name := range entries {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Encode serializes entries in format.
+func Encode(entries map[string]string, format Format) ([]byte, error) {
+	switch format {
+	case FormatJSON:
+		b, err := json.MarshalIndent(entries, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		return append(b, '\n'), nil
+	case FormatEnv:
+		var buf bytes.Buffer
+		for _, name := range Names(entries) {
+			fmt.Fprintf(&buf, "%s=%s\n", name, entries[name])
+		}
+		return buf.Bytes(), nil
+	default:
+		if len(entries) == 0 {
+			return []byte("{}\n"), nil
+		}
+		return yaml.Marshal(entries)
+	}
+}
+
+// Decode parses data in format into entries.
+// YAML and JSON inputs may also be a whole ConfigMap manifest, in which case its data is used.
+func Decode(data []byte, format Format) (map[string]string, error) {
+	if format == FormatEnv {
+		return decodeEnv(data)
+	}
+
+	var manifest struct {
+		Kind string            `json:"kind"`
+		Data map[string]string `json:"data"`
+	}
+	if err := yaml.Unmarshal(data, &manifest); err == nil && manifest.Kind == "ConfigMap" {
+		if manifest.Data == nil {
+			manifest.Data = map[string]string{}
+		}
+		return manifest.Data, nil
+	}
+
+	entries := map[string]string{}
+	if err := yaml.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("failed to parse adapters: %w", err)
+	}
+	return entries, nil
+}
+
+// decodeEnv parses NAME=URL lines, skipping blanks and # comments.
+func decodeEnv(data []byte) (map[string]string, error) {
+	entries := map[string]string{}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		text = strings.TrimPrefix(text, "export ")
+		parts := strings.SplitN(text, "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("line %d: expected <name>=<url>", line)
+		}
+		entries[strings.TrimSpace(parts[0])] = strings.Trim(strings.TrimSpace(parts[1]), `"'`)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// Validate checks that every name is a valid ConfigMap key and every URL is a valid adapter URL.
+func Validate(entries map[string]string) error {
+	var problems []string
+	for _, name := range Names(entries) {
+		if errs := validation.IsConfigMapKey(name); len(errs) > 0 {
+			problems = append(problems, fmt.Sprintf("%s: invalid name: %s", name, strings.Join(errs, "; ")))
+		}
+		if _, err := health.ParseURL(entries[name]); err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %v", name, err))
+		}
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("invalid adapters:\n  %s", strings.Join(problems, "\n  "))
+	}
+	return nil
+}
+
+// Changes describes how one set of adapters differs from another.
+type Changes struct {
+	Added   []string
+	Changed []string
+	Removed []string
+}
+
+// Empty reports whether there is nothing to change.
+func (c Changes) Empty() bool {
+	return len(c.Added) == 0 && len(c.Changed) == 0 && len(c.Removed) == 0
+}
+
+// Diff compares current adapters with desired ones.
+func Diff(current, desired map[string]string) Changes {
+	var c Changes
+	for _, name := range Names(desired) {
+		old, exists := current[name]
+		switch {
+		case !exists:
+			c.Added = append(c.Added, name)
+		case old != desired[name]:
+			c.Changed = append(c.Changed, name)
+		}
+	}
+	for _, name := range Names(current) {
+		if _, exists := desired[name]; !exists {
+			c.Removed = append(c.Removed, name)
+		}
+	}
+	return c
+}
+
+// Merge returns current adapters overlaid with incoming ones.
+func Merge(current, incoming map[string]string) map[string]string {
+	merged := make(map[string]string, len(current)+len(incoming))
+	for name, url := range current {
+		merged[name] = url
+	}
+	for name, url := range incoming {
+		merged[name] = url
+	}
+	return merged
+}
@@ -0,0 +1,101 @@
+package adapters
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// DefaultConfigMapName is the name of the adapter ConfigMap. The operator does not make it configurable.
+const DefaultConfigMapName = "database-config"
+
+// OperatorSelector matches the operator Deployment.
+const OperatorSelector = "control-plane=controller-manager"
+
+// OperatorNamespaceEnv is the operator env variable naming the namespace of the adapter ConfigMap.
+const OperatorNamespaceEnv = "OPERATOR_NAMESPACE"
+
+// DefaultOperatorNamespace is the namespace the operator reads the adapter ConfigMap from
+// when OperatorNamespaceEnv is not set.
+const DefaultOperatorNamespace = "oiler-backup-system"
+
+// A Location points at the adapter ConfigMap.
+type Location struct {
+	Namespace string
+	Name      string
+	// Source tells how the location was found.
+	Source string
+}
+
+// String returns namespace/name.
+func (l Location) String() string {
+	return l.Namespace + "/" + l.Name
+}
+
+// ParseRef splits a [namespace/]name reference, using defaultNamespace when it has no namespace.
+func ParseRef(ref, defaultNamespace string) (string, string) {
+	if ns, name, ok := strings.Cut(ref, "/"); ok {
+		return ns, name
+	}
+	return defaultNamespace, ref
+}
+
+// Locate finds the adapter ConfigMap.
+//
+// An explicit override ([namespace/]name) wins. Otherwise the ConfigMap is the one the operator
+// reads: DefaultConfigMapName in the namespace of the OperatorNamespaceEnv env variable of the
+// operator Deployment in namespace, DefaultOperatorNamespace if the variable is not set.
+// Without an operator Deployment, DefaultConfigMapName is looked up in namespace and then in
+// DefaultOperatorNamespace.
+func Locate(ctx context.Context, clientset kubernetes.Interface, namespace, override string) (Location, error) {
+	if override != "" {
+		ns, name := ParseRef(override, namespace)
+		return Location{Namespace: ns, Name: name, Source: "override"}, nil
+	}
+
+	deployments, err := clientset.AppsV1().Deployments(namespace).List(ctx, metav1.ListOptions{LabelSelector: OperatorSelector})
+	if err != nil {
+		return Location{}, fmt.Errorf("failed to list operator Deployments in %s: %w", namespace, err)
+	}
+	if len(deployments.Items) > 0 {
+		d := &deployments.Items[0]
+		ns, source := operatorNamespace(d)
+		return Location{Namespace: ns, Name: DefaultConfigMapName, Source: fmt.Sprintf("deployment %s %s", d.Name, source)}, nil
+	}
+
+	for _, ns := range []string{namespace, DefaultOperatorNamespace} {
+		_, err := clientset.CoreV1().ConfigMaps(ns).Get(ctx, DefaultConfigMapName, metav1.GetOptions{})
+		if err == nil {
+			return Location{Namespace: ns, Name: DefaultConfigMapName, Source: "default"}, nil
+		}
+		if !apierrors.IsNotFound(err) {
+			return Location{}, fmt.Errorf("failed to get ConfigMap %s/%s: %w", ns, DefaultConfigMapName, err)
+		}
+	}
+	return Location{}, fmt.Errorf("adapter ConfigMap is not found: no operator Deployment (%s) in namespace %s and no %s ConfigMap in %s or %s; set it with --adapter-configmap or 'config set adapter-config-map=<namespace>/<name>'",
+		OperatorSelector, namespace, DefaultConfigMapName, namespace, DefaultOperatorNamespace)
+}
+
+// operatorNamespace returns the namespace the operator Deployment d reads the adapter ConfigMap
+// from and a description of where it was found.
+func operatorNamespace(d *appsv1.Deployment) (string, string) {
+	for _, c := range d.Spec.Template.Spec.Containers {
+		for _, env := range c.Env {
+			if env.Name != OperatorNamespaceEnv {
+				continue
+			}
+			if env.Value != "" {
+				return env.Value, "env " + OperatorNamespaceEnv
+			}
+			if env.ValueFrom != nil && env.ValueFrom.FieldRef != nil && env.ValueFrom.FieldRef.FieldPath == "metadata.namespace" {
+				return d.Namespace, "env " + OperatorNamespaceEnv
+			}
+		}
+	}
+	return DefaultOperatorNamespace, "default of " + OperatorNamespaceEnv
+}
@@ -0,0 +1,70 @@
+package adapters
+
+import (
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+)
+
+// operatorDeployment returns an operator Deployment in namespace with env on its container.
+func operatorDeployment(namespace string, env ...corev1.EnvVar) *appsv1.Deployment {
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "manager", Namespace: namespace, Labels: map[string]string{"control-plane": "controller-manager"}},
+		Spec: appsv1.DeploymentSpec{Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{Name: "manager", Env: env}},
+		}}},
+	}
+}
+
+// configMap returns the adapter ConfigMap in namespace.
+func configMap(namespace string) *corev1.ConfigMap {
+	return &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: DefaultConfigMapName, Namespace: namespace}}
+}
+
+func TestLocate(t *testing.T) {
+	tests := []struct {
+		name     string
+		objects  []runtime.Object
+		override string
+		want     string
+		wantErr  bool
+	}{
+		{name: "override", override: "other/adapters", want: "other/adapters"},
+		{name: "override without namespace", override: "adapters", want: "backups/adapters"},
+		{
+			name:    "env value",
+			objects: []runtime.Object{operatorDeployment("backups", corev1.EnvVar{Name: OperatorNamespaceEnv, Value: "adapters"})},
+			want:    "adapters/" + DefaultConfigMapName,
+		},
+		{
+			name: "env field ref",
+			objects: []runtime.Object{operatorDeployment("backups", corev1.EnvVar{Name: OperatorNamespaceEnv, ValueFrom: &corev1.EnvVarSource{
+				FieldRef: &corev1.ObjectFieldSelector{FieldPath: "metadata.namespace"},
+			}})},
+			want: "backups/" + DefaultConfigMapName,
+		},
+		{
+			name:    "env default",
+			objects: []runtime.Object{operatorDeployment("backups"), configMap("backups")},
+			want:    DefaultOperatorNamespace + "/" + DefaultConfigMapName,
+		},
+		{name: "no operator", objects: []runtime.Object{configMap("backups")}, want: "backups/" + DefaultConfigMapName},
+		{name: "no operator, default namespace", objects: []runtime.Object{configMap(DefaultOperatorNamespace)}, want: DefaultOperatorNamespace + "/" + DefaultConfigMapName},
+		{name: "nothing", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			loc, err := Locate(t.Context(), k8sfake.NewSimpleClientset(tt.objects...), "backups", tt.override)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Locate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := loc.String(); !tt.wantErr && got != tt.want {
+				t.Errorf("Locate() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
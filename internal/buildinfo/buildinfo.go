@@ -0,0 +1,60 @@
+package buildinfo
+
+import (
+	"runtime"
+	"runtime/debug"
+)
+
+// Build information injected with -ldflags "-X github.com/oiler-backup/cli/internal/buildinfo.Version=...".
+// Values left empty are taken from the VCS information Go embeds when building from a checkout.
+var (
+	Version   = ""
+	Commit    = ""
+	BuildDate = ""
+)
+
+// coreModule is the module providing the BackupRequest API.
+const coreModule = "github.com/oiler-backup/core/core"
+
+// Info describes the build of oiler-cli.
+type Info struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	BuildDate string `json:"buildDate"`
+	GoVersion string `json:"goVersion"`
+	// CoreVersion is the version of the oiler-backup/core module compiled in.
+	CoreVersion string `json:"coreVersion"`
+}
+
+// Get returns the build information, "unknown" for what is not known.
+func Get() Info {
+	info := Info{Version: Version, Commit: Commit, BuildDate: BuildDate, GoVersion: runtime.Version()}
+	if build, ok := debug.ReadBuildInfo(); ok {
+		if info.Version == "" && build.Main.Version != "(devel)" {
+			info.Version = build.Main.Version
+		}
+		for _, s := range build.Settings {
+			switch {
+			case s.Key == "vcs.revision" && info.Commit == "":
+				info.Commit = s.Value
+			case s.Key == "vcs.time" && info.BuildDate == "":
+				info.BuildDate = s.Value
+			}
+		}
+		for _, dep := range build.Deps {
+			if dep.Path == coreModule {
+				info.CoreVersion = dep.Version
+				if dep.Replace != nil {
+					info.CoreVersion = dep.Replace.Version
+				}
+			}
+		}
+	}
+
+	for _, v := range []*string{&info.Version, &info.Commit, &info.BuildDate, &info.CoreVersion} {
+		if *v == "" {
+			*v = "unknown"
+		}
+	}
+	return info
+}
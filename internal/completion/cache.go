@@ -0,0 +1,101 @@
+// Package completion caches values offered by shell completion, so pressing TAB
+// does not wait for the cluster every time.
+package completion
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// DefaultTTL is how long cached values are offered without asking the cluster again.
+const DefaultTTL = 30 * time.Second
+
+// A Cache stores completion values as JSON files in a directory.
+type Cache struct {
+	Dir string
+	TTL time.Duration
+}
+
+// An entry is the content of a cache file.
+type entry struct {
+	Stored time.Time `json:"stored"`
+	Values []string  `json:"values"`
+}
+
+// Key builds a cache key from parts, e.g. the kind of values and the cluster they come from.
+func Key(parts ...string) string {
+	h := sha256.New()
+	for _, part := range parts {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))[:16]
+}
+
+// Get returns values stored under key if they are younger than TTL.
+func (c Cache) Get(key string) ([]string, bool) {
+	e, err := c.read(key)
+	if err != nil || time.Since(e.Stored) > c.TTL {
+		return nil, false
+	}
+	return e.Values, true
+}
+
+// Stale returns values stored under key regardless of their age.
+// It is the fallback when the cluster cannot be reached.
+func (c Cache) Stale(key string) ([]string, bool) {
+	e, err := c.read(key)
+	if err != nil {
+		return nil, false
+	}
+	return e.Values, true
+}
+
+// Put stores values under key.
+func (c Cache) Put(key string, values []string) error {
+	if err := os.MkdirAll(c.Dir, 0700); err != nil {
+		return fmt.Errorf("failed to create %s: %w", c.Dir, err)
+	}
+	data, err := json.Marshal(entry{Stored: time.Now(), Values: values})
+	if err != nil {
+		return err
+	}
+
+	// Write and rename, so a concurrent completion never reads half a file.
+	tmp, err := os.CreateTemp(c.Dir, key+"-*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to write cache: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write cache: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write cache: %w", err)
+	}
+	return os.Rename(tmp.Name(), c.path(key))
+}
+
+// read loads the entry stored under key.
+func (c Cache) read(key string) (entry, error) {
+	var e entry
+	data, err := os.ReadFile(c.path(key))
+	if err != nil {
+		return e, err
+	}
+	if err := json.Unmarshal(data, &e); err != nil {
+		return e, fmt.Errorf("corrupt cache file %s: %w", c.path(key), err)
+	}
+	return e, nil
+}
+
+// path returns the file of key.
+func (c Cache) path(key string) string {
+	return filepath.Join(c.Dir, key+".json")
+}
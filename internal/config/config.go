@@ -10,18 +10,31 @@ import (
 
 // A Config stores configuration.
 type Config struct {
-	KubeConfigPath string `mapstructure:"kube_config_path" json:"kube_config_path"`
-	Namespace      string `mapstructure:"namespace" json:"namespace"`
+	KubeConfigPath   string `mapstructure:"kube_config_path" json:"kube_config_path"`
+	Namespace        string `mapstructure:"namespace" json:"namespace"`
+	AdapterConfigMap string `mapstructure:"adapter_config_map" json:"adapter_config_map,omitempty"`
+	// Retries and RetryBackoff set defaults of --retries and --retry-backoff.
+	Retries      *int   `mapstructure:"retries" json:"retries,omitempty"`
+	RetryBackoff string `mapstructure:"retry_backoff" json:"retry_backoff,omitempty"`
+}
+
+// Dir returns the directory configuration file and other local state live in.
+func Dir() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get user home directory: %w", err)
+	}
+	return filepath.Join(home, ".oiler"), nil
 }
 
 // LoadConfig reads configuration file and fills Config up.
 func LoadConfig() (*Config, error) {
-	home, err := os.UserHomeDir()
+	dir, err := Dir()
 	if err != nil {
-		return nil, fmt.Errorf("failed to get user home directory: %w", err)
+		return nil, err
 	}
 
-	configPath := filepath.Join(home, ".oiler", ".config.json")
+	configPath := filepath.Join(dir, ".config.json")
 
 	viper.AddConfigPath(filepath.Dir(configPath))
 	viper.SetConfigName(filepath.Base(configPath[:len(configPath)-len(filepath.Ext(configPath))]))
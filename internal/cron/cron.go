@@ -0,0 +1,111 @@
+// Package cron validates cron schedules the way Kubernetes CronJobs accept them.
+package cron
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// macros are predefined schedules accepted instead of five fields.
+var macros = map[string]bool{
+	"@yearly":   true,
+	"@annually": true,
+	"@monthly":  true,
+	"@weekly":   true,
+	"@daily":    true,
+	"@midnight": true,
+	"@hourly":   true,
+}
+
+// A field describes allowed values of a schedule field.
+type field struct {
+	name     string
+	min, max int
+	names    []string
+}
+
+var fields = []field{
+	{name: "minute", min: 0, max: 59},
+	{name: "hour", min: 0, max: 23},
+	{name: "day of month", min: 1, max: 31},
+	{name: "month", min: 1, max: 12, names: []string{"jan", "feb", "mar", "apr", "may", "jun", "jul", "aug", "sep", "oct", "nov", "dec"}},
+	{name: "day of week", min: 0, max: 7, names: []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}},
+}
+
+// Validate checks a standard five-field schedule or a macro such as @daily.
+// A CRON_TZ= or TZ= prefix is rejected, as Kubernetes takes the time zone only from spec.timeZone.
+func Validate(schedule string) error {
+	schedule = strings.TrimSpace(schedule)
+	if strings.HasPrefix(schedule, "CRON_TZ=") || strings.HasPrefix(schedule, "TZ=") {
+		return fmt.Errorf("schedule %q sets a time zone, which Kubernetes rejects in a schedule; use spec.timeZone instead", schedule)
+	}
+
+	if strings.HasPrefix(schedule, "@") {
+		if !macros[schedule] {
+			return fmt.Errorf("unknown schedule macro %q", schedule)
+		}
+		return nil
+	}
+
+	parts := strings.Fields(schedule)
+	if len(parts) != len(fields) {
+		return fmt.Errorf("schedule %q must have %d fields: minute hour day-of-month month day-of-week", schedule, len(fields))
+	}
+	for i, part := range parts {
+		if err := fields[i].validate(part); err != nil {
+			return fmt.Errorf("invalid %s %q: %w", fields[i].name, part, err)
+		}
+	}
+	return nil
+}
+
+// validate checks a comma-separated list of ranges with optional steps.
+func (f field) validate(expr string) error {
+	for _, item := range strings.Split(expr, ",") {
+		rng, step, hasStep := strings.Cut(item, "/")
+		if hasStep {
+			n, err := strconv.Atoi(step)
+			if err != nil || n <= 0 {
+				return fmt.Errorf("step %q is not a positive number", step)
+			}
+		}
+
+		if rng == "*" || (rng == "?" && strings.HasPrefix(f.name, "day")) {
+			continue
+		}
+		low, high, isRange := strings.Cut(rng, "-")
+		lo, err := f.value(low)
+		if err != nil {
+			return err
+		}
+		if !isRange {
+			continue
+		}
+		hi, err := f.value(high)
+		if err != nil {
+			return err
+		}
+		if lo > hi {
+			return fmt.Errorf("range %s is reversed", rng)
+		}
+	}
+	return nil
+}
+
+// value parses a number or a name of a month or weekday.
+func (f field) value(s string) (int, error) {
+	for i, name := range f.names {
+		if strings.EqualFold(s, name) {
+			return i + f.min, nil
+		}
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("%q is not a number", s)
+	}
+	if n < f.min || n > f.max {
+		return 0, fmt.Errorf("%d is out of range %d-%d", n, f.min, f.max)
+	}
+	return n, nil
+}
@@ -0,0 +1,59 @@
+package cron
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name     string
+		schedule string
+		wantErr  string
+	}{
+		{name: "every minute", schedule: "* * * * *"},
+		{name: "numbers", schedule: "30 2 15 6 1"},
+		{name: "surrounding space", schedule: "  0 4 * * *  "},
+		{name: "ranges", schedule: "0-30 9-17 1-15 1-6 1-5"},
+		{name: "lists", schedule: "0,15,30,45 0,12 * * *"},
+		{name: "steps", schedule: "*/15 0-23/2 */3 * *"},
+		{name: "month names", schedule: "0 0 1 jan-mar,OCT *"},
+		{name: "weekday names", schedule: "0 9 * * mon-fri"},
+		{name: "question mark", schedule: "0 0 ? * ?"},
+		{name: "daily macro", schedule: "@daily"},
+		{name: "hourly macro", schedule: "@hourly"},
+		{name: "annually macro", schedule: "@annually"},
+
+		{name: "unknown macro", schedule: "@fortnightly", wantErr: "unknown schedule macro"},
+		{name: "too few fields", schedule: "0 4 * *", wantErr: "must have 5 fields"},
+		{name: "too many fields", schedule: "0 0 4 * * *", wantErr: "must have 5 fields"},
+		{name: "minute out of range", schedule: "60 * * * *", wantErr: "invalid minute"},
+		{name: "hour out of range", schedule: "0 24 * * *", wantErr: "out of range 0-23"},
+		{name: "day of month zero", schedule: "0 0 0 * *", wantErr: "invalid day of month"},
+		{name: "month out of range", schedule: "0 0 1 13 *", wantErr: "out of range 1-12"},
+		{name: "range end out of range", schedule: "0 0 * * 1-8", wantErr: "invalid day of week"},
+		{name: "reversed range", schedule: "0 17-9 * * *", wantErr: "range 17-9 is reversed"},
+		{name: "reversed names", schedule: "0 0 * * fri-mon", wantErr: "is reversed"},
+		{name: "zero step", schedule: "*/0 * * * *", wantErr: "step \"0\" is not a positive number"},
+		{name: "negative step", schedule: "*/-5 * * * *", wantErr: "is not a positive number"},
+		{name: "unknown name", schedule: "0 0 1 foo *", wantErr: "\"foo\" is not a number"},
+		{name: "weekday name in month", schedule: "0 0 1 mon *", wantErr: "invalid month"},
+		{name: "question mark in hour", schedule: "0 ? * * *", wantErr: "invalid hour"},
+		{name: "CRON_TZ prefix", schedule: "CRON_TZ=Europe/Berlin 0 4 * * *", wantErr: "use spec.timeZone"},
+		{name: "TZ prefix", schedule: "TZ=UTC @daily", wantErr: "use spec.timeZone"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Validate(tt.schedule)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Validate(%q) = %v, want no error", tt.schedule, err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("Validate(%q) = %v, want an error containing %q", tt.schedule, err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,65 @@
+package doctor
+
+import "encoding/json"
+
+// A Severity tells how bad a Finding is.
+type Severity int
+
+// Severities from harmless to broken.
+const (
+	OK Severity = iota
+	Info
+	Warning
+	Error
+)
+
+// severityNames are the names of severities in reports.
+var severityNames = []string{"ok", "info", "warning", "error"}
+
+// String returns the name of s, e.g. "warning".
+func (s Severity) String() string {
+	if s < OK || s > Error {
+		return "unknown"
+	}
+	return severityNames[s]
+}
+
+// MarshalJSON encodes s by its name.
+func (s Severity) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.String())
+}
+
+// A Finding is the outcome of a check, with a hint on how to fix it unless it is OK.
+type Finding struct {
+	Check    string   `json:"check"`
+	Severity Severity `json:"severity"`
+	Message  string   `json:"message"`
+	Hint     string   `json:"hint,omitempty"`
+}
+
+// A Report collects findings of all checks in the order they were made.
+type Report struct {
+	Findings []Finding `json:"findings"`
+	// Counts holds the number of findings by severity name.
+	Counts map[string]int `json:"counts"`
+}
+
+// NewReport returns an empty Report.
+func NewReport() *Report {
+	counts := make(map[string]int, len(severityNames))
+	for _, name := range severityNames {
+		counts[name] = 0
+	}
+	return &Report{Findings: []Finding{}, Counts: counts}
+}
+
+// Add records a finding of check.
+func (r *Report) Add(check string, severity Severity, message, hint string) {
+	r.Findings = append(r.Findings, Finding{Check: check, Severity: severity, Message: message, Hint: hint})
+	r.Counts[severity.String()]++
+}
+
+// Count returns the number of findings with severity.
+func (r *Report) Count(severity Severity) int {
+	return r.Counts[severity.String()]
+}
@@ -0,0 +1,171 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
+)
+
+// Default images used by ProbeViaPod.
+const (
+	DefaultHTTPProbeImage = "busybox:1.36"
+	DefaultGRPCProbeImage = "ghcr.io/grpc-ecosystem/grpc-health-probe:v0.4.28"
+)
+
+// cleanupTimeout bounds deleting the probe pod.
+const cleanupTimeout = 10 * time.Second
+
+// PodOptions configures probing from a temporary pod.
+type PodOptions struct {
+	HTTPImage      string
+	GRPCImage      string
+	Timeout        time.Duration
+	StartupTimeout time.Duration
+}
+
+// ServiceRef splits an in-cluster host name into service name and namespace.
+// Namespace falls back to defaultNamespace for short names.
+func ServiceRef(host, defaultNamespace string) (string, string) {
+	parts := strings.Split(host, ".")
+	if len(parts) > 1 && parts[1] != "svc" {
+		return parts[0], parts[1]
+	}
+	return parts[0], defaultNamespace
+}
+
+// ProbeViaProxy checks an HTTP adapter through the API-server service proxy.
+func ProbeViaProxy(ctx context.Context, clientset kubernetes.Interface, defaultNamespace string, u *url.URL, timeout time.Duration) Result {
+	if u.Scheme != SchemeHTTP && u.Scheme != SchemeHTTPS {
+		return Result{Status: StatusUnknown, Err: fmt.Errorf("gRPC adapters cannot be probed through the API-server proxy, use pod mode")}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	name, namespace := ServiceRef(u.Hostname(), defaultNamespace)
+	port := u.Port()
+	target, _ := url.Parse(HTTPTarget(u))
+
+	start := time.Now()
+	body, err := clientset.CoreV1().Services(namespace).
+		ProxyGet(u.Scheme, name, port, target.Path, nil).
+		DoRaw(ctx)
+	latency := time.Since(start)
+
+	if err != nil {
+		var statusErr *apierrors.StatusError
+		if apierrors.IsNotFound(err) || !errors.As(err, &statusErr) {
+			return Result{Status: StatusUnreachable, Latency: latency, Err: err}
+		}
+		res := ParseHTTPResponse(int(statusErr.ErrStatus.Code), "", body)
+		res.Latency = latency
+		return res
+	}
+
+	res := ParseHTTPResponse(200, "", body)
+	res.Latency = latency
+	return res
+}
+
+// ProbeViaPod checks the adapter from a short-lived pod in namespace.
+// The pod is removed once the probe completes.
+func ProbeViaPod(ctx context.Context, clientset kubernetes.Interface, namespace string, u *url.URL, opts PodOptions) Result {
+	seconds := strconv.Itoa(max(1, int(opts.Timeout.Seconds())))
+	container := corev1.Container{Name: "probe"}
+	switch u.Scheme {
+	case SchemeHTTP, SchemeHTTPS:
+		container.Image = opts.HTTPImage
+		container.Command = []string{"wget", "-q", "-T", seconds, "-O", "-", HTTPTarget(u)}
+	default:
+		container.Image = opts.GRPCImage
+		container.Args = []string{"-addr=" + u.Host, "-connect-timeout=" + seconds + "s", "-rpc-timeout=" + seconds + "s"}
+		if u.Scheme == SchemeGRPCS {
+			container.Args = append(container.Args, "-tls")
+		}
+	}
+
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: "oiler-adapter-probe-",
+			Namespace:    namespace,
+			Labels: map[string]string{
+				"app.kubernetes.io/managed-by": "oiler-cli",
+				"app.kubernetes.io/component":  "adapter-probe",
+			},
+		},
+		Spec: corev1.PodSpec{
+			RestartPolicy: corev1.RestartPolicyNever,
+			Containers:    []corev1.Container{container},
+		},
+	}
+
+	created, err := clientset.CoreV1().Pods(namespace).Create(ctx, pod, metav1.CreateOptions{})
+	if err != nil {
+		return Result{Status: StatusUnknown, Err: fmt.Errorf("failed to create probe pod: %w", err)}
+	}
+	defer func() {
+		// Clean up even if ctx was cancelled, but do not hang on an unresponsive API server.
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), cleanupTimeout)
+		defer cancel()
+		_ = clientset.CoreV1().Pods(namespace).Delete(ctx, created.Name, metav1.DeleteOptions{})
+	}()
+
+	var terminated *corev1.ContainerStateTerminated
+	err = wait.PollUntilContextTimeout(ctx, time.Second, opts.StartupTimeout+opts.Timeout, true, func(ctx context.Context) (bool, error) {
+		p, err := clientset.CoreV1().Pods(namespace).Get(ctx, created.Name, metav1.GetOptions{})
+		if err != nil {
+			return false, err
+		}
+		for _, cs := range p.Status.ContainerStatuses {
+			if cs.State.Terminated != nil {
+				terminated = cs.State.Terminated
+				return true, nil
+			}
+		}
+		return false, nil
+	})
+	if err != nil {
+		return Result{Status: StatusUnknown, Err: fmt.Errorf("probe pod did not complete: %w", err)}
+	}
+
+	res := Result{Latency: terminated.FinishedAt.Sub(terminated.StartedAt.Time)}
+	logs, _ := clientset.CoreV1().Pods(namespace).GetLogs(created.Name, &corev1.PodLogOptions{}).DoRaw(ctx)
+
+	if u.Scheme == SchemeHTTP || u.Scheme == SchemeHTTPS {
+		if terminated.ExitCode != 0 {
+			res.Status = StatusUnreachable
+			res.Err = fmt.Errorf("wget exited with %d: %s", terminated.ExitCode, strings.TrimSpace(string(logs)))
+			return res
+		}
+		parsed := ParseHTTPResponse(200, "", logs)
+		parsed.Latency = res.Latency
+		return parsed
+	}
+
+	// Exit codes are documented by grpc-health-probe.
+	switch terminated.ExitCode {
+	case 0:
+		res.Status = StatusServing
+	case 2:
+		res.Status = StatusUnreachable
+	case 4:
+		res.Status = StatusNotServing
+	default:
+		res.Status = StatusUnknown
+	}
+	if terminated.ExitCode != 0 {
+		res.Err = fmt.Errorf("%s", strings.TrimSpace(string(logs)))
+	}
+
+	return res
+}
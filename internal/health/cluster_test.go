@@ -0,0 +1,22 @@
+package health
+
+import "testing"
+
+func TestServiceRef(t *testing.T) {
+	tests := []struct {
+		host          string
+		wantName      string
+		wantNamespace string
+	}{
+		{host: "adapter", wantName: "adapter", wantNamespace: "default"},
+		{host: "adapter.backups", wantName: "adapter", wantNamespace: "backups"},
+		{host: "adapter.backups.svc.cluster.local", wantName: "adapter", wantNamespace: "backups"},
+		{host: "adapter.svc", wantName: "adapter", wantNamespace: "default"},
+	}
+	for _, tt := range tests {
+		name, namespace := ServiceRef(tt.host, "default")
+		if name != tt.wantName || namespace != tt.wantNamespace {
+			t.Errorf("ServiceRef(%s) = %s, %s, want %s, %s", tt.host, name, namespace, tt.wantName, tt.wantNamespace)
+		}
+	}
+}
@@ -0,0 +1,310 @@
+// Package health validates adapter URLs and probes adapter endpoints.
+//
+// Adapters are reached either over plain HTTP(S), where a GET on the health
+// endpoint is expected to answer 2xx, or over gRPC, where the standard
+// grpc.health.v1.Health/Check protocol is used.
+package health
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// Supported adapter URL schemes.
+const (
+	SchemeHTTP  = "http"
+	SchemeHTTPS = "https"
+	SchemeGRPC  = "grpc"
+	SchemeGRPCS = "grpcs"
+)
+
+// Statuses reported by a probe.
+const (
+	StatusServing        = "SERVING"
+	StatusNotServing     = "NOT_SERVING"
+	StatusServiceUnknown = "SERVICE_UNKNOWN"
+	StatusUnknown        = "UNKNOWN"
+	StatusUnimplemented  = "UNIMPLEMENTED"
+	StatusUnreachable    = "UNREACHABLE"
+)
+
+// DefaultHTTPPath is probed when an HTTP adapter URL has no path.
+const DefaultHTTPPath = "/healthz"
+
+// VersionHeader is the header (or gRPC metadata key) adapters may use to report their version.
+const VersionHeader = "X-Adapter-Version"
+
+const grpcHealthCheckPath = "/grpc.health.v1.Health/Check"
+
+// A Result is an outcome of a single probe.
+type Result struct {
+	Status  string
+	Version string
+	Latency time.Duration
+	Err     error
+}
+
+// Healthy reports whether the adapter answered as serving.
+func (r Result) Healthy() bool {
+	return r.Status == StatusServing
+}
+
+// ParseURL validates raw adapter URL and returns it normalized.
+// A bare host:port is treated as a gRPC address.
+func ParseURL(raw string) (*url.URL, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return nil, fmt.Errorf("adapter URL is empty")
+	}
+	normalized := raw
+	if !strings.Contains(raw, "://") {
+		normalized = SchemeGRPC + "://" + raw
+	}
+
+	u, err := url.Parse(normalized)
+	if err != nil {
+		return nil, fmt.Errorf("invalid adapter URL %q: %w", raw, err)
+	}
+
+	switch u.Scheme {
+	case SchemeHTTP, SchemeHTTPS:
+	case SchemeGRPC, SchemeGRPCS:
+		if u.Port() == "" {
+			return nil, fmt.Errorf("invalid adapter URL %q: gRPC address must contain a port", raw)
+		}
+		if u.Path != "" && u.Path != "/" {
+			return nil, fmt.Errorf("invalid adapter URL %q: gRPC address must not contain a path", raw)
+		}
+	default:
+		return nil, fmt.Errorf("invalid adapter URL %q: unsupported scheme %q", raw, u.Scheme)
+	}
+
+	if u.Hostname() == "" {
+		return nil, fmt.Errorf("invalid adapter URL %q: host is empty", raw)
+	}
+	if p := u.Port(); p != "" {
+		port, err := strconv.Atoi(p)
+		if err != nil || port < 1 || port > 65535 {
+			return nil, fmt.Errorf("invalid adapter URL %q: port %q is out of range", raw, p)
+		}
+	}
+
+	return u, nil
+}
+
+// HTTPTarget returns the URL an HTTP probe should GET.
+func HTTPTarget(u *url.URL) string {
+	target := *u
+	if target.Path == "" || target.Path == "/" {
+		target.Path = DefaultHTTPPath
+	}
+	return target.String()
+}
+
+// Probe checks adapter at u directly from the current host.
+func Probe(ctx context.Context, u *url.URL, timeout time.Duration) Result {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	start := time.Now()
+	var res Result
+	switch u.Scheme {
+	case SchemeHTTP, SchemeHTTPS:
+		res = probeHTTP(ctx, u)
+	default:
+		res = probeGRPC(ctx, u)
+	}
+	res.Latency = time.Since(start)
+	if res.Err != nil && res.Status == "" {
+		res.Status = StatusUnreachable
+	}
+
+	return res
+}
+
+// probeHTTP performs GET on the adapter health endpoint.
+func probeHTTP(ctx context.Context, u *url.URL) Result {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, HTTPTarget(u), nil)
+	if err != nil {
+		return Result{Err: err}
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return Result{Err: err}
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	return ParseHTTPResponse(resp.StatusCode, resp.Header.Get(VersionHeader), body)
+}
+
+// ParseHTTPResponse turns an HTTP health answer into Result.
+// A JSON body with "status" and "version" keys takes precedence over the status code.
+func ParseHTTPResponse(code int, version string, body []byte) Result {
+	res := Result{Status: StatusNotServing, Version: version}
+	if code >= 200 && code < 300 {
+		res.Status = StatusServing
+	} else {
+		res.Err = fmt.Errorf("health endpoint returned %d", code)
+	}
+
+	var payload struct {
+		Status  string `json:"status"`
+		Version string `json:"version"`
+	}
+	if err := json.Unmarshal(body, &payload); err == nil {
+		if payload.Version != "" {
+			res.Version = payload.Version
+		}
+		switch strings.ToUpper(payload.Status) {
+		case "":
+		case "OK", "UP", StatusServing:
+			res.Status = StatusServing
+		default:
+			res.Status = StatusNotServing
+		}
+	}
+
+	return res
+}
+
+// probeGRPC calls grpc.health.v1.Health/Check over HTTP/2.
+func probeGRPC(ctx context.Context, u *url.URL) Result {
+	var protocols http.Protocols
+	transport := &http.Transport{
+		DialContext: (&net.Dialer{}).DialContext,
+		Protocols:   &protocols,
+	}
+	scheme := SchemeHTTP
+	if u.Scheme == SchemeGRPCS {
+		scheme = SchemeHTTPS
+		protocols.SetHTTP2(true)
+		transport.TLSClientConfig = &tls.Config{ServerName: u.Hostname()}
+	} else {
+		protocols.SetUnencryptedHTTP2(true)
+	}
+	defer transport.CloseIdleConnections()
+
+	// HealthCheckRequest with an empty service name is an empty message.
+	frame := make([]byte, 5)
+	target := scheme + "://" + u.Host + grpcHealthCheckPath
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target, bytes.NewReader(frame))
+	if err != nil {
+		return Result{Err: err}
+	}
+	req.Header.Set("Content-Type", "application/grpc")
+	req.Header.Set("TE", "trailers")
+
+	resp, err := (&http.Client{Transport: transport}).Do(req)
+	if err != nil {
+		return Result{Err: err}
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	if err != nil {
+		return Result{Err: err}
+	}
+	if resp.StatusCode != http.StatusOK {
+		return Result{Err: fmt.Errorf("gRPC endpoint returned HTTP %d", resp.StatusCode)}
+	}
+
+	version := resp.Header.Get(VersionHeader)
+	grpcStatus := resp.Trailer.Get("Grpc-Status")
+	grpcMessage := resp.Trailer.Get("Grpc-Message")
+	if grpcStatus == "" {
+		// Trailers-Only responses carry the status in headers.
+		grpcStatus = resp.Header.Get("Grpc-Status")
+		grpcMessage = resp.Header.Get("Grpc-Message")
+	}
+
+	switch grpcStatus {
+	case "0":
+	case "12":
+		return Result{Status: StatusUnimplemented, Version: version, Err: fmt.Errorf("adapter does not implement grpc.health.v1")}
+	case "":
+		return Result{Err: fmt.Errorf("gRPC response has no grpc-status")}
+	default:
+		return Result{Status: StatusNotServing, Version: version, Err: fmt.Errorf("gRPC status %s: %s", grpcStatus, grpcMessage)}
+	}
+
+	status, err := decodeHealthCheckResponse(body)
+	if err != nil {
+		return Result{Version: version, Err: err}
+	}
+	res := Result{Status: status, Version: version}
+	if status != StatusServing {
+		res.Err = fmt.Errorf("adapter reported %s", status)
+	}
+
+	return res
+}
+
+// decodeHealthCheckResponse parses a length-prefixed grpc.health.v1.HealthCheckResponse.
+func decodeHealthCheckResponse(body []byte) (string, error) {
+	if len(body) < 5 {
+		return "", fmt.Errorf("gRPC response is too short")
+	}
+	if body[0] != 0 {
+		return "", fmt.Errorf("compressed gRPC responses are not supported")
+	}
+	size := binary.BigEndian.Uint32(body[1:5])
+	msg := body[5:]
+	if uint32(len(msg)) < size {
+		return "", fmt.Errorf("gRPC response is truncated")
+	}
+	msg = msg[:size]
+
+	status := StatusUnknown
+	for len(msg) > 0 {
+		num, typ, n := protowire.ConsumeTag(msg)
+		if n < 0 {
+			return "", protowire.ParseError(n)
+		}
+		msg = msg[n:]
+		if num == 1 && typ == protowire.VarintType {
+			v, n := protowire.ConsumeVarint(msg)
+			if n < 0 {
+				return "", protowire.ParseError(n)
+			}
+			status = servingStatus(v)
+			msg = msg[n:]
+			continue
+		}
+		n = protowire.ConsumeFieldValue(num, typ, msg)
+		if n < 0 {
+			return "", protowire.ParseError(n)
+		}
+		msg = msg[n:]
+	}
+
+	return status, nil
+}
+
+// servingStatus maps grpc.health.v1.HealthCheckResponse.ServingStatus to its name.
+func servingStatus(v uint64) string {
+	switch v {
+	case 1:
+		return StatusServing
+	case 2:
+		return StatusNotServing
+	case 3:
+		return StatusServiceUnknown
+	default:
+		return StatusUnknown
+	}
+}
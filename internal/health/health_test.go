@@ -0,0 +1,250 @@
+package health
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	grpchealth "google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestParseURL(t *testing.T) {
+	tests := []struct {
+		raw     string
+		want    string
+		wantErr bool
+	}{
+		{raw: "postgres-adapter:50051", want: "grpc://postgres-adapter:50051"},
+		{raw: " grpc://adapter.backups.svc:50051 ", want: "grpc://adapter.backups.svc:50051"},
+		{raw: "grpcs://adapter:443", want: "grpcs://adapter:443"},
+		{raw: "grpc://adapter:50051/", want: "grpc://adapter:50051/"},
+		{raw: "http://adapter", want: "http://adapter"},
+		{raw: "https://adapter:8443/health", want: "https://adapter:8443/health"},
+		{raw: "", wantErr: true},
+		{raw: "adapter", wantErr: true},
+		{raw: "grpc://adapter", wantErr: true},
+		{raw: "grpc://adapter:50051/health", wantErr: true},
+		{raw: "ftp://adapter:21", wantErr: true},
+		{raw: "http://:8080", wantErr: true},
+		{raw: "adapter:0", wantErr: true},
+		{raw: "adapter:65536", wantErr: true},
+		{raw: "http://adapter:port", wantErr: true},
+	}
+	for _, tt := range tests {
+		u, err := ParseURL(tt.raw)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("ParseURL(%q) = %s, want an error", tt.raw, u)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParseURL(%q) error = %v", tt.raw, err)
+			continue
+		}
+		if u.String() != tt.want {
+			t.Errorf("ParseURL(%q) = %s, want %s", tt.raw, u, tt.want)
+		}
+	}
+}
+
+func TestHTTPTarget(t *testing.T) {
+	tests := map[string]string{
+		"http://adapter:8080":          "http://adapter:8080/healthz",
+		"http://adapter:8080/":         "http://adapter:8080/healthz",
+		"https://adapter/ready?full=1": "https://adapter/ready?full=1",
+	}
+	for raw, want := range tests {
+		u, err := url.Parse(raw)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := HTTPTarget(u); got != want {
+			t.Errorf("HTTPTarget(%s) = %s, want %s", raw, got, want)
+		}
+	}
+}
+
+func TestParseHTTPResponse(t *testing.T) {
+	tests := []struct {
+		name        string
+		code        int
+		version     string
+		body        string
+		wantStatus  string
+		wantVersion string
+		wantErr     bool
+	}{
+		{name: "ok", code: 200, version: "1.0.0", wantStatus: StatusServing, wantVersion: "1.0.0"},
+		{name: "plain body", code: 204, body: "ok", wantStatus: StatusServing},
+		{name: "server error", code: 503, wantStatus: StatusNotServing, wantErr: true},
+		{name: "json up", code: 200, body: `{"status":"up","version":"2.1.0"}`, wantStatus: StatusServing, wantVersion: "2.1.0"},
+		{name: "json version wins", code: 200, version: "1.0.0", body: `{"version":"2.1.0"}`, wantStatus: StatusServing, wantVersion: "2.1.0"},
+		{name: "json down", code: 200, body: `{"status":"DOWN"}`, wantStatus: StatusNotServing},
+		{name: "json serving on error", code: 500, body: `{"status":"SERVING"}`, wantStatus: StatusServing, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := ParseHTTPResponse(tt.code, tt.version, []byte(tt.body))
+			if res.Status != tt.wantStatus || res.Version != tt.wantVersion {
+				t.Errorf("ParseHTTPResponse() = %s %q, want %s %q", res.Status, res.Version, tt.wantStatus, tt.wantVersion)
+			}
+			if (res.Err != nil) != tt.wantErr {
+				t.Errorf("ParseHTTPResponse() error = %v, wantErr %v", res.Err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestProbeHTTP(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(VersionHeader, "1.2.3")
+		_, _ = w.Write([]byte(`{"status":"UP"}`))
+	})
+	mux.HandleFunc("/broken", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "database is down", http.StatusServiceUnavailable)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	tests := []struct {
+		name        string
+		raw         string
+		wantStatus  string
+		wantVersion string
+		wantErr     bool
+	}{
+		{name: "default path", raw: server.URL, wantStatus: StatusServing, wantVersion: "1.2.3"},
+		{name: "custom path", raw: server.URL + "/broken", wantStatus: StatusNotServing, wantErr: true},
+		{name: "unknown path", raw: server.URL + "/missing", wantStatus: StatusNotServing, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u, err := ParseURL(tt.raw)
+			if err != nil {
+				t.Fatal(err)
+			}
+			res := Probe(t.Context(), u, 5*time.Second)
+			if res.Status != tt.wantStatus || res.Version != tt.wantVersion {
+				t.Errorf("Probe(%s) = %s %q, want %s %q", u, res.Status, res.Version, tt.wantStatus, tt.wantVersion)
+			}
+			if (res.Err != nil) != tt.wantErr {
+				t.Errorf("Probe(%s) error = %v, wantErr %v", u, res.Err, tt.wantErr)
+			}
+			if res.Latency <= 0 {
+				t.Errorf("Probe(%s) latency = %v, want it measured", u, res.Latency)
+			}
+		})
+	}
+}
+
+func TestProbeUnreachable(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := listener.Addr().String()
+	listener.Close()
+
+	for _, raw := range []string{"http://" + addr, addr} {
+		u, err := ParseURL(raw)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res := Probe(t.Context(), u, 5*time.Second); res.Status != StatusUnreachable || res.Err == nil {
+			t.Errorf("Probe(%s) = %s, %v, want %s with an error", u, res.Status, res.Err, StatusUnreachable)
+		}
+	}
+}
+
+// serveGRPC starts a gRPC server on a local port and returns its address.
+// register adds services to it; the server stops with the test.
+func serveGRPC(t *testing.T, register func(*grpc.Server)) string {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := grpc.NewServer(grpc.UnaryInterceptor(func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		_ = grpc.SetHeader(ctx, metadata.Pairs(VersionHeader, "3.0.0"))
+		return handler(ctx, req)
+	}))
+	register(server)
+	go func() { _ = server.Serve(listener) }()
+	t.Cleanup(server.Stop)
+	return listener.Addr().String()
+}
+
+func TestProbeGRPC(t *testing.T) {
+	tests := []struct {
+		name        string
+		status      healthpb.HealthCheckResponse_ServingStatus
+		noHealth    bool
+		wantStatus  string
+		wantVersion string
+		wantErr     bool
+	}{
+		{name: "serving", status: healthpb.HealthCheckResponse_SERVING, wantStatus: StatusServing, wantVersion: "3.0.0"},
+		{name: "not serving", status: healthpb.HealthCheckResponse_NOT_SERVING, wantStatus: StatusNotServing, wantVersion: "3.0.0", wantErr: true},
+		{name: "unimplemented", noHealth: true, wantStatus: StatusUnimplemented, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			addr := serveGRPC(t, func(server *grpc.Server) {
+				if tt.noHealth {
+					return
+				}
+				healthServer := grpchealth.NewServer()
+				healthServer.SetServingStatus("", tt.status)
+				healthpb.RegisterHealthServer(server, healthServer)
+			})
+			u, err := ParseURL(addr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			res := Probe(t.Context(), u, 5*time.Second)
+			if res.Status != tt.wantStatus || res.Version != tt.wantVersion {
+				t.Errorf("Probe(%s) = %s %q, want %s %q (err: %v)", u, res.Status, res.Version, tt.wantStatus, tt.wantVersion, res.Err)
+			}
+			if (res.Err != nil) != tt.wantErr {
+				t.Errorf("Probe(%s) error = %v, wantErr %v", u, res.Err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestDecodeHealthCheckResponse(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    []byte
+		want    string
+		wantErr bool
+	}{
+		{name: "serving", body: []byte{0, 0, 0, 0, 2, 0x08, 1}, want: StatusServing},
+		{name: "service unknown", body: []byte{0, 0, 0, 0, 2, 0x08, 3}, want: StatusServiceUnknown},
+		{name: "empty message", body: []byte{0, 0, 0, 0, 0}, want: StatusUnknown},
+		{name: "unknown field", body: []byte{0, 0, 0, 0, 4, 0x10, 7, 0x08, 2}, want: StatusNotServing},
+		{name: "too short", body: []byte{0, 0}, wantErr: true},
+		{name: "compressed", body: []byte{1, 0, 0, 0, 0}, wantErr: true},
+		{name: "truncated", body: []byte{0, 0, 0, 0, 4, 0x08}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := decodeHealthCheckResponse(tt.body)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("decodeHealthCheckResponse() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("decodeHealthCheckResponse() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
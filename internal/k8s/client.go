@@ -3,59 +3,168 @@ package k8s
 import (
 	"fmt"
 	"path/filepath"
+	"sync"
+	"time"
 
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
-	"k8s.io/client-go/util/homedir"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
 )
 
-// GetClient returns Clientset based on kubeconfig file.
-func GetClient(kubeConfigPath string) (*kubernetes.Clientset, error) {
-	if kubeConfigPath == "" {
-		if home := homedir.HomeDir(); home != "" {
-			kubeConfigPath = filepath.Join(home, ".kube", "config")
-		} else {
-			return nil, fmt.Errorf("kubeconfig path not provided and unable to determine home directory")
-		}
+// Options select the kubeconfig entries and client settings, like the kubectl flags of the same names.
+// Zero values keep what the kubeconfig or client-go defaults say.
+type Options struct {
+	// KubeConfigPath is a kubeconfig file, or several separated by os.PathListSeparator to merge.
+	// Empty means $KUBECONFIG, then ~/.kube/config.
+	KubeConfigPath string
+	// Context, Cluster and User override entries of the current context.
+	Context string
+	Cluster string
+	User    string
+	// Impersonate and ImpersonateGroups act as another user and groups.
+	Impersonate       string
+	ImpersonateGroups []string
+	// Timeout bounds a single request to the API server.
+	Timeout time.Duration
+	// QPS and Burst limit the rate of requests.
+	QPS   float32
+	Burst int
+	// Retry retries requests failing for transient reasons.
+	Retry RetryPolicy
+}
+
+// A Client builds Kubernetes clients from Options once and reuses them for the lifetime of the process.
+// It is safe for concurrent use.
+type Client struct {
+	opts Options
+
+	mu        sync.Mutex
+	config    *rest.Config
+	clientset kubernetes.Interface
+	dynClient dynamic.Interface
+}
+
+// NewClient returns a Client for opts. Nothing is loaded until a client is requested.
+func NewClient(opts Options) *Client {
+	return &Client{opts: opts}
+}
+
+// RESTConfig returns the client configuration.
+// The in-cluster configuration is used only if no kubeconfig is found, like kubectl does.
+func (c *Client) RESTConfig() (*rest.Config, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.restConfig()
+}
+
+// restConfig is RESTConfig for callers holding mu.
+func (c *Client) restConfig() (*rest.Config, error) {
+	if c.config != nil {
+		return c.config, nil
 	}
 
-	// Use the current context in kubeconfig
-	config, err := clientcmd.BuildConfigFromFlags("", kubeConfigPath)
+	config, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(c.loadingRules(), c.overrides()).ClientConfig()
 	if err != nil {
-		return nil, fmt.Errorf("error building kubeconfig: %v", err)
+		return nil, fmt.Errorf("failed to load kubeconfig: %w", err)
+	}
+	if c.opts.Timeout > 0 {
+		config.Timeout = c.opts.Timeout
+	}
+	if c.opts.QPS > 0 {
+		config.QPS = c.opts.QPS
+	}
+	if c.opts.Burst > 0 {
+		config.Burst = c.opts.Burst
+	}
+	config.Wrap(c.opts.Retry.WrapTransport)
+	c.config = config
+	return config, nil
+}
+
+// ClientSet returns a typed client.
+func (c *Client) ClientSet() (kubernetes.Interface, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.clientset != nil {
+		return c.clientset, nil
 	}
 
-	// Create a new clientset
+	config, err := c.restConfig()
+	if err != nil {
+		return nil, err
+	}
 	clientset, err := kubernetes.NewForConfig(config)
 	if err != nil {
-		return nil, fmt.Errorf("error creating clientset: %v", err)
+		return nil, fmt.Errorf("failed to create clientset: %w", err)
 	}
-
+	c.clientset = clientset
 	return clientset, nil
 }
 
-// GetCustomResourceClient returns client to work with CustomResources.
-func GetCustomResourceClient(kubeConfigPath, groupVersion, resource string) (rest.Interface, error) {
-	if kubeConfigPath == "" {
-		if home := homedir.HomeDir(); home != "" {
-			kubeConfigPath = filepath.Join(home, ".kube", "config")
-		} else {
-			return nil, fmt.Errorf("kubeconfig path not provided and unable to determine home directory")
-		}
+// DynamicClient returns a dynamic client.
+func (c *Client) DynamicClient() (dynamic.Interface, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.dynClient != nil {
+		return c.dynClient, nil
 	}
 
-	// Use the current context in kubeconfig
-	config, err := clientcmd.BuildConfigFromFlags("", kubeConfigPath)
+	config, err := c.restConfig()
 	if err != nil {
-		return nil, fmt.Errorf("error building kubeconfig: %v", err)
+		return nil, err
 	}
+	dynClient, err := dynamic.NewForConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create dynamic client: %w", err)
+	}
+	c.dynClient = dynClient
+	return dynClient, nil
+}
+
+// RawConfig returns the merged kubeconfig, e.g. to list its contexts.
+func (c *Client) RawConfig() (clientcmdapi.Config, error) {
+	return clientcmd.NewNonInteractiveDeferredLoadingClientConfig(c.loadingRules(), c.overrides()).RawConfig()
+}
 
-	// Create a REST client
-	restClient, err := rest.RESTClientFor(config)
+// CurrentContext returns the name of the context in use, empty for the in-cluster configuration.
+func (c *Client) CurrentContext() string {
+	if c.opts.Context != "" {
+		return c.opts.Context
+	}
+	raw, err := c.RawConfig()
 	if err != nil {
-		return nil, fmt.Errorf("error creating REST client: %v", err)
+		return ""
 	}
+	return raw.CurrentContext
+}
 
-	return restClient, nil
+// loadingRules returns where to read the kubeconfig from.
+// A single KubeConfigPath must exist, files of a list are merged like $KUBECONFIG.
+func (c *Client) loadingRules() *clientcmd.ClientConfigLoadingRules {
+	rules := clientcmd.NewDefaultClientConfigLoadingRules()
+	switch paths := filepath.SplitList(c.opts.KubeConfigPath); len(paths) {
+	case 0:
+	case 1:
+		rules.ExplicitPath = paths[0]
+	default:
+		rules.Precedence = paths
+	}
+	return rules
+}
+
+// overrides returns changes of the kubeconfig requested by Options.
+func (c *Client) overrides() *clientcmd.ConfigOverrides {
+	return &clientcmd.ConfigOverrides{
+		CurrentContext: c.opts.Context,
+		Context: clientcmdapi.Context{
+			Cluster:  c.opts.Cluster,
+			AuthInfo: c.opts.User,
+		},
+		AuthInfo: clientcmdapi.AuthInfo{
+			Impersonate:       c.opts.Impersonate,
+			ImpersonateGroups: c.opts.ImpersonateGroups,
+		},
+	}
 }
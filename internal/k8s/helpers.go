@@ -20,3 +20,44 @@ func UpdateField(obj map[string]any, parts []string, value any) error {
 
 	return UpdateField(nextObj, parts[1:], value)
 }
+
+// ParseKeyValues splits key=value pairs into a map. Used for --label and --annotation flags.
+func ParseKeyValues(pairs []string) (map[string]string, error) {
+	result := make(map[string]string, len(pairs))
+	for _, pair := range pairs {
+		key, value, ok := strings.Cut(pair, "=")
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid pair %q, use <key>=<value>", pair)
+		}
+		result[key] = value
+	}
+	return result, nil
+}
+
+// ParseMetadataChanges splits kubectl-style arguments into keys to set (key=value) and keys to remove (key-).
+func ParseMetadataChanges(args []string) (map[string]string, []string, error) {
+	set := map[string]string{}
+	var remove []string
+	for _, arg := range args {
+		if key, ok := strings.CutSuffix(arg, "-"); ok && !strings.Contains(arg, "=") {
+			if key == "" {
+				return nil, nil, fmt.Errorf("invalid removal %q, use <key>-", arg)
+			}
+			remove = append(remove, key)
+			continue
+		}
+		pairs, err := ParseKeyValues([]string{arg})
+		if err != nil {
+			return nil, nil, err
+		}
+		for key, value := range pairs {
+			set[key] = value
+		}
+	}
+	return set, remove, nil
+}
+
+// IsMetadataChange reports whether arg looks like key=value or key- rather than an object name.
+func IsMetadataChange(arg string) bool {
+	return strings.Contains(arg, "=") || strings.HasSuffix(arg, "-")
+}
@@ -0,0 +1,163 @@
+package k8s
+
+import (
+	"errors"
+	"io"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+// Defaults of RetryPolicy.
+const (
+	DefaultRetries    = 3
+	DefaultBackoff    = 500 * time.Millisecond
+	DefaultMaxBackoff = 10 * time.Second
+)
+
+// A RetryPolicy retries API requests failing for transient reasons: throttling (429), an unavailable
+// API server (503) and broken connections. Requests rejected by the server or never sent are retried
+// for every method, requests whose connection broke only for idempotent methods.
+type RetryPolicy struct {
+	// MaxRetries is how often a request is retried, 0 disables retries.
+	MaxRetries int
+	// Backoff is the delay before the first retry, doubled for each further one and jittered.
+	Backoff time.Duration
+	// MaxBackoff caps delays, including ones asked for by Retry-After.
+	MaxBackoff time.Duration
+	// Logf logs retries if set.
+	Logf func(format string, args ...any)
+}
+
+// WrapTransport returns rt retrying requests by p, for rest.Config.Wrap.
+// It replaces the retries client-go makes on its own when a response has Retry-After,
+// so 0 MaxRetries disables retries entirely.
+func (p RetryPolicy) WrapTransport(rt http.RoundTripper) http.RoundTripper {
+	return &retryTransport{policy: p, next: rt}
+}
+
+// A retryTransport is an http.RoundTripper retrying by policy.
+type retryTransport struct {
+	policy RetryPolicy
+	next   http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		resp, err := t.next.RoundTrip(req)
+		retryAfter, ok := retryable(req, resp, err)
+		if !ok || attempt >= t.policy.MaxRetries || req.Context().Err() != nil || !rewindable(req) {
+			if resp != nil {
+				resp.Header.Del("Retry-After")
+			}
+			return resp, err
+		}
+
+		var reason string
+		if err != nil {
+			reason = err.Error()
+		} else {
+			reason = resp.Status
+			io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+			resp.Body.Close()
+		}
+		delay := t.policy.delay(attempt, retryAfter)
+		if t.policy.Logf != nil {
+			t.policy.Logf("Retrying %s %s in %s (%d/%d): %s", req.Method, req.URL.Path, delay.Round(time.Millisecond), attempt+1, t.policy.MaxRetries, reason)
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+
+		if req.Body != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req = req.Clone(req.Context())
+			req.Body = body
+		}
+	}
+}
+
+// delay returns how long to wait before retry attempt+1: exponential backoff with jitter,
+// or retryAfter if the server asked for longer, capped by MaxBackoff.
+func (p RetryPolicy) delay(attempt int, retryAfter time.Duration) time.Duration {
+	backoff := p.Backoff << attempt
+	if backoff < 0 || (p.Backoff > 0 && backoff == 0) {
+		backoff = p.MaxBackoff // overflowed
+	}
+	backoff = backoff/2 + rand.N(backoff/2+1)
+	delay := max(backoff, retryAfter)
+	if p.MaxBackoff > 0 && delay > p.MaxBackoff {
+		delay = p.MaxBackoff
+	}
+	return delay
+}
+
+// rewindable reports whether the body of req can be sent again.
+func rewindable(req *http.Request) bool {
+	return req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+}
+
+// retryable reports whether req failing with resp or err is worth another attempt,
+// and how long the server asked to wait.
+func retryable(req *http.Request, resp *http.Response, err error) (time.Duration, bool) {
+	if err != nil {
+		if notSent(err) {
+			return 0, true
+		}
+		return 0, idempotent(req.Method) && brokenConnection(err)
+	}
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusServiceUnavailable:
+		return parseRetryAfter(resp.Header.Get("Retry-After")), true
+	}
+	return 0, false
+}
+
+// notSent reports whether err happened before the request reached the server.
+func notSent(err error) bool {
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
+
+// brokenConnection reports whether err is a connection lost while the request was in flight.
+func brokenConnection(err error) bool {
+	var netErr net.Error
+	return errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.EPIPE) ||
+		errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) ||
+		(errors.As(err, &netErr) && netErr.Timeout())
+}
+
+// idempotent reports whether sending a request with method twice has the effect of sending it once.
+func idempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// parseRetryAfter parses a Retry-After header in seconds or as an HTTP date, 0 if missing or invalid.
+func parseRetryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		return max(time.Until(at), 0)
+	}
+	return 0
+}
@@ -0,0 +1,113 @@
+package k8s
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRetryTransport(t *testing.T) {
+	tests := []struct {
+		name      string
+		method    string
+		failures  int
+		failure   func(w http.ResponseWriter)
+		wantCalls int32
+		wantCode  int
+	}{
+		{name: "unavailable", method: http.MethodGet, failures: 2, failure: status(http.StatusServiceUnavailable), wantCalls: 3, wantCode: http.StatusOK},
+		{name: "throttled post", method: http.MethodPost, failures: 1, failure: status(http.StatusTooManyRequests), wantCalls: 2, wantCode: http.StatusOK},
+		{name: "exhausted", method: http.MethodGet, failures: 5, failure: status(http.StatusServiceUnavailable), wantCalls: 4, wantCode: http.StatusServiceUnavailable},
+		{name: "server error", method: http.MethodGet, failures: 1, failure: status(http.StatusInternalServerError), wantCalls: 1, wantCode: http.StatusInternalServerError},
+		{name: "reset get", method: http.MethodGet, failures: 1, failure: closeConnection, wantCalls: 2, wantCode: http.StatusOK},
+		{name: "reset post", method: http.MethodPost, failures: 1, failure: closeConnection, wantCalls: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, _ := io.ReadAll(r.Body)
+				if r.Method == http.MethodPost && string(body) != "payload" {
+					t.Errorf("body = %q, want payload", body)
+				}
+				if calls.Add(1) <= int32(tt.failures) {
+					tt.failure(w)
+					return
+				}
+				w.WriteHeader(http.StatusOK)
+			}))
+			defer server.Close()
+
+			policy := RetryPolicy{MaxRetries: 3, Backoff: time.Millisecond, MaxBackoff: 5 * time.Millisecond}
+			client := &http.Client{Transport: policy.WrapTransport(http.DefaultTransport)}
+			req, err := http.NewRequestWithContext(t.Context(), tt.method, server.URL, strings.NewReader("payload"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp, err := client.Do(req)
+			if tt.wantCode == 0 {
+				if err == nil {
+					t.Errorf("expected an error, got %s", resp.Status)
+				}
+			} else if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			} else {
+				resp.Body.Close()
+				if resp.StatusCode != tt.wantCode {
+					t.Errorf("status = %d, want %d", resp.StatusCode, tt.wantCode)
+				}
+			}
+			if got := calls.Load(); got != tt.wantCalls {
+				t.Errorf("calls = %d, want %d", got, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	policy := RetryPolicy{Backoff: 100 * time.Millisecond, MaxBackoff: time.Second}
+	for attempt, want := range []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 400 * time.Millisecond, 800 * time.Millisecond, time.Second} {
+		if got := policy.delay(attempt, 0); got < want/2 || got > want {
+			t.Errorf("delay(%d) = %s, want between %s and %s", attempt, got, want/2, want)
+		}
+	}
+	if got := policy.delay(0, 700*time.Millisecond); got != 700*time.Millisecond {
+		t.Errorf("delay with Retry-After = %s, want 700ms", got)
+	}
+	if got := policy.delay(0, time.Minute); got != time.Second {
+		t.Errorf("delay with long Retry-After = %s, want capped to 1s", got)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	if got := parseRetryAfter("3"); got != 3*time.Second {
+		t.Errorf("seconds: got %s", got)
+	}
+	if got := parseRetryAfter(time.Now().Add(time.Hour).UTC().Format(http.TimeFormat)); got < 59*time.Minute {
+		t.Errorf("date: got %s", got)
+	}
+	for _, value := range []string{"", "soon", "-1"} {
+		if got := parseRetryAfter(value); got != 0 {
+			t.Errorf("%q: got %s, want 0", value, got)
+		}
+	}
+}
+
+// status returns a failure answering with code.
+func status(code int) func(http.ResponseWriter) {
+	return func(w http.ResponseWriter) {
+		w.WriteHeader(code)
+	}
+}
+
+// closeConnection fails by dropping the connection without an answer.
+func closeConnection(w http.ResponseWriter) {
+	conn, _, err := w.(http.Hijacker).Hijack()
+	if err == nil {
+		conn.Close()
+	}
+}
@@ -0,0 +1,66 @@
+// Package logging builds the logger of oiler-cli from --log-format and --verbose.
+package logging
+
+import (
+	"fmt"
+	"os"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"golang.org/x/term"
+)
+
+// A Format is a log output format.
+type Format string
+
+// Supported formats.
+const (
+	FormatText Format = "text"
+	FormatJSON Format = "json"
+)
+
+// ParseFormat validates format name.
+func ParseFormat(s string) (Format, error) {
+	switch Format(s) {
+	case FormatText, FormatJSON:
+		return Format(s), nil
+	default:
+		return "", fmt.Errorf("unknown log format %q, use %s or %s", s, FormatText, FormatJSON)
+	}
+}
+
+// New returns a logger writing to stderr.
+// Text logs are plain messages with a level, colored on a terminal. JSON logs are zap production logs.
+// verbose enables debug messages, and time and caller in text logs.
+func New(format Format, verbose bool) (*zap.SugaredLogger, error) {
+	level := zap.NewAtomicLevelAt(zap.InfoLevel)
+	if verbose {
+		level.SetLevel(zap.DebugLevel)
+	}
+
+	config := zap.NewProductionConfig()
+	config.Level = level
+	config.OutputPaths = []string{"stderr"}
+	config.ErrorOutputPaths = []string{"stderr"}
+
+	if format == FormatText {
+		config.Encoding = "console"
+		config.DisableStacktrace = true
+		config.Sampling = nil
+		config.EncoderConfig = zap.NewDevelopmentEncoderConfig()
+		config.EncoderConfig.EncodeLevel = zapcore.CapitalLevelEncoder
+		if term.IsTerminal(int(os.Stderr.Fd())) {
+			config.EncoderConfig.EncodeLevel = zapcore.CapitalColorLevelEncoder
+		}
+		if !verbose {
+			config.EncoderConfig.TimeKey = ""
+			config.EncoderConfig.CallerKey = ""
+		}
+	}
+
+	logger, err := config.Build()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build logger: %w", err)
+	}
+	return logger.Sugar(), nil
+}
@@ -0,0 +1,213 @@
+package operator
+
+import (
+	"bytes"
+	"context"
+	"embed"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"slices"
+	"strings"
+	"text/template"
+
+	"github.com/oiler-backup/cli/internal/adapters"
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+)
+
+// Labels put on every object of an installation.
+const (
+	PartOfLabel    = "app.kubernetes.io/part-of"
+	ManagedByLabel = "app.kubernetes.io/managed-by"
+	VersionLabel   = "app.kubernetes.io/version"
+)
+
+// PartOf is the value of PartOfLabel.
+const PartOf = "oiler-backup"
+
+// FieldManager owns the fields oiler-cli applies.
+const FieldManager = "oiler-cli"
+
+// ManagerContainer is the operator container, the one a bare image override applies to.
+const ManagerContainer = "manager"
+
+// DefaultVersion is the operator version installed unless another is requested.
+const DefaultVersion = "0.0.1-11"
+
+// manifests holds a directory of templated manifests per operator version, applied in file name order.
+//
+//go:embed manifests
+var manifests embed.FS
+
+// clusterScoped are kinds of the manifests that are not namespaced.
+var clusterScoped = []string{"Namespace", "CustomResourceDefinition", "ClusterRole", "ClusterRoleBinding"}
+
+// Values customize rendered manifests.
+type Values struct {
+	// Namespace the operator is installed into.
+	Namespace string
+	// Images override container images by container name.
+	Images map[string]string
+}
+
+// Versions returns the operator versions with embedded manifests.
+func Versions() []string {
+	entries, err := manifests.ReadDir("manifests")
+	if err != nil {
+		return nil
+	}
+	versions := make([]string, 0, len(entries))
+	for _, e := range entries {
+		if e.IsDir() {
+			versions = append(versions, e.Name())
+		}
+	}
+	return versions
+}
+
+// Render returns the objects of version for values, in the order they should be applied.
+// Every object is labeled with PartOfLabel, ManagedByLabel and VersionLabel.
+func Render(version string, values Values) ([]*unstructured.Unstructured, error) {
+	if !slices.Contains(Versions(), version) {
+		return nil, fmt.Errorf("no manifests for operator version %s, available: %s", version, strings.Join(Versions(), ", "))
+	}
+	dir := path.Join("manifests", version)
+	files, err := fs.Glob(manifests, dir+"/*.yaml")
+	if err != nil {
+		return nil, err
+	}
+
+	var objects []*unstructured.Unstructured
+	used := map[string]bool{}
+	for _, file := range files {
+		tmpl, err := template.New(path.Base(file)).Option("missingkey=error").ParseFS(manifests, file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", file, err)
+		}
+		var buf bytes.Buffer
+		if err := tmpl.Execute(&buf, values); err != nil {
+			return nil, fmt.Errorf("failed to render %s: %w", file, err)
+		}
+
+		decoder := utilyaml.NewYAMLOrJSONDecoder(&buf, 4096)
+		for {
+			var object map[string]any
+			if err := decoder.Decode(&object); errors.Is(err, io.EOF) {
+				break
+			} else if err != nil {
+				return nil, fmt.Errorf("failed to decode %s: %w", file, err)
+			}
+			if object == nil {
+				continue
+			}
+			u := &unstructured.Unstructured{Object: object}
+			labels := u.GetLabels()
+			if labels == nil {
+				labels = map[string]string{}
+			}
+			labels[PartOfLabel] = PartOf
+			labels[ManagedByLabel] = FieldManager
+			labels[VersionLabel] = version
+			u.SetLabels(labels)
+			if err := overrideImages(u, values.Images, used); err != nil {
+				return nil, err
+			}
+			objects = append(objects, u)
+		}
+	}
+
+	for container := range values.Images {
+		if !used[container] {
+			return nil, fmt.Errorf("no container %s in the manifests of operator version %s", container, version)
+		}
+	}
+	return objects, nil
+}
+
+// overrideImages sets images of containers of a Deployment, recording overridden containers in used.
+func overrideImages(u *unstructured.Unstructured, images map[string]string, used map[string]bool) error {
+	if u.GetKind() != "Deployment" || len(images) == 0 {
+		return nil
+	}
+	containers, _, err := unstructured.NestedSlice(u.Object, "spec", "template", "spec", "containers")
+	if err != nil {
+		return err
+	}
+	for _, c := range containers {
+		container, ok := c.(map[string]any)
+		if !ok {
+			continue
+		}
+		name, _ := container["name"].(string)
+		if image, ok := images[name]; ok {
+			container["image"] = image
+			used[name] = true
+		}
+	}
+	return unstructured.SetNestedSlice(u.Object, containers, "spec", "template", "spec", "containers")
+}
+
+// Namespaced reports whether u is a namespaced object.
+func Namespaced(u *unstructured.Unstructured) bool {
+	return !slices.Contains(clusterScoped, u.GetKind())
+}
+
+// GroupVersionResource returns the resource of u. Manifests only hold well-known kinds,
+// so their resources are derived from kinds without discovery.
+func GroupVersionResource(u *unstructured.Unstructured) schema.GroupVersionResource {
+	gvr, _ := meta.UnsafeGuessKindToResource(u.GroupVersionKind())
+	return gvr
+}
+
+// ResourceFor returns the client for the resource of u.
+func ResourceFor(dynClient dynamic.Interface, u *unstructured.Unstructured) dynamic.ResourceInterface {
+	gvr := GroupVersionResource(u)
+	if Namespaced(u) {
+		return dynClient.Resource(gvr).Namespace(u.GetNamespace())
+	}
+	return dynClient.Resource(gvr)
+}
+
+// Find returns the operator Deployment in namespace, however it was installed, nil if there is none.
+func Find(ctx context.Context, clientset kubernetes.Interface, namespace string) (*appsv1.Deployment, error) {
+	deployments, err := clientset.AppsV1().Deployments(namespace).List(ctx, metav1.ListOptions{LabelSelector: adapters.OperatorSelector})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list operator Deployments in %s: %w", namespace, err)
+	}
+	if len(deployments.Items) == 0 {
+		return nil, nil
+	}
+	return &deployments.Items[0], nil
+}
+
+// InstalledVersion returns the version of the operator Deployment d: its version label, or the
+// tag of its manager image if it was not installed by oiler-cli, "unknown" if neither is set.
+func InstalledVersion(d *appsv1.Deployment) string {
+	if v := d.Labels[VersionLabel]; v != "" {
+		return v
+	}
+	image := ManagerImage(d)
+	if i := strings.LastIndex(image, ":"); i > strings.LastIndex(image, "/") {
+		return strings.TrimPrefix(image[i+1:], "v")
+	}
+	return "unknown"
+}
+
+// ManagerImage returns the image of the manager container of the operator deployment d.
+func ManagerImage(d *appsv1.Deployment) string {
+	for _, c := range d.Spec.Template.Spec.Containers {
+		if c.Name == ManagerContainer {
+			return c.Image
+		}
+	}
+	return ""
+}
@@ -0,0 +1,86 @@
+package operator
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/oiler-backup/cli/internal/adapters"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestRender(t *testing.T) {
+	objects, err := Render(DefaultVersion, Values{Namespace: "backups", Images: map[string]string{ManagerContainer: "registry.local/oiler:dev"}})
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+
+	var kinds []string
+	for _, o := range objects {
+		kinds = append(kinds, o.GetKind())
+		if o.GetLabels()[VersionLabel] != DefaultVersion || o.GetLabels()[PartOfLabel] != PartOf {
+			t.Errorf("%s %s has labels %v", o.GetKind(), o.GetName(), o.GetLabels())
+		}
+		if Namespaced(o) && o.GetNamespace() != "backups" {
+			t.Errorf("%s %s is in namespace %q, want backups", o.GetKind(), o.GetName(), o.GetNamespace())
+		}
+	}
+	want := "Namespace CustomResourceDefinition CustomResourceDefinition ServiceAccount ClusterRole ClusterRoleBinding Role RoleBinding ConfigMap Deployment Service"
+	if got := strings.Join(kinds, " "); got != want {
+		t.Errorf("kinds = %s, want %s", got, want)
+	}
+
+	deployment := objects[len(objects)-2]
+	containers, _, _ := unstructured.NestedSlice(deployment.Object, "spec", "template", "spec", "containers")
+	manager := containers[0].(map[string]any)
+	if manager["image"] != "registry.local/oiler:dev" {
+		t.Errorf("manager image = %v, want the override", manager["image"])
+	}
+	env := map[string]any{}
+	for _, e := range manager["env"].([]any) {
+		env[e.(map[string]any)["name"].(string)] = e.(map[string]any)["value"]
+	}
+	if env["CORE_ADDR"] != "oiler-backup-controller-manager.backups.svc.cluster.local:50051" {
+		t.Errorf("CORE_ADDR = %v, want the service in namespace backups", env["CORE_ADDR"])
+	}
+	if env[adapters.OperatorNamespaceEnv] != "backups" {
+		t.Errorf("%s = %v, want backups, the namespace of the adapter ConfigMap", adapters.OperatorNamespaceEnv, env[adapters.OperatorNamespaceEnv])
+	}
+	if annotations := deployment.GetAnnotations(); len(annotations) > 0 {
+		t.Errorf("Deployment has annotations %v, the operator reads none", annotations)
+	}
+}
+
+func TestRenderErrors(t *testing.T) {
+	if _, err := Render("9.9.9", Values{Namespace: "backups"}); err == nil {
+		t.Error("Render() of an unknown version succeeded")
+	}
+	if _, err := Render(DefaultVersion, Values{Namespace: "backups", Images: map[string]string{"sidecar": "busybox"}}); err == nil {
+		t.Error("Render() with an unknown container succeeded")
+	}
+}
+
+func TestInstalledVersion(t *testing.T) {
+	tests := []struct {
+		labels map[string]string
+		image  string
+		want   string
+	}{
+		{labels: map[string]string{VersionLabel: "0.0.1-11"}, image: "oiler:latest", want: "0.0.1-11"},
+		{image: "registry.local:5000/oiler-backup:v0.2.0", want: "0.2.0"},
+		{image: "registry.local:5000/oiler-backup", want: "unknown"},
+	}
+	for _, tt := range tests {
+		d := &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Labels: tt.labels},
+			Spec: appsv1.DeploymentSpec{Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+				Containers: []corev1.Container{{Name: ManagerContainer, Image: tt.image}},
+			}}},
+		}
+		if got := InstalledVersion(d); got != tt.want {
+			t.Errorf("InstalledVersion(%v, %s) = %s, want %s", tt.labels, tt.image, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,190 @@
+package progress
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jedib0t/go-pretty/v6/text"
+	"golang.org/x/term"
+)
+
+// frameInterval is how often animated tasks are redrawn.
+const frameInterval = 500 * time.Millisecond
+
+// barWidth is the number of cells of a progress bar.
+const barWidth = 20
+
+// frames animate tasks without a total.
+var frames = []string{".", "..", "..."}
+
+// Options configure a Reporter.
+type Options struct {
+	// Quiet hides all progress.
+	Quiet bool
+	// NoColor disables colors of progress bars.
+	NoColor bool
+}
+
+// A Reporter shows progress of a command on out, which should be stderr so it never mixes with
+// results on stdout. On a terminal tasks are animated on a single line that is erased when they are
+// done. Otherwise nothing is animated: a task prints its text once, a bar also its final count.
+// It is safe for concurrent use.
+type Reporter struct {
+	out         io.Writer
+	interactive bool
+	opts        Options
+
+	mu    sync.Mutex
+	tasks map[*Task]struct{}
+}
+
+// New returns a Reporter writing to out, animated if out is a terminal.
+func New(out io.Writer, opts Options) *Reporter {
+	interactive := false
+	if f, ok := out.(*os.File); ok {
+		interactive = term.IsTerminal(int(f.Fd()))
+	}
+	return &Reporter{out: out, interactive: interactive, opts: opts, tasks: map[*Task]struct{}{}}
+}
+
+// A Task is a step shown by a Reporter until Done is called.
+type Task struct {
+	r     *Reporter
+	text  string
+	total int
+
+	mu      sync.Mutex
+	current int
+	frame   int
+	stop    chan struct{}
+	stopped sync.WaitGroup
+	once    sync.Once
+}
+
+// Start shows text until the returned Task is done.
+func (r *Reporter) Start(text string) *Task {
+	return r.start(text, 0)
+}
+
+// StartBar shows text with a bar filling up as the returned Task advances to total.
+func (r *Reporter) StartBar(text string, total int) *Task {
+	return r.start(text, max(total, 1))
+}
+
+// start starts a Task with total steps, 0 for an indeterminate one.
+func (r *Reporter) start(text string, total int) *Task {
+	t := &Task{r: r, text: text, total: total, stop: make(chan struct{})}
+	if r.opts.Quiet {
+		return t
+	}
+	if !r.interactive {
+		fmt.Fprintln(r.out, text)
+		return t
+	}
+
+	r.mu.Lock()
+	r.tasks[t] = struct{}{}
+	r.mu.Unlock()
+	t.draw()
+	t.stopped.Add(1)
+	go t.animate()
+	return t
+}
+
+// Add advances the Task by n steps.
+func (t *Task) Add(n int) {
+	t.mu.Lock()
+	t.current = min(t.current+n, t.total)
+	t.mu.Unlock()
+	if t.r.interactive && !t.r.opts.Quiet {
+		t.draw()
+	}
+}
+
+// Increment advances the Task by one step.
+func (t *Task) Increment() {
+	t.Add(1)
+}
+
+// Done stops showing the Task. Calling it again does nothing.
+func (t *Task) Done() {
+	t.once.Do(func() {
+		close(t.stop)
+		t.stopped.Wait()
+		if t.r.opts.Quiet {
+			return
+		}
+		if !t.r.interactive {
+			if t.total > 0 {
+				t.mu.Lock()
+				fmt.Fprintf(t.r.out, "%s: %d/%d\n", t.text, t.current, t.total)
+				t.mu.Unlock()
+			}
+			return
+		}
+
+		t.r.mu.Lock()
+		delete(t.r.tasks, t)
+		t.r.mu.Unlock()
+		t.mu.Lock()
+		fmt.Fprint(t.r.out, "\r\033[K")
+		t.mu.Unlock()
+	})
+}
+
+// StopAll stops all running tasks, e.g. on interruption, so the terminal is left clean.
+func (r *Reporter) StopAll() {
+	r.mu.Lock()
+	tasks := make([]*Task, 0, len(r.tasks))
+	for t := range r.tasks {
+		tasks = append(tasks, t)
+	}
+	r.mu.Unlock()
+	for _, t := range tasks {
+		t.Done()
+	}
+}
+
+// animate redraws the Task until it is done.
+func (t *Task) animate() {
+	defer t.stopped.Done()
+	ticker := time.NewTicker(frameInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-t.stop:
+			return
+		case <-ticker.C:
+			t.mu.Lock()
+			t.frame++
+			t.mu.Unlock()
+			t.draw()
+		}
+	}
+}
+
+// draw renders the Task over the current line.
+func (t *Task) draw() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	select {
+	case <-t.stop:
+		return
+	default:
+	}
+
+	if t.total == 0 {
+		fmt.Fprintf(t.r.out, "\r\033[K%s%s", t.text, frames[t.frame%len(frames)])
+		return
+	}
+	filled := barWidth * t.current / t.total
+	bar := strings.Repeat("█", filled)
+	if !t.r.opts.NoColor {
+		bar = text.FgGreen.Sprint(bar)
+	}
+	fmt.Fprintf(t.r.out, "\r\033[K%s [%s%s] %d/%d", t.text, bar, strings.Repeat("░", barWidth-filled), t.current, t.total)
+}
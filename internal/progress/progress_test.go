@@ -0,0 +1,56 @@
+package progress
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestReporterNonInteractive(t *testing.T) {
+	var out bytes.Buffer
+	r := New(&out, Options{})
+
+	r.Start("[1/2] Preparing").Done()
+	bar := r.StartBar("[2/2] Deleting BackupRequests", 3)
+	bar.Increment()
+	bar.Add(5)
+	bar.Done()
+	bar.Done()
+
+	want := "[1/2] Preparing\n[2/2] Deleting BackupRequests\n[2/2] Deleting BackupRequests: 3/3\n"
+	if got := out.String(); got != want {
+		t.Errorf("output = %q, want %q", got, want)
+	}
+}
+
+func TestReporterQuiet(t *testing.T) {
+	var out bytes.Buffer
+	r := New(&out, Options{Quiet: true})
+
+	r.Start("[1/2] Preparing").Done()
+	bar := r.StartBar("[2/2] Deleting BackupRequests", 2)
+	bar.Increment()
+	bar.Done()
+	r.StopAll()
+
+	if out.Len() != 0 {
+		t.Errorf("quiet reporter wrote %q", out.String())
+	}
+}
+
+func TestTaskDraw(t *testing.T) {
+	var out bytes.Buffer
+	r := &Reporter{out: &out, interactive: true, opts: Options{NoColor: true}, tasks: map[*Task]struct{}{}}
+
+	bar := r.StartBar("Deleting", 4)
+	bar.Add(2)
+	if want := "\r\033[KDeleting [" + "██████████" + "░░░░░░░░░░" + "] 2/4"; !bytes.HasSuffix(out.Bytes(), []byte(want)) {
+		t.Errorf("output = %q, want suffix %q", out.String(), want)
+	}
+	r.StopAll()
+	if !bytes.HasSuffix(out.Bytes(), []byte("\r\033[K")) {
+		t.Errorf("StopAll did not erase the line: %q", out.String())
+	}
+	if len(r.tasks) != 0 {
+		t.Errorf("%d tasks still running", len(r.tasks))
+	}
+}
@@ -0,0 +1,88 @@
+package rbac
+
+import (
+	"context"
+	"fmt"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// A Permission is an API access, like the arguments of kubectl auth can-i.
+type Permission struct {
+	Verb        string
+	Group       string
+	Resource    string
+	Subresource string
+	// Namespaced permissions are checked in the namespace passed to Check.
+	Namespaced bool
+}
+
+// String formats p like kubectl auth can-i arguments, e.g. "get pods/log".
+func (p Permission) String() string {
+	return p.Verb + " " + p.ResourceName()
+}
+
+// ResourceName returns the resource of p with group and subresource, e.g. "cronjobs.batch" or "pods/log".
+func (p Permission) ResourceName() string {
+	name := p.Resource
+	if p.Group != "" {
+		name += "." + p.Group
+	}
+	if p.Subresource != "" {
+		name += "/" + p.Subresource
+	}
+	return name
+}
+
+// A Result tells whether the current user has a Permission.
+type Result struct {
+	Permission
+	Namespace string
+	Allowed   bool
+	// Reason explains the decision if the authorizer gave one.
+	Reason string
+}
+
+// Check reviews perms for the current user with SelfSubjectAccessReviews,
+// checking namespaced ones in namespace.
+func Check(ctx context.Context, clientset kubernetes.Interface, namespace string, perms []Permission) ([]Result, error) {
+	results := make([]Result, 0, len(perms))
+	for _, p := range perms {
+		attrs := &authorizationv1.ResourceAttributes{
+			Verb:        p.Verb,
+			Group:       p.Group,
+			Resource:    p.Resource,
+			Subresource: p.Subresource,
+		}
+		if p.Namespaced {
+			attrs.Namespace = namespace
+		}
+
+		review, err := clientset.AuthorizationV1().SelfSubjectAccessReviews().Create(ctx, &authorizationv1.SelfSubjectAccessReview{
+			Spec: authorizationv1.SelfSubjectAccessReviewSpec{ResourceAttributes: attrs},
+		}, metav1.CreateOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to review access to %s: %w", p, err)
+		}
+
+		reason := review.Status.Reason
+		if review.Status.EvaluationError != "" {
+			reason = review.Status.EvaluationError
+		}
+		results = append(results, Result{Permission: p, Namespace: attrs.Namespace, Allowed: review.Status.Allowed, Reason: reason})
+	}
+	return results, nil
+}
+
+// Denied returns results that are not allowed.
+func Denied(results []Result) []Result {
+	var denied []Result
+	for _, r := range results {
+		if !r.Allowed {
+			denied = append(denied, r)
+		}
+	}
+	return denied
+}
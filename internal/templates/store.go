@@ -0,0 +1,271 @@
+package templates
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// A Source tells where a template is stored.
+type Source string
+
+// Supported sources.
+const (
+	SourceLocal   Source = "local"
+	SourceCluster Source = "cluster"
+)
+
+// ConfigMapName is the ConfigMap in the operator namespace holding cluster templates.
+const ConfigMapName = "oiler-backup-templates"
+
+// fileExt is the extension of template files and ConfigMap keys.
+const fileExt = ".yaml"
+
+// ParseSource validates source name.
+func ParseSource(s string) (Source, error) {
+	switch Source(s) {
+	case SourceLocal, SourceCluster:
+		return Source(s), nil
+	default:
+		return "", fmt.Errorf("unknown template source %q, use %s or %s", s, SourceLocal, SourceCluster)
+	}
+}
+
+// A Store keeps templates.
+type Store interface {
+	Source() Source
+	List(ctx context.Context) ([]Template, error)
+	Get(ctx context.Context, name string) (Template, error)
+	Save(ctx context.Context, t Template) error
+	Delete(ctx context.Context, name string) error
+}
+
+// A LocalStore keeps templates as YAML files in a directory.
+// Names are validated with ValidateName before they are used in paths.
+type LocalStore struct {
+	Dir string
+}
+
+// Source implements Store.
+func (s LocalStore) Source() Source {
+	return SourceLocal
+}
+
+// List implements Store.
+func (s LocalStore) List(ctx context.Context) ([]Template, error) {
+	entries, err := os.ReadDir(s.Dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", s.Dir, err)
+	}
+
+	var list []Template
+	for _, entry := range entries {
+		name, ok := strings.CutSuffix(entry.Name(), fileExt)
+		if entry.IsDir() || !ok || ValidateName(name) != nil {
+			continue
+		}
+		t, err := s.Get(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, t)
+	}
+	return list, nil
+}
+
+// Get implements Store.
+func (s LocalStore) Get(ctx context.Context, name string) (Template, error) {
+	if err := ValidateName(name); err != nil {
+		return Template{}, err
+	}
+	path := filepath.Join(s.Dir, name+fileExt)
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return Template{}, fmt.Errorf("%w: %s in %s", ErrNotFound, name, s.Dir)
+	}
+	if err != nil {
+		return Template{}, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return parseNamed(data, name, path)
+}
+
+// Save implements Store.
+func (s LocalStore) Save(ctx context.Context, t Template) error {
+	if err := ValidateName(t.Name); err != nil {
+		return err
+	}
+	data, err := Encode(t)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(s.Dir, 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", s.Dir, err)
+	}
+	return os.WriteFile(filepath.Join(s.Dir, t.Name+fileExt), data, 0644)
+}
+
+// Delete implements Store.
+func (s LocalStore) Delete(ctx context.Context, name string) error {
+	if err := ValidateName(name); err != nil {
+		return err
+	}
+	err := os.Remove(filepath.Join(s.Dir, name+fileExt))
+	if errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("%w: %s in %s", ErrNotFound, name, s.Dir)
+	}
+	return err
+}
+
+// A ClusterStore keeps templates in a ConfigMap, one key per template.
+type ClusterStore struct {
+	Client    kubernetes.Interface
+	Namespace string
+	Name      string
+}
+
+// Source implements Store.
+func (s ClusterStore) Source() Source {
+	return SourceCluster
+}
+
+// List implements Store.
+func (s ClusterStore) List(ctx context.Context) ([]Template, error) {
+	cm, err := s.configMap(ctx)
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]string, 0, len(cm.Data))
+	for key := range cm.Data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var list []Template
+	for _, key := range keys {
+		name, ok := strings.CutSuffix(key, fileExt)
+		if !ok {
+			continue
+		}
+		t, err := parseNamed([]byte(cm.Data[key]), name, s.location()+"["+key+"]")
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, t)
+	}
+	return list, nil
+}
+
+// Get implements Store.
+func (s ClusterStore) Get(ctx context.Context, name string) (Template, error) {
+	cm, err := s.configMap(ctx)
+	if apierrors.IsNotFound(err) {
+		return Template{}, fmt.Errorf("%w: %s, ConfigMap %s does not exist", ErrNotFound, name, s.location())
+	}
+	if err != nil {
+		return Template{}, err
+	}
+	data, ok := cm.Data[name+fileExt]
+	if !ok {
+		return Template{}, fmt.Errorf("%w: %s in ConfigMap %s", ErrNotFound, name, s.location())
+	}
+	return parseNamed([]byte(data), name, s.location()+"["+name+fileExt+"]")
+}
+
+// Save implements Store. The ConfigMap is created on first use.
+func (s ClusterStore) Save(ctx context.Context, t Template) error {
+	data, err := Encode(t)
+	if err != nil {
+		return err
+	}
+
+	cm, err := s.configMap(ctx)
+	if apierrors.IsNotFound(err) {
+		cm = &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: s.Name, Namespace: s.Namespace},
+			Data:       map[string]string{t.Name + fileExt: string(data)},
+		}
+		_, err = s.Client.CoreV1().ConfigMaps(s.Namespace).Create(ctx, cm, metav1.CreateOptions{})
+		return err
+	}
+	if err != nil {
+		return err
+	}
+
+	if cm.Data == nil {
+		cm.Data = map[string]string{}
+	}
+	cm.Data[t.Name+fileExt] = string(data)
+	_, err = s.Client.CoreV1().ConfigMaps(s.Namespace).Update(ctx, cm, metav1.UpdateOptions{})
+	return err
+}
+
+// Delete implements Store.
+func (s ClusterStore) Delete(ctx context.Context, name string) error {
+	cm, err := s.configMap(ctx)
+	if apierrors.IsNotFound(err) {
+		return fmt.Errorf("%w: %s, ConfigMap %s does not exist", ErrNotFound, name, s.location())
+	}
+	if err != nil {
+		return err
+	}
+	if _, ok := cm.Data[name+fileExt]; !ok {
+		return fmt.Errorf("%w: %s in ConfigMap %s", ErrNotFound, name, s.location())
+	}
+
+	delete(cm.Data, name+fileExt)
+	_, err = s.Client.CoreV1().ConfigMaps(s.Namespace).Update(ctx, cm, metav1.UpdateOptions{})
+	return err
+}
+
+// configMap gets the templates ConfigMap.
+func (s ClusterStore) configMap(ctx context.Context) (*corev1.ConfigMap, error) {
+	return s.Client.CoreV1().ConfigMaps(s.Namespace).Get(ctx, s.Name, metav1.GetOptions{})
+}
+
+// location describes the ConfigMap for messages.
+func (s ClusterStore) location() string {
+	return s.Namespace + "/" + s.Name
+}
+
+// parseNamed parses a stored template, taking its name from where it is stored.
+func parseNamed(data []byte, name, location string) (Template, error) {
+	t, err := Parse(data)
+	if err != nil {
+		return Template{}, fmt.Errorf("%s: %w", location, err)
+	}
+	t.Name = name
+	return t, nil
+}
+
+// Find returns the first template named name in stores.
+func Find(ctx context.Context, name string, stores ...Store) (Template, Source, error) {
+	var notFound []string
+	for _, s := range stores {
+		t, err := s.Get(ctx, name)
+		if errors.Is(err, ErrNotFound) {
+			notFound = append(notFound, strings.TrimPrefix(err.Error(), ErrNotFound.Error()+": "))
+			continue
+		}
+		if err != nil {
+			return Template{}, "", err
+		}
+		return t, s.Source(), nil
+	}
+	return Template{}, "", fmt.Errorf("%w: %s", ErrNotFound, strings.Join(notFound, "; "))
+}
@@ -0,0 +1,48 @@
+package templates
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLocalStoreRejectsInvalidNames(t *testing.T) {
+	root := t.TempDir()
+	outside := filepath.Join(root, "x"+fileExt)
+	if err := os.WriteFile(outside, []byte("name: x\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	store := LocalStore{Dir: filepath.Join(root, "templates")}
+
+	for _, name := range []string{"../x", "a/b", "", "Nightly"} {
+		if err := store.Delete(t.Context(), name); err == nil {
+			t.Errorf("Delete(%q) succeeded", name)
+		}
+		if _, err := store.Get(t.Context(), name); err == nil {
+			t.Errorf("Get(%q) succeeded", name)
+		}
+		if err := store.Save(t.Context(), Template{Name: name}); err == nil {
+			t.Errorf("Save(%q) succeeded", name)
+		}
+	}
+	if _, err := os.Stat(outside); err != nil {
+		t.Errorf("file outside of the store was removed: %v", err)
+	}
+}
+
+func TestLocalStore(t *testing.T) {
+	store := LocalStore{Dir: t.TempDir()}
+	if err := store.Save(t.Context(), Template{Name: "nightly", Schedule: "0 2 * * *"}); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	got, err := store.Get(t.Context(), "nightly")
+	if err != nil || got.Schedule != "0 2 * * *" {
+		t.Fatalf("Get() = %+v, %v", got, err)
+	}
+	if err := store.Delete(t.Context(), "nightly"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if list, err := store.List(t.Context()); err != nil || len(list) != 0 {
+		t.Errorf("List() after Delete() = %v, %v", list, err)
+	}
+}
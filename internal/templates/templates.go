@@ -0,0 +1,125 @@
+// Package templates stores named BackupRequest templates that backup create
+// expands, so standard S3 targets, schedules and retention are defined once.
+package templates
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+	"sigs.k8s.io/yaml"
+)
+
+// ErrNotFound is returned when a template does not exist in a store.
+var ErrNotFound = errors.New("template not found")
+
+// NameVariable is always set to the name of the BackupRequest being created.
+const NameVariable = "name"
+
+// A Template holds defaults for backup create flags.
+// String fields may reference variables as ${var}.
+type Template struct {
+	Name           string            `json:"name"`
+	Description    string            `json:"description,omitempty"`
+	DB             string            `json:"db,omitempty"`
+	S3             string            `json:"s3,omitempty"`
+	Schedule       string            `json:"schedule,omitempty"`
+	MaxBackupCount int64             `json:"maxBackupCount,omitempty"`
+	Labels         map[string]string `json:"labels,omitempty"`
+	Annotations    map[string]string `json:"annotations,omitempty"`
+}
+
+// Parse decodes a YAML or JSON template.
+func Parse(data []byte) (Template, error) {
+	var t Template
+	if err := yaml.UnmarshalStrict(data, &t); err != nil {
+		return Template{}, fmt.Errorf("failed to parse template: %w", err)
+	}
+	return t, nil
+}
+
+// Encode serializes t as YAML.
+func Encode(t Template) ([]byte, error) {
+	return yaml.Marshal(t)
+}
+
+// Validate checks the template name, which is also used as a file name and ConfigMap key.
+func Validate(t Template) error {
+	if err := ValidateName(t.Name); err != nil {
+		return err
+	}
+	if t.MaxBackupCount < 0 {
+		return fmt.Errorf("maxBackupCount must not be negative")
+	}
+	return nil
+}
+
+// ValidateName checks that name is a DNS-1123 label, so it cannot point outside of a store.
+func ValidateName(name string) error {
+	if problems := validation.IsDNS1123Label(name); len(problems) > 0 {
+		return fmt.Errorf("invalid template name %q: %s", name, strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// Variables returns names of variables referenced by t, sorted alphabetically.
+func (t Template) Variables() []string {
+	seen := map[string]bool{}
+	t.rewrite(func(s string) string {
+		os.Expand(s, func(name string) string {
+			seen[name] = true
+			return ""
+		})
+		return s
+	})
+
+	names := make([]string, 0, len(seen))
+	for name := range seen {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Expand returns t with variables replaced by vars.
+// All referenced variables must be given.
+func (t Template) Expand(vars map[string]string) (Template, error) {
+	var missing []string
+	for _, name := range t.Variables() {
+		if _, ok := vars[name]; !ok {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		return Template{}, fmt.Errorf("template %s needs variables: %s", t.Name, strings.Join(missing, ", "))
+	}
+
+	return t.rewrite(func(s string) string {
+		return os.Expand(s, func(name string) string { return vars[name] })
+	}), nil
+}
+
+// rewrite returns a copy of t with fn applied to every field that may contain variables.
+func (t Template) rewrite(fn func(string) string) Template {
+	t.DB = fn(t.DB)
+	t.S3 = fn(t.S3)
+	t.Schedule = fn(t.Schedule)
+	t.Labels = rewriteValues(t.Labels, fn)
+	t.Annotations = rewriteValues(t.Annotations, fn)
+	return t
+}
+
+// rewriteValues returns a copy of m with fn applied to values, nil for nil.
+func rewriteValues(m map[string]string, fn func(string) string) map[string]string {
+	if m == nil {
+		return nil
+	}
+	c := make(map[string]string, len(m))
+	for k, v := range m {
+		c[k] = fn(v)
+	}
+	return c
+}
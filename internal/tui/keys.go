@@ -0,0 +1,98 @@
+package tui
+
+import "unicode/utf8"
+
+// A Key is a decoded key press. Printable keys are stored in Rune.
+type Key struct {
+	Code KeyCode
+	Rune rune
+}
+
+// A KeyCode identifies a special key.
+type KeyCode int
+
+// Special keys.
+const (
+	KeyRune KeyCode = iota
+	KeyUp
+	KeyDown
+	KeyLeft
+	KeyRight
+	KeyPageUp
+	KeyPageDown
+	KeyHome
+	KeyEnd
+	KeyEnter
+	KeyTab
+	KeyBackspace
+	KeyEsc
+	KeyCtrlC
+)
+
+// escapeSequences maps CSI and SS3 sequences to keys.
+var escapeSequences = map[string]KeyCode{
+	"\x1b[A":  KeyUp,
+	"\x1b[B":  KeyDown,
+	"\x1b[C":  KeyRight,
+	"\x1b[D":  KeyLeft,
+	"\x1bOA":  KeyUp,
+	"\x1bOB":  KeyDown,
+	"\x1bOC":  KeyRight,
+	"\x1bOD":  KeyLeft,
+	"\x1b[5~": KeyPageUp,
+	"\x1b[6~": KeyPageDown,
+	"\x1b[H":  KeyHome,
+	"\x1b[F":  KeyEnd,
+	"\x1b[1~": KeyHome,
+	"\x1b[4~": KeyEnd,
+}
+
+// Decode splits raw terminal input into keys.
+// Unknown escape sequences are dropped.
+func Decode(b []byte) []Key {
+	var keys []Key
+	for len(b) > 0 {
+		switch c := b[0]; {
+		case c == 0x1b:
+			if len(b) == 1 {
+				return append(keys, Key{Code: KeyEsc})
+			}
+			n := sequenceLength(b)
+			if code, ok := escapeSequences[string(b[:n])]; ok {
+				keys = append(keys, Key{Code: code})
+			}
+			b = b[n:]
+			continue
+		case c == '\r' || c == '\n':
+			keys = append(keys, Key{Code: KeyEnter})
+		case c == '\t':
+			keys = append(keys, Key{Code: KeyTab})
+		case c == 0x7f || c == 0x08:
+			keys = append(keys, Key{Code: KeyBackspace})
+		case c == 0x03:
+			keys = append(keys, Key{Code: KeyCtrlC})
+		case c < 0x20:
+			// Other control characters are ignored.
+		default:
+			r, size := utf8.DecodeRune(b)
+			keys = append(keys, Key{Code: KeyRune, Rune: r})
+			b = b[size:]
+			continue
+		}
+		b = b[1:]
+	}
+	return keys
+}
+
+// sequenceLength returns the length of the escape sequence b starts with.
+func sequenceLength(b []byte) int {
+	if len(b) < 3 || (b[1] != '[' && b[1] != 'O') {
+		return min(2, len(b))
+	}
+	for i := 2; i < len(b); i++ {
+		if b[i] >= 0x40 && b[i] <= 0x7e {
+			return i + 1
+		}
+	}
+	return len(b)
+}
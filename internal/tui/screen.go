@@ -0,0 +1,162 @@
+// Package tui draws full-screen terminal interfaces with plain ANSI escape
+// sequences and reads keys from a terminal in raw mode.
+package tui
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jedib0t/go-pretty/v6/text"
+	"golang.org/x/term"
+)
+
+// ANSI escape sequences used by Screen.
+const (
+	enterAltScreen = "\033[?1049h"
+	leaveAltScreen = "\033[?1049l"
+	hideCursor     = "\033[?25l"
+	showCursor     = "\033[?25h"
+	cursorHome     = "\033[H"
+	clearLine      = "\033[K"
+	clearBelow     = "\033[J"
+)
+
+// readPoll bounds how long a key read blocks, so Suspend can stop reading quickly.
+const readPoll = 100 * time.Millisecond
+
+// A Screen is a terminal switched to the alternate screen in raw mode.
+type Screen struct {
+	in    *os.File
+	out   *bufio.Writer
+	state *term.State
+
+	// reading is held while keys are read and while the screen is suspended.
+	reading sync.Mutex
+}
+
+// Open switches the terminal of stdin and stdout to a full-screen raw mode.
+// Close must be called to restore it.
+func Open() (*Screen, error) {
+	if !term.IsTerminal(int(os.Stdin.Fd())) || !term.IsTerminal(int(os.Stdout.Fd())) {
+		return nil, fmt.Errorf("stdin and stdout must be a terminal")
+	}
+
+	// A separately opened tty supports read deadlines, stdin does not.
+	in, err := os.OpenFile("/dev/tty", os.O_RDONLY, 0)
+	if err != nil {
+		in = os.Stdin
+	}
+	s := &Screen{in: in, out: bufio.NewWriter(os.Stdout)}
+	if err := s.enter(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Suspend gives the terminal back, e.g. to run an editor. Keys are not read until Resume.
+func (s *Screen) Suspend() {
+	s.reading.Lock()
+	s.leave()
+}
+
+// Resume takes the terminal back after Suspend.
+func (s *Screen) Resume() error {
+	defer s.reading.Unlock()
+	return s.enter()
+}
+
+// Close restores the terminal.
+func (s *Screen) Close() {
+	s.leave()
+	if s.in != os.Stdin {
+		s.in.Close()
+	}
+}
+
+// enter switches the terminal to raw mode and the alternate screen.
+func (s *Screen) enter() error {
+	state, err := term.MakeRaw(int(s.in.Fd()))
+	if err != nil {
+		return fmt.Errorf("failed to switch terminal to raw mode: %w", err)
+	}
+	s.state = state
+	s.out.WriteString(enterAltScreen + hideCursor)
+	return s.out.Flush()
+}
+
+// leave restores the terminal mode and the main screen.
+func (s *Screen) leave() {
+	s.out.WriteString(showCursor + leaveAltScreen)
+	s.out.Flush()
+	if s.state != nil {
+		term.Restore(int(s.in.Fd()), s.state)
+		s.state = nil
+	}
+}
+
+// Keys decodes key presses until the screen is closed. The channel is closed then.
+func (s *Screen) Keys() <-chan Key {
+	keys := make(chan Key)
+	go func() {
+		defer close(keys)
+		buf := make([]byte, 64)
+		for {
+			n, err := s.read(buf)
+			for _, k := range Decode(buf[:n]) {
+				keys <- k
+			}
+			if err != nil && !errors.Is(err, os.ErrDeadlineExceeded) {
+				return
+			}
+		}
+	}()
+	return keys
+}
+
+// read reads input unless the screen is suspended, waiting at most readPoll when the terminal allows it.
+func (s *Screen) read(buf []byte) (int, error) {
+	s.reading.Lock()
+	defer s.reading.Unlock()
+	if err := s.in.SetReadDeadline(time.Now().Add(readPoll)); err != nil && !errors.Is(err, os.ErrNoDeadline) {
+		return 0, err
+	}
+	return s.in.Read(buf)
+}
+
+// Size returns the terminal width and height, 80x24 if unknown.
+func (s *Screen) Size() (int, int) {
+	width, height, err := term.GetSize(int(os.Stdout.Fd()))
+	if err != nil || width <= 0 || height <= 0 {
+		return 80, 24
+	}
+	return width, height
+}
+
+// Draw replaces the screen content with lines, cutting them to the terminal size.
+// Lines may contain color escape sequences.
+func (s *Screen) Draw(lines []string) error {
+	width, height := s.Size()
+	if len(lines) > height {
+		lines = lines[:height]
+	}
+
+	var b strings.Builder
+	b.WriteString(cursorHome)
+	for i, line := range lines {
+		b.WriteString(text.Trim(line, width))
+		b.WriteString(text.Reset.EscapeSeq())
+		b.WriteString(clearLine)
+		if i < len(lines)-1 {
+			b.WriteString("\r\n")
+		}
+	}
+	b.WriteString(clearBelow)
+
+	s.out.WriteString(b.String())
+	return s.out.Flush()
+}
@@ -0,0 +1,115 @@
+// Package watch streams BackupRequest changes from a dynamic informer.
+package watch
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	backupv1 "github.com/oiler-backup/core/core/api/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/tools/cache"
+)
+
+// An EventType tells what happened to a BackupRequest.
+type EventType string
+
+// Event types.
+const (
+	Added    EventType = "ADDED"
+	Modified EventType = "MODIFIED"
+	Deleted  EventType = "DELETED"
+)
+
+// An Event is a single BackupRequest change.
+type Event struct {
+	Type   EventType
+	Object backupv1.BackupRequest
+	// PreviousStatus is Status.Status before a MODIFIED event.
+	PreviousStatus string
+}
+
+// StatusChanged reports whether the event changed Status.Status.
+func (e Event) StatusChanged() bool {
+	return e.Type == Modified && e.PreviousStatus != e.Object.Status.Status
+}
+
+// ToBackupRequest converts an informer object into BackupRequest.
+func ToBackupRequest(obj any) (backupv1.BackupRequest, error) {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	u, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		return backupv1.BackupRequest{}, fmt.Errorf("unexpected object %T", obj)
+	}
+
+	var br backupv1.BackupRequest
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, &br); err != nil {
+		return backupv1.BackupRequest{}, fmt.Errorf("failed to convert BackupRequest %s: %w", u.GetName(), err)
+	}
+	return br, nil
+}
+
+// BackupRequests starts an informer for gvr and sends every change to the returned channel.
+// Existing objects are delivered as ADDED events first. The channel is closed once ctx is done.
+func BackupRequests(ctx context.Context, client dynamic.Interface, gvr schema.GroupVersionResource, tweak func(*metav1.ListOptions)) (<-chan Event, error) {
+	factory := dynamicinformer.NewFilteredDynamicSharedInformerFactory(client, 0, metav1.NamespaceAll, tweak)
+	informer := factory.ForResource(gvr).Informer()
+
+	events := make(chan Event, 64)
+	send := func(e Event) {
+		select {
+		case events <- e:
+		case <-ctx.Done():
+		}
+	}
+
+	_, err := informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj any) {
+			if br, err := ToBackupRequest(obj); err == nil {
+				send(Event{Type: Added, Object: br})
+			}
+		},
+		UpdateFunc: func(oldObj, newObj any) {
+			oldBr, err := ToBackupRequest(oldObj)
+			if err != nil {
+				return
+			}
+			newBr, err := ToBackupRequest(newObj)
+			if err != nil || oldBr.ResourceVersion == newBr.ResourceVersion {
+				return
+			}
+			send(Event{Type: Modified, Object: newBr, PreviousStatus: oldBr.Status.Status})
+		},
+		DeleteFunc: func(obj any) {
+			if br, err := ToBackupRequest(obj); err == nil {
+				send(Event{Type: Deleted, Object: br, PreviousStatus: br.Status.Status})
+			}
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to register event handler: %w", err)
+	}
+
+	factory.Start(ctx.Done())
+	syncCtx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+	if !cache.WaitForCacheSync(syncCtx.Done(), informer.HasSynced) {
+		factory.Shutdown()
+		return nil, fmt.Errorf("failed to sync BackupRequest informer")
+	}
+
+	go func() {
+		<-ctx.Done()
+		factory.Shutdown()
+		close(events)
+	}()
+
+	return events, nil
+}